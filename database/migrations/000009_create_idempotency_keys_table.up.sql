@@ -0,0 +1,16 @@
+CREATE TABLE idempotency_keys (
+  scope VARCHAR(100) NOT NULL,
+  idempotency_key VARCHAR(255) NOT NULL,
+  fingerprint CHAR(64) NOT NULL,
+  status_code SMALLINT,
+  content_type VARCHAR(255) NOT NULL DEFAULT '',
+  response_body BYTEA,
+  locked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  completed_at TIMESTAMP WITH TIME ZONE,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+  CONSTRAINT idempotency_keys_pkey PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_index ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_permissions_group_name;
+ALTER TABLE permissions DROP COLUMN IF EXISTS group_name;
+ALTER TABLE permissions DROP COLUMN IF EXISTS description;
@@ -0,0 +1,4 @@
+ALTER TABLE permissions ADD COLUMN description VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE permissions ADD COLUMN group_name VARCHAR(50) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_permissions_group_name ON permissions(group_name);
@@ -0,0 +1,17 @@
+CREATE TABLE audit_logs (
+  id CHAR(26) PRIMARY KEY NOT NULL,
+  actor_id CHAR(26),
+  action VARCHAR(100) NOT NULL,
+  target_type VARCHAR(50) NOT NULL,
+  target_id VARCHAR(255) NOT NULL,
+  before JSONB,
+  after JSONB,
+  ip_address VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  request_id VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_logs_actor_id_created_at_index ON audit_logs (actor_id, created_at);
+CREATE INDEX audit_logs_target_created_at_index ON audit_logs (target_type, target_id, created_at);
+CREATE INDEX audit_logs_created_at_index ON audit_logs (created_at);
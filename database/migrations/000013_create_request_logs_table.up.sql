@@ -0,0 +1,18 @@
+CREATE TABLE request_logs (
+  id CHAR(26) PRIMARY KEY NOT NULL,
+  request_id VARCHAR(255) NOT NULL DEFAULT '',
+  user_id CHAR(26),
+  method VARCHAR(10) NOT NULL,
+  route VARCHAR(255) NOT NULL,
+  path TEXT NOT NULL,
+  status SMALLINT NOT NULL,
+  latency_ms BIGINT NOT NULL,
+  client_ip VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent TEXT NOT NULL DEFAULT '',
+  response_size INTEGER NOT NULL DEFAULT 0,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX request_logs_request_id_index ON request_logs (request_id);
+CREATE INDEX request_logs_user_id_index ON request_logs (user_id);
+CREATE INDEX request_logs_created_at_index ON request_logs (created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS employees_department_index;
+
+ALTER TABLE employees DROP COLUMN IF EXISTS department;
@@ -0,0 +1,3 @@
+ALTER TABLE employees ADD COLUMN department VARCHAR(100);
+
+CREATE INDEX employees_department_index ON employees (department);
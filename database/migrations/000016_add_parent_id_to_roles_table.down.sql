@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS roles_parent_id_index;
+
+ALTER TABLE roles DROP CONSTRAINT IF EXISTS fk_roles_parent;
+
+ALTER TABLE roles DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,8 @@
+ALTER TABLE roles ADD COLUMN parent_id CHAR(26);
+
+ALTER TABLE roles ADD CONSTRAINT fk_roles_parent
+  FOREIGN KEY (parent_id)
+  REFERENCES roles(id)
+  ON DELETE SET NULL;
+
+CREATE INDEX roles_parent_id_index ON roles (parent_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS role_user_expires_at_index;
+DROP INDEX IF EXISTS role_user_starts_at_index;
+
+ALTER TABLE role_user DROP COLUMN IF EXISTS began_at;
+ALTER TABLE role_user DROP COLUMN IF EXISTS reason;
+ALTER TABLE role_user DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE role_user DROP COLUMN IF EXISTS starts_at;
@@ -0,0 +1,10 @@
+ALTER TABLE role_user ADD COLUMN starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE role_user ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE role_user ADD COLUMN reason VARCHAR(255);
+ALTER TABLE role_user ADD COLUMN began_at TIMESTAMP WITH TIME ZONE;
+
+-- The existing roles were held since they were assigned
+UPDATE role_user SET starts_at = created_at;
+
+CREATE INDEX role_user_starts_at_index ON role_user (starts_at);
+CREATE INDEX role_user_expires_at_index ON role_user (expires_at);
@@ -0,0 +1 @@
+-- The grant is kept, super_admin is meant to hold * and revoking it would lock the super admins out
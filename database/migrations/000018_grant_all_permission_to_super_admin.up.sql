@@ -0,0 +1,17 @@
+-- The * wildcard grants every permission, including those added later. It is held by super_admin
+-- whether the role was seeded before the wildcard existed or had it revoked since.
+INSERT INTO permissions (id, name, group_name, description)
+VALUES ('01M596W1MCYTS4N3054F1W6463', '*', '*', 'Every permission, including those added later')
+ON CONFLICT (name) DO NOTHING;
+
+-- The holders of super_admin resolve their permissions again once it is granted
+WITH granted AS (
+    INSERT INTO permission_role (permission_id, role_id)
+    SELECT permissions.id, roles.id
+    FROM permissions, roles
+    WHERE permissions.name = '*' AND roles.name = 'super_admin'
+    ON CONFLICT (permission_id, role_id) DO NOTHING
+    RETURNING role_id
+)
+UPDATE users SET permission_version = permission_version + 1
+WHERE id IN (SELECT role_user.user_id FROM role_user JOIN granted ON granted.role_id = role_user.role_id);
@@ -0,0 +1,2 @@
+ALTER TABLE idempotency_keys ADD COLUMN location VARCHAR(2048) NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ADD COLUMN etag VARCHAR(255) NOT NULL DEFAULT '';
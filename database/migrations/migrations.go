@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL migrations so the binary carries its own schema.
+//
+// Files are named <version>_<name>.<up|down>.sql, e.g. 000001_create_users_table.up.sql,
+// and are applied with golang-migrate by the migrate command.
+package migrations
+
+import "embed"
+
+// FS holds every *.sql migration of this directory
+//
+//go:embed *.sql
+var FS embed.FS
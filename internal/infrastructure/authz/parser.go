@@ -0,0 +1,159 @@
+package authz
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+)
+
+// Parse parses a requirement expression such as "users.update OR (users.show AND owner)".
+//
+// An operand is a permission name when it contains a dot or is the * wildcard, a role name when it is prefixed by
+// RolePrefix and the name of a condition otherwise. Operands are combined with NOT, AND and OR,
+// by order of precedence and case-insensitively, and grouped with parentheses. Permissions and
+// roles must be declared in their enums.
+func Parse(expression string) (Requirement, error) {
+	p := &parser{expression: expression, tokens: tokenize(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("authz: empty requirement expression")
+	}
+
+	requirement, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.position < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.position])
+	}
+	return requirement, nil
+}
+
+// MustParse is like Parse but panics when the expression is invalid, for requirements declared with the routes
+func MustParse(expression string) Requirement {
+	requirement, err := Parse(expression)
+	if err != nil {
+		panic(err)
+	}
+	return requirement
+}
+
+type parser struct {
+	expression string
+	tokens     []string
+	position   int
+}
+
+// tokenize splits the expression into parentheses and words
+func tokenize(expression string) []string {
+	expression = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(expression)
+	return strings.Fields(expression)
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("authz: invalid requirement expression %q: %s", p.expression, fmt.Sprintf(format, args...))
+}
+
+// next consumes the next token when it is the keyword, case-insensitively
+func (p *parser) next(keyword string) bool {
+	if p.position < len(p.tokens) && strings.EqualFold(p.tokens[p.position], keyword) {
+		p.position++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (Requirement, error) {
+	requirement, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := or{requirement}
+	for p.next("OR") {
+		requirement, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	if len(requirements) == 1 {
+		return requirements[0], nil
+	}
+	return requirements, nil
+}
+
+func (p *parser) parseAnd() (Requirement, error) {
+	requirement, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	requirements := and{requirement}
+	for p.next("AND") {
+		requirement, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	if len(requirements) == 1 {
+		return requirements[0], nil
+	}
+	return requirements, nil
+}
+
+func (p *parser) parseNot() (Requirement, error) {
+	if p.next("NOT") {
+		requirement, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return not{requirement}, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *parser) parseOperand() (Requirement, error) {
+	if p.position >= len(p.tokens) {
+		return nil, p.errorf("unexpected end")
+	}
+
+	if p.next("(") {
+		requirement, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, p.errorf("missing )")
+		}
+		return requirement, nil
+	}
+
+	token := p.tokens[p.position]
+	if token == ")" || strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") {
+		return nil, p.errorf("unexpected %q", token)
+	}
+	p.position++
+
+	switch {
+	case strings.HasPrefix(token, RolePrefix):
+		name := roleEnum.RoleName(strings.TrimPrefix(token, RolePrefix))
+		if !slices.Contains(roleEnum.RoleNames, name) {
+			return nil, p.errorf("unknown role %q", name)
+		}
+		return role(name), nil
+	case token == permissionEnum.All.String() || strings.Contains(token, "."):
+		name := permissionEnum.PermissionName(token)
+		if !slices.Contains(permissionEnum.PermissionNames, name) {
+			return nil, p.errorf("unknown permission %q", name)
+		}
+		return permission(name), nil
+	default:
+		return condition(token), nil
+	}
+}
@@ -0,0 +1,230 @@
+package authz_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+)
+
+// subject holds the permissions, roles and conditions it is given
+type subject struct {
+	permissions []permissionEnum.PermissionName
+	roles       []roleEnum.RoleName
+	conditions  []string
+}
+
+func (s subject) HasPermissionName(name permissionEnum.PermissionName) bool {
+	return permissionEnum.Granted(s.permissions, name)
+}
+
+func (s subject) HasRoleName(name roleEnum.RoleName) bool {
+	return slices.Contains(s.roles, name)
+}
+
+func (s subject) Condition(name string) bool {
+	return slices.Contains(s.conditions, name)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		// want is the expression the requirement is printed as, its grouping showing the precedence
+		want       string
+		conditions []string
+	}{
+		{
+			name:       "a permission",
+			expression: "users.show",
+			want:       "users.show",
+		},
+		{
+			name:       "the wildcard",
+			expression: "*",
+			want:       "*",
+		},
+		{
+			name:       "AND binds tighter than OR",
+			expression: "users.update OR users.show AND owner",
+			want:       "users.update OR (users.show AND owner)",
+			conditions: []string{"owner"},
+		},
+		{
+			name:       "NOT binds tighter than AND",
+			expression: "NOT users.update AND owner",
+			want:       "NOT users.update AND owner",
+			conditions: []string{"owner"},
+		},
+		{
+			name:       "parentheses group first",
+			expression: "(users.update OR users.show) AND owner",
+			want:       "(users.update OR users.show) AND owner",
+			conditions: []string{"owner"},
+		},
+		{
+			name:       "NOT of a group",
+			expression: "NOT (users.update OR users.show)",
+			want:       "NOT (users.update OR users.show)",
+		},
+		{
+			name:       "nested parentheses",
+			expression: "((users.show))",
+			want:       "users.show",
+		},
+		{
+			name:       "keywords are case-insensitive",
+			expression: "not users.update and owner or role:admin",
+			want:       "(NOT users.update AND owner) OR role:admin",
+			conditions: []string{"owner"},
+		},
+		{
+			name:       "a role",
+			expression: "role:super_admin",
+			want:       "role:super_admin",
+		},
+		{
+			name:       "parentheses without spaces",
+			expression: "(role:admin)AND(owner)",
+			want:       "role:admin AND owner",
+			conditions: []string{"owner"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirement, err := authz.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expression, err)
+			}
+			if got := requirement.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.expression, got, tt.want)
+			}
+			if got := requirement.Conditions(); !slices.Equal(got, tt.conditions) {
+				t.Errorf("Parse(%q).Conditions() = %v, want %v", tt.expression, got, tt.conditions)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		// want is a part of the error
+		want string
+	}{
+		{name: "empty", expression: "  ", want: "empty"},
+		{name: "unknown permission", expression: "users.update.self", want: `unknown permission "users.update.self"`},
+		{name: "unknown role", expression: "role:owner", want: `unknown role "owner"`},
+		{name: "missing operand", expression: "users.show AND", want: "unexpected end"},
+		{name: "missing closing parenthesis", expression: "(users.show OR owner", want: "missing )"},
+		{name: "unopened parenthesis", expression: "users.show)", want: `unexpected ")"`},
+		{name: "leading operator", expression: "OR users.show", want: `unexpected "OR"`},
+		{name: "missing operator", expression: "users.show owner", want: `unexpected "owner"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirement, err := authz.Parse(tt.expression)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %q, want an error", tt.expression, requirement)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.expression, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirementEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		subject    subject
+		want       bool
+		unmet      []string
+	}{
+		{
+			name:       "permission held",
+			expression: "users.show",
+			subject:    subject{permissions: []permissionEnum.PermissionName{permissionEnum.UsersShow}},
+			want:       true,
+		},
+		{
+			name:       "permission held by wildcard",
+			expression: "users.show",
+			subject:    subject{permissions: []permissionEnum.PermissionName{permissionEnum.UsersAll}},
+			want:       true,
+		},
+		{
+			name:       "permission missing",
+			expression: "users.show",
+			subject:    subject{},
+			unmet:      []string{"users.show"},
+		},
+		{
+			name:       "AND lists every unmet operand",
+			expression: "users.show AND users.update AND owner",
+			subject:    subject{permissions: []permissionEnum.PermissionName{permissionEnum.UsersShow}},
+			unmet:      []string{"users.update", "owner"},
+		},
+		{
+			name:       "OR met by its second operand",
+			expression: "users.update OR (users.show AND owner)",
+			subject: subject{
+				permissions: []permissionEnum.PermissionName{permissionEnum.UsersShow},
+				conditions:  []string{"owner"},
+			},
+			want: true,
+		},
+		{
+			name:       "OR lists the unmet operands of every alternative",
+			expression: "users.update OR (users.show AND owner)",
+			subject:    subject{permissions: []permissionEnum.PermissionName{permissionEnum.UsersShow}},
+			unmet:      []string{"users.update", "owner"},
+		},
+		{
+			name:       "NOT of a missing permission",
+			expression: "NOT users.update",
+			subject:    subject{},
+			want:       true,
+		},
+		{
+			name:       "NOT of a held permission",
+			expression: "NOT users.update AND owner",
+			subject: subject{
+				permissions: []permissionEnum.PermissionName{permissionEnum.UsersUpdate},
+				conditions:  []string{"owner"},
+			},
+			unmet: []string{"NOT users.update"},
+		},
+		{
+			name:       "role held",
+			expression: "role:admin OR users.update",
+			subject:    subject{roles: []roleEnum.RoleName{roleEnum.Admin}},
+			want:       true,
+		},
+		{
+			name:       "role missing",
+			expression: "role:super_admin",
+			subject:    subject{roles: []roleEnum.RoleName{roleEnum.Admin}},
+			unmet:      []string{"role:super_admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unmet := authz.MustParse(tt.expression).Evaluate(tt.subject)
+			if got != tt.want {
+				t.Errorf("Evaluate(%q) = %v, want %v", tt.expression, got, tt.want)
+			}
+			if !slices.Equal(unmet, tt.unmet) {
+				t.Errorf("Evaluate(%q) unmet = %v, want %v", tt.expression, unmet, tt.unmet)
+			}
+		})
+	}
+}
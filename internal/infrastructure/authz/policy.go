@@ -0,0 +1,147 @@
+package authz
+
+import (
+	"context"
+
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	userService "github.com/arfanxn/welding/internal/module/user/usecase/service"
+	"github.com/guregu/null/v6"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+)
+
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/infrastructure/authz")
+
+// Effect is what a rule decides about a request
+type Effect int
+
+const (
+	// Abstain leaves the request to the next rules
+	Abstain Effect = iota
+	// Allow authorizes the request without evaluating the next rules
+	Allow
+	// Deny refuses the request without evaluating the next rules
+	Deny
+)
+
+// Actor is the authenticated user requesting an action, with the permissions it resolved to
+type Actor struct {
+	User        *entity.User
+	Permissions *userService.EffectivePermissions
+}
+
+func (a *Actor) HasPermissionName(name permissionEnum.PermissionName) bool {
+	return a.Permissions.HasPermissionNames(name)
+}
+
+func (a *Actor) HasRoleName(name roleEnum.RoleName) bool {
+	return a.Permissions.HasRoleNames(name)
+}
+
+// Department is the department of the actor, null when it is not an employee or has none
+func (a *Actor) Department() null.String {
+	return a.User.DepartmentName()
+}
+
+// Request is an action an actor requests under a permission
+type Request struct {
+	Actor      *Actor
+	Permission permissionEnum.PermissionName
+	// Resource is the target of the action, nil when the action creates it
+	Resource any
+	// Input is what the action is given, such as the attributes a user is updated with
+	Input any
+}
+
+// Rule decides a request or abstains. A rule denies with an error to explain why, which is returned
+// as is, and otherwise with ErrAuthForbidden. Rules are plain functions of the request, so they can be
+// tested by themselves or through PolicyEngine.Evaluate.
+type Rule func(ctx context.Context, request *Request) (Effect, error)
+
+// PolicyEngine authorizes actions on resources with the rules registered for their permission
+type PolicyEngine interface {
+	// Register appends rules to those of permission. It is meant to be called by the policies on startup.
+	Register(permission permissionEnum.PermissionName, rules ...Rule)
+	// Authorize evaluates the request of the authenticated user of ctx to act under permission
+	Authorize(ctx context.Context, permission permissionEnum.PermissionName, resource any, input any) error
+	// Evaluate evaluates the rules of the permission of request in registration order. The first rule that
+	// allows or denies the request decides, and when every rule abstains the actor must hold the permission.
+	Evaluate(ctx context.Context, request *Request) error
+}
+
+var _ PolicyEngine = (*policyEngine)(nil)
+
+type policyEngine struct {
+	userPermissionService userService.UserPermissionService
+	rules                 map[permissionEnum.PermissionName][]Rule
+}
+
+type NewPolicyEngineParams struct {
+	fx.In
+
+	UserPermissionService userService.UserPermissionService
+}
+
+func NewPolicyEngine(params NewPolicyEngineParams) PolicyEngine {
+	return &policyEngine{
+		userPermissionService: params.UserPermissionService,
+		rules:                 map[permissionEnum.PermissionName][]Rule{},
+	}
+}
+
+func (e *policyEngine) Register(permission permissionEnum.PermissionName, rules ...Rule) {
+	e.rules[permission] = append(e.rules[permission], rules...)
+}
+
+func (e *policyEngine) Authorize(
+	ctx context.Context,
+	permission permissionEnum.PermissionName,
+	resource any,
+	input any,
+) error {
+	ctx, span := tracer.Start(ctx, "PolicyEngine.Authorize")
+	defer span.End()
+
+	user := ctx.Value(contextkey.UserKey).(*entity.User)
+	permissions, err := e.userPermissionService.Resolve(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	err = e.Evaluate(ctx, &Request{
+		Actor:      &Actor{User: user, Permissions: permissions},
+		Permission: permission,
+		Resource:   resource,
+		Input:      input,
+	})
+	span.SetAttributes(
+		attribute.String("authz.permission", permission.String()),
+		attribute.Bool("authz.allowed", err == nil),
+	)
+	return err
+}
+
+func (e *policyEngine) Evaluate(ctx context.Context, request *Request) error {
+	for _, rule := range e.rules[request.Permission] {
+		effect, err := rule(ctx, request)
+		if err != nil {
+			return err
+		}
+		switch effect {
+		case Allow:
+			return nil
+		case Deny:
+			return errorx.ErrAuthForbidden
+		}
+	}
+
+	if !request.Actor.HasPermissionName(request.Permission) {
+		return errorx.ErrAuthForbidden
+	}
+	return nil
+}
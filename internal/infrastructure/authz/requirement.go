@@ -0,0 +1,195 @@
+// Package authz describes what an authenticated user must hold to be authorized: permissions,
+// roles and named conditions on the request, combined with AND, OR and NOT. Its policy engine
+// goes further and evaluates the actions on a resource against rules registered per permission.
+package authz
+
+import (
+	"strings"
+
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+)
+
+// RolePrefix marks a role in an expression, e.g. role:super_admin
+const RolePrefix = "role:"
+
+// Subject is what a requirement is evaluated against, usually the authenticated user and the request
+type Subject interface {
+	HasPermissionName(name permissionEnum.PermissionName) bool
+	HasRoleName(name roleEnum.RoleName) bool
+	// Condition reports whether the named condition holds, e.g. owner when the user is the target resource
+	Condition(name string) bool
+}
+
+// Requirement is satisfied or not by a subject. Its String is the expression it is parsed from.
+type Requirement interface {
+	String() string
+	// Evaluate reports whether subject satisfies the requirement, and otherwise the requirements it
+	// failed, which are the permissions, roles and conditions missing to satisfy it
+	Evaluate(subject Subject) (bool, []string)
+	// Conditions lists the names of the conditions the requirement refers to
+	Conditions() []string
+}
+
+// AllPermissions requires every one of names
+func AllPermissions(names ...permissionEnum.PermissionName) Requirement {
+	return and(permissionRequirements(names))
+}
+
+// AnyPermission requires one of names at least
+func AnyPermission(names ...permissionEnum.PermissionName) Requirement {
+	return or(permissionRequirements(names))
+}
+
+func permissionRequirements(names []permissionEnum.PermissionName) []Requirement {
+	requirements := make([]Requirement, 0, len(names))
+	for _, name := range names {
+		requirements = append(requirements, permission(name))
+	}
+	return requirements
+}
+
+type permission permissionEnum.PermissionName
+
+func (r permission) String() string {
+	return string(r)
+}
+
+func (r permission) Evaluate(subject Subject) (bool, []string) {
+	if subject.HasPermissionName(permissionEnum.PermissionName(r)) {
+		return true, nil
+	}
+	return false, []string{r.String()}
+}
+
+func (r permission) Conditions() []string {
+	return nil
+}
+
+type role roleEnum.RoleName
+
+func (r role) String() string {
+	return RolePrefix + string(r)
+}
+
+func (r role) Evaluate(subject Subject) (bool, []string) {
+	if subject.HasRoleName(roleEnum.RoleName(r)) {
+		return true, nil
+	}
+	return false, []string{r.String()}
+}
+
+func (r role) Conditions() []string {
+	return nil
+}
+
+type condition string
+
+func (r condition) String() string {
+	return string(r)
+}
+
+func (r condition) Evaluate(subject Subject) (bool, []string) {
+	if subject.Condition(string(r)) {
+		return true, nil
+	}
+	return false, []string{r.String()}
+}
+
+func (r condition) Conditions() []string {
+	return []string{string(r)}
+}
+
+type and []Requirement
+
+func (r and) String() string {
+	return join(r, " AND ")
+}
+
+func (r and) Evaluate(subject Subject) (bool, []string) {
+	var unmet []string
+	for _, requirement := range r {
+		if ok, failed := requirement.Evaluate(subject); !ok {
+			unmet = append(unmet, failed...)
+		}
+	}
+	return len(unmet) == 0, unmet
+}
+
+func (r and) Conditions() []string {
+	return conditions(r)
+}
+
+type or []Requirement
+
+func (r or) String() string {
+	return join(r, " OR ")
+}
+
+func (r or) Evaluate(subject Subject) (bool, []string) {
+	// An empty OR is satisfied like an empty AND, requiring nothing
+	if len(r) == 0 {
+		return true, nil
+	}
+
+	var unmet []string
+	for _, requirement := range r {
+		ok, failed := requirement.Evaluate(subject)
+		if ok {
+			return true, nil
+		}
+		unmet = append(unmet, failed...)
+	}
+	return false, unmet
+}
+
+func (r or) Conditions() []string {
+	return conditions(r)
+}
+
+type not struct {
+	Requirement
+}
+
+func (r not) String() string {
+	return "NOT " + group(r.Requirement)
+}
+
+func (r not) Evaluate(subject Subject) (bool, []string) {
+	if ok, _ := r.Requirement.Evaluate(subject); ok {
+		return false, []string{r.String()}
+	}
+	return true, nil
+}
+
+// join joins the expressions of requirements with operator, grouping the compound ones
+func join(requirements []Requirement, operator string) string {
+	expressions := make([]string, 0, len(requirements))
+	for _, requirement := range requirements {
+		expressions = append(expressions, group(requirement))
+	}
+	return strings.Join(expressions, operator)
+}
+
+// group wraps the expression of a compound requirement in parentheses
+func group(requirement Requirement) string {
+	switch requirement := requirement.(type) {
+	case and:
+		if len(requirement) > 1 {
+			return "(" + requirement.String() + ")"
+		}
+	case or:
+		if len(requirement) > 1 {
+			return "(" + requirement.String() + ")"
+		}
+	}
+	return requirement.String()
+}
+
+func conditions(requirements []Requirement) []string {
+	var names []string
+	for _, requirement := range requirements {
+		names = append(names, requirement.Conditions()...)
+	}
+	return names
+}
@@ -10,6 +10,9 @@ var RootCommand = &cli.Command{
 	Commands: []*cli.Command{
 		serveCommand,
 		migrateCommand,
+		permissionsSyncCommand,
 		seedCommand,
+		configCheckCommand,
+		openAPIExportCommand,
 	},
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/urfave/cli/v3"
+)
+
+var configCheckCommand = &cli.Command{
+	Name:  "config:check",
+	Usage: "Validate the configuration and print the effective values with secrets masked",
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		entries, err := cfg.Describe()
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		w := tabwriter.NewWriter(cmd.Root().Writer, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Key, entry.Value, entry.Source)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(cmd.Root().Writer, "\nConfiguration is valid")
+		return nil
+	},
+}
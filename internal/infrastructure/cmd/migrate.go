@@ -2,100 +2,321 @@ package cmd
 
 import (
 	"context"
-	"os"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/arfanxn/welding/internal/infrastructure/config"
-	"github.com/arfanxn/welding/internal/infrastructure/di"
+	"github.com/arfanxn/welding/internal/infrastructure/database/migration"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
-	"gorm.io/gorm"
-
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/urfave/cli/v3"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
+// migrationsDir is where migrate create writes new migration files, relative to the project root
+const migrationsDir = "database/migrations"
+
+var dryRunFlag = &cli.BoolFlag{
+	Name:  "dry-run",
+	Usage: "print the SQL of the migrations that would run without running them",
+}
+
 var migrateCommand = &cli.Command{
 	Name:  "migrate",
-	Usage: "Run the migration",
-	Action: func(ctx context.Context, cmd *cli.Command) error {
-		app := fx.New(
-			di.Module,
-			fx.Invoke(_migrate),
-		)
-
-		app.Run()
+	Usage: "Run the database migrations embedded in the binary",
+	Description: "Without a subcommand every pending migration is applied, like migrate up.\n" +
+		"Exits with status 1 when a migration fails or the database is dirty.",
+	Flags:  []cli.Flag{dryRunFlag},
+	Action: migrateUpAction,
+	Commands: []*cli.Command{
+		{
+			Name:      "up",
+			Usage:     "Apply the next N pending migrations, all of them when N is omitted",
+			ArgsUsage: "[N]",
+			Flags:     []cli.Flag{dryRunFlag},
+			Action:    migrateUpAction,
+		},
+		{
+			Name:      "down",
+			Usage:     "Roll back the last N applied migrations, the last one when N is omitted",
+			ArgsUsage: "[N]",
+			Flags: []cli.Flag{
+				dryRunFlag,
+				&cli.BoolFlag{Name: "all", Usage: "roll back every applied migration"},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				steps, err := migrateStepsArg(cmd, 1)
+				if err != nil {
+					return err
+				}
+				if cmd.Bool("all") {
+					steps = 0
+				}
+				return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+					if cmd.Bool("dry-run") {
+						return printMigrationPlan(w, m, migration.Down, steps)
+					}
+					return runMigration(w, m, func() error { return m.Down(steps) })
+				})
+			},
+		},
+		{
+			Name:  "fresh",
+			Usage: "Roll back every applied migration then apply every migration",
+			Flags: []cli.Flag{dryRunFlag},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+					if cmd.Bool("dry-run") {
+						if err := printMigrationPlan(w, m, migration.Down, 0); err != nil {
+							return err
+						}
+						// Every migration runs again once everything is rolled back
+						plan, err := migration.Embedded(migration.Up)
+						if err != nil {
+							return err
+						}
+						printMigrations(w, plan, migration.Up)
+						return nil
+					}
+					if err := m.Fresh(); err != nil {
+						return err
+					}
+					return printMigrationVersion(w, m)
+				})
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "List the migrations and whether they have been applied",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+					statuses, err := m.Status()
+					if err != nil {
+						return err
+					}
 
-		return nil
+					tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS")
+					for _, status := range statuses {
+						state := "pending"
+						switch {
+						case status.Dirty:
+							state = "dirty"
+						case status.Applied:
+							state = "applied"
+						}
+						fmt.Fprintf(tw, "%06d\t%s\t%s\n", status.Version, status.Identifier, state)
+					}
+					return tw.Flush()
+				})
+			},
+		},
+		{
+			Name:  "version",
+			Usage: "Print the current migration version",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+					return printMigrationVersion(w, m)
+				})
+			},
+		},
+		{
+			Name:      "force",
+			Usage:     "Set the migration version without running migrations, e.g. to clear the dirty state after fixing a failure (-1 for none)",
+			ArgsUsage: "V",
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() != 1 {
+					return cli.Exit("force requires exactly one version argument", 1)
+				}
+				version, err := strconv.Atoi(cmd.Args().First())
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("invalid version %q", cmd.Args().First()), 1)
+				}
+				return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+					if err := m.Force(version); err != nil {
+						return err
+					}
+					return printMigrationVersion(w, m)
+				})
+			},
+		},
+		{
+			Name:      "create",
+			Usage:     "Create an empty up and down migration, it is embedded on the next build",
+			ArgsUsage: "<name>",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "dir", Value: migrationsDir, Usage: "directory of the migration files"},
+			},
+			Action: func(ctx context.Context, cmd *cli.Command) error {
+				if cmd.Args().Len() != 1 {
+					return cli.Exit("create requires exactly one name argument", 1)
+				}
+				paths, err := migration.Create(cmd.String("dir"), cmd.Args().First())
+				for _, path := range paths {
+					fmt.Fprintf(cmd.Root().Writer, "Created %s\n", path)
+				}
+				if err != nil {
+					return cli.Exit(err.Error(), 1)
+				}
+				return nil
+			},
+		},
 	},
 }
 
-type _migrateParams struct {
-	fx.In
+func migrateUpAction(ctx context.Context, cmd *cli.Command) error {
+	steps, err := migrateStepsArg(cmd, 0)
+	if err != nil {
+		return err
+	}
+	return withMigrator(ctx, cmd, func(m migration.Migrator, w io.Writer) error {
+		if cmd.Bool("dry-run") {
+			return printMigrationPlan(w, m, migration.Up, steps)
+		}
+		return runMigration(w, m, func() error { return m.Up(steps) })
+	})
+}
 
-	Config *config.Config
-	Logger *logger.Logger
-	GormDB *gorm.DB
+// migrateStepsArg parses the optional positive N argument, fallback when omitted
+func migrateStepsArg(cmd *cli.Command, fallback int) (int, error) {
+	if cmd.Args().Len() == 0 {
+		return fallback, nil
+	}
+	if cmd.Args().Len() > 1 {
+		return 0, cli.Exit("expected at most one N argument", 1)
+	}
+	steps, err := strconv.Atoi(cmd.Args().First())
+	if err != nil || steps <= 0 {
+		return 0, cli.Exit(fmt.Sprintf("invalid number of migrations %q", cmd.Args().First()), 1)
+	}
+	return steps, nil
 }
 
-func _migrate(params _migrateParams) error {
-	params.Logger.Info("==========migrating database==========")
-	defer os.Exit(0)
+// withMigrator runs fn with a migrator connected to the configured database and
+// turns any failure into exit status 1
+func withMigrator(ctx context.Context, cmd *cli.Command, fn func(m migration.Migrator, w io.Writer) error) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
 
-	migrationPath := "database/migrations"
+	w := cmd.Root().Writer
+	m, err := migration.NewMigratorFromConfig(ctx, cfg, writerLogger{w})
+	if err != nil {
+		return cli.Exit("failed to connect to the database: "+err.Error(), 1)
+	}
+	defer m.Close()
 
-	db, err := params.GormDB.DB()
+	if err := fn(m, w); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	return nil
+}
+
+// runMigration runs migrate and reports the version it ended at
+func runMigration(w io.Writer, m migration.Migrator, migrate func() error) error {
+	before, _, err := m.Version()
 	if err != nil {
-		params.Logger.Error("failed to get database connection", zap.Error(err))
 		return err
 	}
-	defer db.Close()
-
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err := migrate(); err != nil {
+		return err
+	}
+	after, _, err := m.Version()
 	if err != nil {
-		params.Logger.Error("failed to create postgres driver", zap.Error(err))
 		return err
 	}
 
-	m, err := migrate.NewWithDatabaseInstance("file://"+migrationPath, "postgres", driver)
+	if before == after {
+		fmt.Fprintf(w, "Nothing to migrate, the database is at version %d\n", after)
+		return nil
+	}
+	fmt.Fprintf(w, "Migrated from version %d to %d\n", before, after)
+	return nil
+}
+
+func printMigrationPlan(w io.Writer, m migration.Migrator, direction migration.Direction, steps int) error {
+	plan, err := m.Plan(direction, steps)
 	if err != nil {
-		params.Logger.Error("failed to create migrate instance", zap.Error(err))
 		return err
 	}
+	printMigrations(w, plan, direction)
+	return nil
+}
+
+// printMigrations prints the SQL of the migrations, as run by a dry run
+func printMigrations(w io.Writer, plan []migration.Migration, direction migration.Direction) {
+	if len(plan) == 0 {
+		fmt.Fprintf(w, "-- No migration to run %s\n", direction)
+		return
+	}
+	for _, migration := range plan {
+		fmt.Fprintf(w, "-- %06d_%s.%s.sql\n%s\n\n", migration.Version, migration.Identifier, migration.Direction, strings.TrimSpace(migration.SQL))
+	}
+}
 
-	migrationType := "up"
-	if len(os.Args) == 3 {
-		migrationType = os.Args[2]
+func printMigrationVersion(w io.Writer, m migration.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	if dirty {
+		fmt.Fprintf(w, "Version %d (dirty, fix the failed migration then run migrate force)\n", version)
+		return nil
 	}
+	fmt.Fprintf(w, "Version %d\n", version)
+	return nil
+}
 
-	if migrationType == "down" || migrationType == "fresh" {
-		err = m.Down()
-		if err != nil {
-			params.Logger.Error("failed to run migration down", zap.Error(err))
-			return err
-		}
+// writerLogger prints the migrations run by the migrator
+type writerLogger struct {
+	w io.Writer
+}
 
-		params.Logger.Info("migration down completed")
-	}
+func (l writerLogger) Printf(format string, v ...any) {
+	fmt.Fprintf(l.w, "Migrated "+format, v...)
+}
 
-	if migrationType != "down" {
-		err = m.Up()
-		if err != nil {
-			if err == migrate.ErrNoChange {
-				params.Logger.Error("no migration up to run", zap.Error(err))
-				return err
-			}
+type migrateOnServeParams struct {
+	fx.In
 
-			params.Logger.Error("failed to run migration up", zap.Error(err))
-			return err
-		}
+	Config *config.Config
+	Logger *logger.Logger
+}
 
-		params.Logger.Info("migration up completed")
+// migrateOnServe applies every pending migration before the server starts when MIGRATE_ON_SERVE is enabled
+func migrateOnServe(params migrateOnServeParams) error {
+	if !params.Config.MigrateOnServe {
+		return nil
 	}
 
-	params.Logger.Info("==========database migrated==========")
+	m, err := migration.NewMigratorFromConfig(context.Background(), params.Config, zapMigrationLogger{params.Logger})
+	if err != nil {
+		return err
+	}
+	defer m.Close()
 
+	if err := m.Up(0); err != nil {
+		params.Logger.Error("failed to migrate the database", zap.Error(err))
+		return err
+	}
+
+	version, _, err := m.Version()
+	if err != nil {
+		return err
+	}
+	params.Logger.Info("database migrated", zap.Uint("version", version))
 	return nil
 }
+
+// zapMigrationLogger logs the migrations run on serve
+type zapMigrationLogger struct {
+	logger *logger.Logger
+}
+
+func (l zapMigrationLogger) Printf(format string, v ...any) {
+	l.logger.Info("migrated " + strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/infrastructure/di"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
+	"github.com/urfave/cli/v3"
+	"go.uber.org/fx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var openAPIExportCommand = &cli.Command{
+	Name:  "openapi:export",
+	Usage: "Export the OpenAPI document of the registered routes",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Value:   "openapi.json",
+			Usage:   "file to write the document to",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		// The routes only need the handlers to be constructed, so the databases are never dialed
+		db, err := gorm.Open(postgres.Open(cfg.PostgresDSN), &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		var document []byte
+		app := fx.New(
+			fx.NopLogger,
+			di.Module,
+			fx.Replace(cfg, db, database.ReadDB{DB: db}, database.MongoDB{}),
+			fx.Invoke(func(h openapi.OpenAPIHandler) (err error) {
+				document, err = h.Document()
+				return err
+			}),
+		)
+		if err := app.Err(); err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		output := cmd.String("output")
+		if err := os.WriteFile(output, append(document, '\n'), 0644); err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		fmt.Fprintf(cmd.Root().Writer, "OpenAPI document written to %s\n", output)
+		return nil
+	},
+}
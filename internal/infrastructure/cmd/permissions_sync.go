@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/di"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	"github.com/arfanxn/welding/internal/module/permission/usecase"
+	"github.com/arfanxn/welding/internal/module/permission/usecase/dto"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/samber/lo"
+	"github.com/urfave/cli/v3"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var permissionsSyncCommand = &cli.Command{
+	Name:  "permissions:sync",
+	Usage: "Synchronize the permissions declared in the code to the database",
+	Description: "Inserts the missing permissions and grants them to the super admin role, updates their group and\n" +
+		"description, and reports the permissions of the database no longer declared in the code.",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "prune",
+			Usage: "delete the permissions no longer declared in the code, revoking them from every role",
+		},
+	},
+	Action: func(ctx context.Context, cmd *cli.Command) error {
+		var permissionUsecase usecase.PermissionUsecase
+		app := fx.New(
+			fx.NopLogger,
+			di.Module,
+			fx.Populate(&permissionUsecase),
+		)
+		if err := app.Err(); err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		result, err := permissionUsecase.Sync(ctx, &dto.SyncPermissions{Prune: cmd.Bool("prune")})
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		printPermissionsSyncResult(cmd.Root().Writer, result)
+		return nil
+	},
+}
+
+func printPermissionsSyncResult(w io.Writer, result *dto.SyncPermissionsResult) {
+	if len(result.Created) == 0 && result.GrantedRole == nil && len(result.Updated) == 0 && len(result.Orphaned) == 0 {
+		fmt.Fprintln(w, "Permissions are in sync")
+		return
+	}
+
+	for _, permission := range result.Created {
+		fmt.Fprintf(w, "Created %s\n", permission.Name)
+	}
+	if result.GrantedRole != nil {
+		fmt.Fprintf(w, "Granted %s to %s\n", permissionEnum.All, result.GrantedRole.Name)
+	}
+	for _, permission := range result.Updated {
+		fmt.Fprintf(w, "Updated %s\n", permission.Name)
+	}
+	for _, permission := range result.Orphaned {
+		if result.Pruned {
+			fmt.Fprintf(w, "Pruned %s\n", permission.Name)
+			continue
+		}
+		fmt.Fprintf(w, "Orphaned %s (run with --prune to delete it)\n", permission.Name)
+	}
+}
+
+type syncPermissionsOnServeParams struct {
+	fx.In
+
+	Config            *config.Config
+	Logger            *logger.Logger
+	PermissionUsecase usecase.PermissionUsecase
+}
+
+// syncPermissionsOnServe synchronizes the permissions before the server starts when PERMISSIONS_SYNC_ON_SERVE
+// is enabled, orphaned permissions are only logged
+func syncPermissionsOnServe(params syncPermissionsOnServeParams) error {
+	if !params.Config.PermissionsSyncOnServe {
+		return nil
+	}
+
+	result, err := params.PermissionUsecase.Sync(context.Background(), &dto.SyncPermissions{})
+	if err != nil {
+		params.Logger.Error("failed to synchronize the permissions", zap.Error(err))
+		return err
+	}
+
+	permissionNames := func(permissions []*entity.Permission) []string {
+		return lo.Map(permissions, func(permission *entity.Permission, _ int) string { return permission.Name.String() })
+	}
+	params.Logger.Info("permissions synchronized",
+		zap.Strings("created", permissionNames(result.Created)),
+		zap.Strings("updated", permissionNames(result.Updated)),
+	)
+	if len(result.Orphaned) > 0 {
+		params.Logger.Warn("permissions no longer declared in the code, run permissions:sync --prune to delete them",
+			zap.Strings("permissions", permissionNames(result.Orphaned)),
+		)
+	}
+	return nil
+}
@@ -2,14 +2,15 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/arfanxn/welding/internal/infrastructure/database/factory"
 	"github.com/arfanxn/welding/internal/infrastructure/database/seeder"
 	"github.com/arfanxn/welding/internal/infrastructure/di"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
-	"github.com/arfanxn/welding/pkg/reflectutil"
-	"github.com/arfanxn/welding/pkg/typeutil"
 	"github.com/urfave/cli/v3"
 	"go.uber.org/fx"
 	"go.uber.org/fx/fxevent"
@@ -19,7 +20,63 @@ import (
 var seedCommand = &cli.Command{
 	Name:  "seed",
 	Usage: "Run the seed",
+	Description: "Seeders run after the seeders they depend on and insert only the records missing from the database,\n" +
+		"so seeding an already seeded database is safe.",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "only",
+			Usage: "run only the given seeders, e.g. --only=permission,role (permission, role, user, fake_user)",
+		},
+		&cli.StringFlag{
+			Name:  "env",
+			Value: string(seeder.Development),
+			Usage: "development seeds sample users, production seeds only the permissions, the roles and the super admin",
+		},
+		&cli.IntFlag{
+			Name:  "count",
+			Usage: "number of fake users to generate, e.g. for load testing",
+		},
+		&cli.StringFlag{
+			Name:    "super-admin-email",
+			Sources: cli.EnvVars("SUPER_ADMIN_EMAIL"),
+			Usage:   "email of the super admin, required in production",
+		},
+		&cli.StringFlag{
+			Name:    "super-admin-password",
+			Sources: cli.EnvVars("SUPER_ADMIN_PASSWORD"),
+			Usage:   "password of the super admin, required in production unless given by --super-admin-password-file",
+		},
+		&cli.StringFlag{
+			Name:    "super-admin-password-file",
+			Sources: cli.EnvVars("SUPER_ADMIN_PASSWORD_FILE"),
+			Usage:   "file holding the password of the super admin, e.g. /run/secrets/super_admin_password (Docker secrets)",
+		},
+	},
 	Action: func(ctx context.Context, cmd *cli.Command) error {
+		options := seeder.Options{
+			Environment:        seeder.Environment(cmd.String("env")),
+			Count:              cmd.Int("count"),
+			SuperAdminEmail:    cmd.String("super-admin-email"),
+			SuperAdminPassword: cmd.String("super-admin-password"),
+		}
+		if path := cmd.String("super-admin-password-file"); path != "" && options.SuperAdminPassword == "" {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to read the super admin password: %s", err), 1)
+			}
+			options.SuperAdminPassword = strings.TrimRight(string(content), "\r\n")
+		}
+		if !slices.Contains(seeder.Environments, options.Environment) {
+			return cli.Exit(fmt.Sprintf("invalid environment %q, expected %s or %s", options.Environment, seeder.Development, seeder.Production), 1)
+		}
+		if options.Count < 0 {
+			return cli.Exit(fmt.Sprintf("invalid count %d", options.Count), 1)
+		}
+		if options.Environment == seeder.Production && options.Count > 0 {
+			return cli.Exit("fake users cannot be generated in production", 1)
+		}
+
+		var params seedParams
 		app := fx.New(
 			di.Module,
 			fx.Provide(
@@ -30,6 +87,7 @@ var seedCommand = &cli.Command{
 				fx.Annotate(factory.NewUserFactory, fx.ResultTags(`name:"user_factory"`)),
 
 				fx.Annotate(seeder.NewUserSeeder, fx.As(new(seeder.Seeder)), fx.ResultTags(`group:"seeders"`)),
+				fx.Annotate(seeder.NewFakeUserSeeder, fx.As(new(seeder.Seeder)), fx.ResultTags(`group:"seeders"`)),
 				fx.Annotate(seeder.NewRoleSeeder, fx.As(new(seeder.Seeder)), fx.ResultTags(`group:"seeders"`)),
 				fx.Annotate(seeder.NewPermissionSeeder, fx.As(new(seeder.Seeder)), fx.ResultTags(`group:"seeders"`)),
 			),
@@ -37,11 +95,15 @@ var seedCommand = &cli.Command{
 				fxLogger := l.With(zap.String("component", "fx"))
 				return &fxevent.ZapLogger{Logger: fxLogger}
 			}),
-			fx.Invoke(seed),
+			fx.Populate(&params),
 		)
+		if err := app.Err(); err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
 
-		app.Run()
-
+		if err := seed(ctx, params, cmd.StringSlice("only"), options); err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
 		return nil
 	},
 }
@@ -53,30 +115,27 @@ type seedParams struct {
 	Seeders []seeder.Seeder `group:"seeders"`
 }
 
-func mustGetByTypeFromSeeders[T any](seeders []seeder.Seeder) T {
-	return typeutil.MustGetByTypeFromArray[seeder.Seeder, T](seeders)
-}
-
-func mustGetOrderedSeeders(seeders []seeder.Seeder) []seeder.Seeder {
-	orderedSeeders := []seeder.Seeder{
-		mustGetByTypeFromSeeders[*seeder.PermissionSeeder](seeders),
-		mustGetByTypeFromSeeders[*seeder.RoleSeeder](seeders),
-		mustGetByTypeFromSeeders[*seeder.UserSeeder](seeders),
+// seed runs the seeders named by only, every seeder when empty, after the seeders they depend on
+func seed(ctx context.Context, params seedParams, only []string, options seeder.Options) error {
+	orderedSeeders, err := seeder.Order(params.Seeders)
+	if err != nil {
+		return err
+	}
+	orderedSeeders, err = seeder.Select(orderedSeeders, only)
+	if err != nil {
+		return err
 	}
-	return orderedSeeders
-}
-
-func seed(params seedParams) error {
-	params.Logger.Info("==========seeding database==========")
-	defer os.Exit(0)
 
-	orderedSeeders := mustGetOrderedSeeders(params.Seeders)
+	params.Logger.Info("==========seeding database==========",
+		zap.String("environment", string(options.Environment)),
+		zap.Strings("seeders", seeder.Names(orderedSeeders)),
+	)
 
 	for _, s := range orderedSeeders {
-		seederName := reflectutil.GetStructName(s)
+		seederName := s.Name()
 		params.Logger.Info("seeding " + seederName)
 
-		if err := s.Seed(); err != nil {
+		if err := s.Seed(ctx, options); err != nil {
 			params.Logger.Error(
 				"failed to seed "+seederName,
 				zap.Error(err),
@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 
 	"github.com/arfanxn/welding/internal/infrastructure/config"
 	"github.com/arfanxn/welding/internal/infrastructure/di"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
 	"github.com/gin-gonic/gin"
 	"github.com/urfave/cli/v3"
 	"go.uber.org/fx"
@@ -19,7 +22,10 @@ var serveCommand = &cli.Command{
 	Action: func(ctx context.Context, cmd *cli.Command) error {
 		app := fx.New(
 			di.Module,
+			fx.Invoke(migrateOnServe),
+			fx.Invoke(syncPermissionsOnServe),
 			fx.Invoke(serve),
+			fx.Invoke(serveMetrics),
 		)
 
 		app.Run()
@@ -60,3 +66,38 @@ func serve(params serveParams) {
 		},
 	)
 }
+
+type serveMetricsParams struct {
+	fx.In
+
+	Lifecycle      fx.Lifecycle
+	Logger         *logger.Logger
+	MetricsService metrics.MetricsService
+	Config         *config.Config
+}
+
+// serveMetrics serves GET /metrics on METRICS_PORT, apart from the API so it is not exposed with it
+func serveMetrics(params serveMetricsParams) {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", params.MetricsService.Handler())
+	server := &http.Server{Addr: fmt.Sprintf(":%s", params.Config.MetricsPort), Handler: mux}
+
+	params.Lifecycle.Append(
+		fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				params.Logger.Info("starting metrics server", zap.String("port", params.Config.MetricsPort))
+
+				go func() {
+					if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+						params.Logger.Error("metrics server error", zap.Error(err))
+					}
+				}()
+
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return server.Shutdown(ctx)
+			},
+		},
+	)
+}
@@ -1,69 +1,131 @@
 package config
 
 import (
-	"os"
-	"reflect"
-
-	"github.com/arfanxn/welding/pkg/reflectutil"
-	"github.com/joho/godotenv"
+	"net/url"
+	"time"
 )
 
+// Config holds the application configuration.
+//
+// Every field is read from the environment variable named by its `env` tag and supports:
+//   - `default:"..."`  value used when the variable is not set anywhere
+//   - `required:"true"` the variable must resolve to a non-empty value
+//   - `options:"a,b"`  the value must be one of the listed options
+//   - `secret:"true"`  the value is masked when the configuration is printed
+//
+// Optional URLs are *url.URL, nil when unset, while required or defaulted ones are url.URL.
+//
+// See Load for the sources a value can come from and their precedence.
 type Config struct {
 	// App
-	AppName string `env:"APP_NAME"`
-	AppHost string `env:"APP_HOST"`
-	AppPort string `env:"APP_PORT"`
+	AppName string `env:"APP_NAME" default:"Welding"`
+	AppPort string `env:"APP_PORT" default:"8080"`
 
 	// Gin
-	GinMode string `env:"GIN_MODE"`
+	GinMode string `env:"GIN_MODE" default:"debug" options:"debug,release,test"`
+
+	// HTTP
+	TrustedProxies       []string      `env:"TRUSTED_PROXIES" default:"127.0.0.1,::1"`
+	CORSAllowedOrigins   []string      `env:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowedMethods   []string      `env:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORSAllowedHeaders   []string      `env:"CORS_ALLOWED_HEADERS" default:"Authorization,Content-Type,Accept,Accept-Language,X-Request-ID,Idempotency-Key,If-Match,If-None-Match"`
+	CORSExposedHeaders   []string      `env:"CORS_EXPOSED_HEADERS" default:"X-Request-ID,Idempotent-Replayed,Retry-After,ETag"`
+	CORSAllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	CORSMaxAge           time.Duration `env:"CORS_MAX_AGE" default:"12h"`
+	SecurityHSTSMaxAge   time.Duration `env:"SECURITY_HSTS_MAX_AGE" default:"8760h"`
+	SecurityCSP          string        `env:"SECURITY_CSP" default:"default-src 'none'; frame-ancestors 'none'"`
+	RequireIfMatch       bool          `env:"HTTP_REQUIRE_IF_MATCH" default:"false"`
+
+	// Idempotency
+	IdempotencyTTL             time.Duration `env:"IDEMPOTENCY_TTL" default:"24h"`
+	IdempotencyLockTimeout     time.Duration `env:"IDEMPOTENCY_LOCK_TIMEOUT" default:"1m"`
+	IdempotencyCleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL" default:"1h"`
+
+	// Locale
+	DefaultLocale string `env:"DEFAULT_LOCALE" default:"id" options:"id,en"`
 
 	// Database
-	PostgresDSN string `env:"POSTGRES_DSN"`
+	PostgresDSN    string `env:"POSTGRES_DSN" required:"true" secret:"true"`
+	MigrateOnServe bool   `env:"MIGRATE_ON_SERVE" default:"false"`
+	// PermissionsSyncOnServe synchronizes the permissions of the code to the database on start, never pruning
+	PermissionsSyncOnServe bool `env:"PERMISSIONS_SYNC_ON_SERVE" default:"false"`
+	// PostgresReadDSN points to a read replica serving the Get and Paginate repository methods, the primary when empty
+	PostgresReadDSN      string        `env:"POSTGRES_READ_DSN" secret:"true"`
+	DBMaxOpenConns       int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+	DBMaxIdleConns       int           `env:"DB_MAX_IDLE_CONNS" default:"10"`
+	DBConnMaxLifetime    time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"30m"`
+	DBConnMaxIdleTime    time.Duration `env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+	DBStatementTimeout   time.Duration `env:"DB_STATEMENT_TIMEOUT" default:"10s"`
+	DBSlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+	DBLogLevel           string        `env:"DB_LOG_LEVEL" default:"warn" options:"silent,error,warn,info"`
+	DBPrepareStmt        bool          `env:"DB_PREPARE_STMT" default:"true"`
+
+	// Mongo
+	// MongoDSN enables the Mongo store of the audit events and request logs, Postgres stores them when empty
+	MongoDSN            string        `env:"MONGO_DSN" secret:"true"`
+	MongoDatabase       string        `env:"MONGO_DB" default:"welding"`
+	MongoConnectTimeout time.Duration `env:"MONGO_CONNECT_TIMEOUT" default:"10s"`
+
+	// Request log
+	// RequestLogEnabled stores a record of every HTTP request, in addition to the access log
+	RequestLogEnabled bool `env:"REQUEST_LOG_ENABLED" default:"false"`
 
 	// Log
-	LogLevel    string `env:"LOG_LEVEL"`
-	LogFilepath string `env:"LOG_FILEPATH"`
+	LogLevel              string `env:"LOG_LEVEL" default:"info" options:"debug,info,warn,error,dpanic,panic,fatal"`
+	LogConsoleLevel       string `env:"LOG_CONSOLE_LEVEL" options:"debug,info,warn,error,dpanic,panic,fatal"`
+	LogFileLevel          string `env:"LOG_FILE_LEVEL" options:"debug,info,warn,error,dpanic,panic,fatal"`
+	LogFilepath           string `env:"LOG_FILEPATH" default:"./logs/application.log"`
+	LogMaxSize            int    `env:"LOG_MAX_SIZE" default:"100"`
+	LogMaxAge             int    `env:"LOG_MAX_AGE" default:"30"`
+	LogMaxBackups         int    `env:"LOG_MAX_BACKUPS" default:"10"`
+	LogCompress           bool   `env:"LOG_COMPRESS" default:"true"`
+	LogSampling           bool   `env:"LOG_SAMPLING" default:"false"`
+	LogSamplingInitial    int    `env:"LOG_SAMPLING_INITIAL" default:"100"`
+	LogSamplingThereafter int    `env:"LOG_SAMPLING_THEREAFTER" default:"100"`
 
 	// JWT
-	JWTSecret   string `env:"JWT_SECRET"`
-	JWTDuration int    `env:"JWT_DURATION"`
+	JWTSecret   string `env:"JWT_SECRET" required:"true" secret:"true"`
+	JWTDuration int    `env:"JWT_DURATION" default:"24"`
+	// JWTPermissionVersion embeds the permission version of the user in the tokens, which are refused once it changed
+	JWTPermissionVersion bool `env:"JWT_PERMISSION_VERSION" default:"false"`
+
+	// Authorization
+	// PermissionCacheTTL bounds how long the resolved permissions of a user are cached, 0 disables the cache
+	PermissionCacheTTL time.Duration `env:"PERMISSION_CACHE_TTL" default:"5m"`
+	// RoleGrantCheckInterval is how often the temporary role grants that began or ended are announced, 0 disables it
+	RoleGrantCheckInterval time.Duration `env:"ROLE_GRANT_CHECK_INTERVAL" default:"1m"`
 
 	// Mail
-	MailHost        string `env:"MAIL_HOST"`
-	MailPort        int    `env:"MAIL_PORT"`
+	MailHost        string `env:"MAIL_HOST" required:"true"`
+	MailPort        int    `env:"MAIL_PORT" default:"587"`
 	MailIdentity    string `env:"MAIL_IDENTITY"`
 	MailUsername    string `env:"MAIL_USERNAME"`
-	MailPassword    string `env:"MAIL_PASSWORD"`
+	MailPassword    string `env:"MAIL_PASSWORD" secret:"true"`
 	MailEncryption  string `env:"MAIL_ENCRYPTION"`
-	MailFromAddress string `env:"MAIL_FROM_ADDRESS"`
+	MailFromAddress string `env:"MAIL_FROM_ADDRESS" required:"true"`
 	MailFromName    string `env:"MAIL_FROM_NAME"`
+
+	// Health
+	HealthCheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" default:"3s"`
+	HealthCacheTTL     time.Duration `env:"HEALTH_CACHE_TTL" default:"5s"`
+
+	// Metrics
+	// MetricsPort serves GET /metrics on a listener of its own, apart from the API, to be reached from the
+	// internal network only
+	MetricsPort string `env:"METRICS_PORT" default:"9090"`
+
+	// Tracing, the OTLP endpoint is a full URL such as http://localhost:4318, a plain http one being insecure
+	TracingExporter     string   `env:"TRACING_EXPORTER" default:"none" options:"none,stdout,otlp"`
+	TracingOTLPEndpoint *url.URL `env:"TRACING_OTLP_ENDPOINT"`
+	TracingOTLPInsecure bool     `env:"TRACING_OTLP_INSECURE" default:"false"`
+	TracingSampleRatio  float64  `env:"TRACING_SAMPLE_RATIO" default:"1"`
+
+	// sources records where the value of each variable came from, keyed by variable name
+	sources map[string]Source
 }
 
-// NewConfigFromEnv creates a new Config instance with values from environment variables
+// NewConfigFromEnv creates a new Config instance with values from environment variables,
+// Docker secrets (*_FILE variables), the optional YAML config file and tag defaults.
 func NewConfigFromEnv() (*Config, error) {
-	err := godotenv.Load()
-	if err != nil {
-		return nil, err
-	}
-	config := &Config{}
-
-	val := reflect.ValueOf(config).Elem()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Type().Field(i)
-		envKey := field.Tag.Get("env")
-		if envKey == "" {
-			continue
-		}
-
-		envValue, exists := os.LookupEnv(envKey)
-		if !exists {
-			continue
-		}
-
-		if err := reflectutil.SetValueFromString(val.Field(i), envValue); err != nil {
-			return nil, err
-		}
-	}
-
-	return config, nil
+	return Load()
 }
@@ -0,0 +1,214 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/arfanxn/welding/pkg/reflectutil"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Source describes where the value of a configuration variable came from
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceFile    Source = "file"
+	SourceYAML    Source = "yaml"
+	SourceDefault Source = "default"
+	SourceUnset   Source = "unset"
+)
+
+const (
+	// EnvFilepath is the dotenv file loaded into the environment when present
+	EnvFilepath = ".env"
+	// ConfigFileEnv names the variable pointing to the YAML config file
+	ConfigFileEnv = "CONFIG_FILE"
+	// DefaultConfigFilepath is the YAML config file loaded when present and CONFIG_FILE is not set
+	DefaultConfigFilepath = "config.yaml"
+	// fileEnvSuffix is appended to a variable name to read its value from a file (e.g. Docker secrets)
+	fileEnvSuffix = "_FILE"
+	// maskedValue replaces the value of secret variables when printed
+	maskedValue = "********"
+)
+
+// ValidationError aggregates every problem found while loading the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems):\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Entry is a single configuration variable as printed by Describe
+type Entry struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Load builds the configuration. The value of each variable is resolved, from highest
+// to lowest precedence, from:
+//  1. the environment (including the optional .env file, which never overrides real env vars)
+//  2. the file named by <VAR>_FILE, e.g. JWT_SECRET_FILE=/run/secrets/jwt_secret
+//  3. the optional YAML config file (CONFIG_FILE, or config.yaml when present)
+//  4. the `default` tag
+//
+// Empty values are treated as unset. All problems are reported at once as a *ValidationError.
+func Load() (*Config, error) {
+	if err := godotenv.Load(EnvFilepath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load %s: %w", EnvFilepath, err)
+	}
+
+	yamlValues, err := loadYAML()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{sources: map[string]Source{}}
+	var problems []string
+
+	val := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		envKey := field.Tag.Get("env")
+		if envKey == "" {
+			continue
+		}
+
+		value, source, err := lookup(envKey, yamlValues)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if source == SourceUnset {
+			if defaultValue, ok := field.Tag.Lookup("default"); ok {
+				value, source = defaultValue, SourceDefault
+			}
+		}
+		cfg.sources[envKey] = source
+
+		if value == "" {
+			if field.Tag.Get("required") == "true" {
+				problems = append(problems, fmt.Sprintf("%s is required", envKey))
+			}
+			continue
+		}
+
+		if options := field.Tag.Get("options"); options != "" && !slices.Contains(strings.Split(options, ","), value) {
+			problems = append(problems, fmt.Sprintf("%s must be one of [%s], got %q", envKey, options, value))
+			continue
+		}
+
+		if err := reflectutil.SetValueFromString(val.Field(i), value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s is invalid (%s source): %s", envKey, source, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+// Describe returns every configuration variable with its effective value and source.
+// Values of fields tagged `secret:"true"` are masked.
+func (c *Config) Describe() ([]Entry, error) {
+	var entries []Entry
+
+	val := reflect.ValueOf(c).Elem()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		envKey := field.Tag.Get("env")
+		if envKey == "" {
+			continue
+		}
+
+		value, err := reflectutil.GetValueAsString(val.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = maskedValue
+		}
+
+		source, ok := c.sources[envKey]
+		if !ok {
+			source = SourceUnset
+		}
+
+		entries = append(entries, Entry{Key: envKey, Value: value, Source: source})
+	}
+
+	return entries, nil
+}
+
+// lookup resolves the raw value of a variable from the environment, its *_FILE variant or the YAML values.
+func lookup(envKey string, yamlValues map[string]string) (string, Source, error) {
+	if value := os.Getenv(envKey); value != "" {
+		return value, SourceEnv, nil
+	}
+
+	if path := os.Getenv(envKey + fileEnvSuffix); path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", SourceUnset, fmt.Errorf("%s%s: %s", envKey, fileEnvSuffix, err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), SourceFile, nil
+	}
+
+	if value := yamlValues[envKey]; value != "" {
+		return value, SourceYAML, nil
+	}
+
+	return "", SourceUnset, nil
+}
+
+// loadYAML reads the optional YAML config file. Keys are variable names (case-insensitive),
+// lists are joined with commas so they parse like their env counterpart.
+func loadYAML() (map[string]string, error) {
+	path, explicit := os.LookupEnv(ConfigFileEnv)
+	if !explicit || path == "" {
+		path = DefaultConfigFilepath
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		key = strings.ToUpper(key)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// setRequired sets the variables Load requires, clearing the ones a test may read
+func setRequired(t *testing.T) {
+	t.Helper()
+	t.Setenv("POSTGRES_DSN", "postgres://welding@localhost/welding")
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("MAIL_HOST", "localhost")
+	t.Setenv("MAIL_FROM_ADDRESS", "welding@example.com")
+	for _, key := range []string{"APP_NAME", "APP_NAME_FILE", "JWT_SECRET_FILE", "GIN_MODE", "CORS_MAX_AGE", "MAIL_PASSWORD"} {
+		t.Setenv(key, "")
+	}
+}
+
+// writeFile writes content to a file of the test directory and returns its path
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        string
+		file       string
+		yaml       string
+		want       string
+		wantSource Source
+	}{
+		{name: "default", want: "Welding", wantSource: SourceDefault},
+		{name: "YAML over default", yaml: "Yaml", want: "Yaml", wantSource: SourceYAML},
+		{name: "file over YAML", file: "File\n", yaml: "Yaml", want: "File", wantSource: SourceFile},
+		{name: "env over file", env: "Env", file: "File", yaml: "Yaml", want: "Env", wantSource: SourceEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			t.Setenv(ConfigFileEnv, writeFile(t, "config.yaml", "app_name: "+tt.yaml+"\n"))
+			t.Setenv("APP_NAME", tt.env)
+			if tt.file != "" {
+				t.Setenv("APP_NAME"+fileEnvSuffix, writeFile(t, "app_name", tt.file))
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.AppName != tt.want {
+				t.Errorf("AppName = %q, want %q", cfg.AppName, tt.want)
+			}
+			if got := cfg.sources["APP_NAME"]; got != tt.wantSource {
+				t.Errorf("source = %q, want %q", got, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "value out of the options",
+			env:  map[string]string{"GIN_MODE": "verbose"},
+			want: []string{`GIN_MODE must be one of [debug,release,test], got "verbose"`},
+		},
+		{
+			name: "required value missing",
+			env:  map[string]string{"JWT_SECRET": ""},
+			want: []string{"JWT_SECRET is required"},
+		},
+		{
+			name: "value not parsable",
+			env:  map[string]string{"CORS_MAX_AGE": "soon"},
+			want: []string{`CORS_MAX_AGE is invalid (env source): time: invalid duration "soon"`},
+		},
+		{
+			name: "secret file missing",
+			env:  map[string]string{"JWT_SECRET": "", "JWT_SECRET_FILE": "/nonexistent/jwt_secret"},
+			want: []string{"JWT_SECRET_FILE: open /nonexistent/jwt_secret: no such file or directory"},
+		},
+		{
+			name: "every problem reported at once",
+			env:  map[string]string{"GIN_MODE": "verbose", "JWT_SECRET": ""},
+			want: []string{`GIN_MODE must be one of [debug,release,test], got "verbose"`, "JWT_SECRET is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			t.Setenv(ConfigFileEnv, writeFile(t, "config.yaml", "{}\n"))
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			cfg, err := Load()
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Load() = %v, %v, want a *ValidationError", cfg, err)
+			}
+			if !slices.Equal(validationErr.Problems, tt.want) {
+				t.Errorf("problems = %q, want %q", validationErr.Problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeMasksSecrets(t *testing.T) {
+	setRequired(t)
+	t.Setenv(ConfigFileEnv, writeFile(t, "config.yaml", "{}\n"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	entries, err := cfg.Describe()
+	if err != nil {
+		t.Fatalf("Describe() error = %v", err)
+	}
+
+	want := map[string]Entry{
+		"JWT_SECRET":    {Key: "JWT_SECRET", Value: maskedValue, Source: SourceEnv},
+		"MAIL_PASSWORD": {Key: "MAIL_PASSWORD", Value: "", Source: SourceUnset},
+		"MAIL_HOST":     {Key: "MAIL_HOST", Value: "localhost", Source: SourceEnv},
+	}
+	for _, entry := range entries {
+		if wantEntry, ok := want[entry.Key]; ok && entry != wantEntry {
+			t.Errorf("Describe() %s = %+v, want %+v", entry.Key, entry, wantEntry)
+		}
+	}
+}
@@ -10,11 +10,17 @@ import (
 	"github.com/guregu/null/v6"
 )
 
+// departments are the departments fake employees are spread across
+var departments = []string{"Production", "Quality Control", "Maintenance", "Engineering", "Warehouse"}
+
 func NewEmployeeFactory() *factory.Factory {
 	return factory.NewFactory(&entity.Employee{}).
 		Attr("EmploymentIdentityNumber", func(args factory.Args) (any, error) {
 			return goutil.ToString(gofakeit.IntRange(100000000000000000, 900000000000000000))
 		}).
+		Attr("Department", func(args factory.Args) (any, error) {
+			return null.StringFrom(gofakeit.RandomString(departments)), nil
+		}).
 		Attr("CreatedAt", func(args factory.Args) (any, error) {
 			return gofakeit.DateRange(time.Now().Add(-time.Hour*24*365), time.Now()), nil
 		}).
@@ -16,6 +16,9 @@ func NewRoleFactory(
 		Attr("Id", func(args factory.Args) (any, error) {
 			return idService.Generate(), nil
 		}).
+		Attr("Version", func(args factory.Args) (any, error) {
+			return int64(1), nil
+		}).
 		Attr("IsDefault", func(args factory.Args) (any, error) {
 			return false, nil
 		}).
@@ -18,6 +18,9 @@ func NewUserFactory(
 		Attr("Id", func(args factory.Args) (any, error) {
 			return idService.Generate(), nil
 		}).
+		Attr("Version", func(args factory.Args) (any, error) {
+			return int64(1), nil
+		}).
 		Attr("Name", func(args factory.Args) (any, error) {
 			return gofakeit.Name(), nil
 		}).
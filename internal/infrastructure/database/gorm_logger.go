@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+var _ gormLogger.Interface = (*zapGormLogger)(nil)
+var _ gorm.ParamsFilter = (*zapGormLogger)(nil)
+
+// zapGormLogger routes the GORM logs to zap. Failed statements are logged as errors, statements
+// slower than slowThreshold as warnings and, at the info level, every statement at debug level.
+type zapGormLogger struct {
+	logger        *logger.Logger
+	level         gormLogger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewZapGormLogger creates a GORM logger writing to l at the given level
+// (silent, error, warn or info), statements slower than slowThreshold are logged as warnings
+func NewZapGormLogger(l *logger.Logger, level string, slowThreshold time.Duration) gormLogger.Interface {
+	levels := map[string]gormLogger.LogLevel{
+		"silent": gormLogger.Silent,
+		"error":  gormLogger.Error,
+		"warn":   gormLogger.Warn,
+		"info":   gormLogger.Info,
+	}
+	logLevel, ok := levels[level]
+	if !ok {
+		logLevel = gormLogger.Warn
+	}
+
+	return &zapGormLogger{
+		logger:        &logger.Logger{Logger: l.With(zap.String("component", "gorm"))},
+		level:         logLevel,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *zapGormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *zapGormLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Info {
+		l.logger.FromContext(ctx).Info(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Warn {
+		l.logger.FromContext(ctx).Warn(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= gormLogger.Error {
+		l.logger.FromContext(ctx).Error(fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *zapGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.String("source", utils.FileWithLineNum()),
+		}
+	}
+
+	switch {
+	// A missing record is an expected outcome, the repositories turn it into a domain error
+	case err != nil && l.level >= gormLogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		l.logger.FromContext(ctx).Error("database statement failed", append(fields(), zap.Error(err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= gormLogger.Warn:
+		l.logger.FromContext(ctx).Warn("slow database statement", append(fields(), zap.Duration("threshold", l.slowThreshold))...)
+	case l.level >= gormLogger.Info:
+		l.logger.FromContext(ctx).Debug("database statement", fields()...)
+	}
+}
+
+// ParamsFilter keeps the values bound to statements, such as password hashes, out of the logs
+func (l *zapGormLogger) ParamsFilter(ctx context.Context, sql string, params ...any) (string, []any) {
+	return sql, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // IsPostgresDuplicateKeyError checks if the error is a PostgreSQL duplicate key error
@@ -18,6 +19,51 @@ func IsPostgresDuplicateKeyError(err error) bool {
 	return false
 }
 
+// GormDBSaveVersioned saves a model guarded by optimistic locking, version points to its version field.
+// A model at version zero is created at version one, otherwise it is updated only while the stored version
+// is still the loaded one and its version is incremented. It returns false when the row changed in between.
+func GormDBSaveVersioned(db *gorm.DB, model any, version *int64) (bool, error) {
+	current := *version
+	if current == 0 {
+		*version = 1
+		if err := db.Create(model).Error; err != nil {
+			*version = current
+			return false, err
+		}
+		return true, nil
+	}
+
+	*version = current + 1
+	result := db.Model(model).Select("*").Omit(clause.Associations).Where("version = ?", current).Updates(model)
+	if result.Error != nil || result.RowsAffected == 0 {
+		*version = current
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// GormDBDestroyVersioned deletes a model only while its stored version is still version.
+// It returns false when the row changed in between.
+func GormDBDestroyVersioned(db *gorm.DB, model any, version int64) (bool, error) {
+	result := db.Where("version = ?", version).Delete(model)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GormDBCreateManyIgnoringConflicts inserts records in batches and skips those conflicting with an
+// existing row on columns, or on any unique constraint when no column is given. It returns the number
+// of rows inserted.
+func GormDBCreateManyIgnoringConflicts(db *gorm.DB, records any, columns ...string) (int64, error) {
+	onConflict := clause.OnConflict{DoNothing: true}
+	for _, column := range columns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	result := db.Clauses(onConflict).CreateInBatches(records, 100)
+	return result.RowsAffected, result.Error
+}
+
 func GormDBPaginateWithQuery[T any](db *gorm.DB, q *query.Query) (*pagination.OffsetPagination[T], error) {
 	// First, get the total count of matching records (before applying pagination)
 	var totalItems int64
@@ -49,3 +95,54 @@ func GormDBPaginateWithQuery[T any](db *gorm.DB, q *query.Query) (*pagination.Of
 
 	return pagination.NewOffsetPagination(offset, limit, int(totalItems), items), nil
 }
+
+// GormDBIncrementPermissionVersion increments the permission version of the users whose id is in userIds,
+// a slice of ids or a subquery selecting them, once their roles or the permissions of their roles changed
+func GormDBIncrementPermissionVersion(db *gorm.DB, userIds any) error {
+	return db.Table("users").
+		Where("id IN (?)", userIds).
+		UpdateColumn("permission_version", gorm.Expr("permission_version + 1")).Error
+}
+
+// GormDBRoleUserIds returns the subquery selecting the ids of the users holding one of roleIds, a role id,
+// a slice of them or a subquery selecting them
+func GormDBRoleUserIds(db *gorm.DB, roleIds any) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Table("role_user").
+		Select("user_id").
+		Where("role_id IN (?)", roleIds)
+}
+
+// GormDBRoleAncestorIds returns the subquery selecting the ids of the parents of roleIds, a role id, a slice
+// of them or a subquery selecting them, and of their parents in turn. UNION drops the rows already selected,
+// so a cycle of parents ends the recursion.
+func GormDBRoleAncestorIds(db *gorm.DB, roleIds any) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Raw(`WITH RECURSIVE ancestors (id) AS (
+		SELECT parent_id FROM roles WHERE id IN (?) AND parent_id IS NOT NULL
+		UNION
+		SELECT roles.parent_id FROM roles JOIN ancestors ON roles.id = ancestors.id WHERE roles.parent_id IS NOT NULL
+	) SELECT id FROM ancestors`, roleIds)
+}
+
+// GormDBRoleDescendantIds returns the subquery selecting roleIds, a role id, a slice of them or a subquery
+// selecting them, and the ids of the roles inheriting from them, which are affected by a change to them
+func GormDBRoleDescendantIds(db *gorm.DB, roleIds any) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Raw(`WITH RECURSIVE descendants (id) AS (
+		SELECT id FROM roles WHERE id IN (?)
+		UNION
+		SELECT roles.id FROM roles JOIN descendants ON roles.parent_id = descendants.id
+	) SELECT id FROM descendants`, roleIds)
+}
+
+// RoleUserInEffectCondition selects the role_user rows of the grants in effect, those started and not expired
+const RoleUserInEffectCondition = "role_user.starts_at <= CURRENT_TIMESTAMP AND (role_user.expires_at IS NULL OR role_user.expires_at > CURRENT_TIMESTAMP)"
+
+// GormDBAllRoleGrantsKey is the setting that lets the queries of role_user read every grant, see GormDBAllRoleGrants
+const GormDBAllRoleGrantsKey = "database:all_role_grants"
+
+// GormDBAllRoleGrants returns db reading the grants not in effect yet or anymore as well. The queries of
+// role_user, the preloads of the roles of users and of the users of roles included, otherwise only read
+// the grants in effect.
+func GormDBAllRoleGrants(db *gorm.DB) *gorm.DB {
+	return db.Set(GormDBAllRoleGrantsKey, true)
+}
@@ -0,0 +1,345 @@
+// Package migration applies the SQL migrations embedded in the binary with golang-migrate.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/arfanxn/welding/database/migrations"
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/iancoleman/strcase"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Direction tells whether a migration is applied (up) or rolled back (down)
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+// Migration is a single migration file
+type Migration struct {
+	Version    uint
+	Identifier string
+	Direction  Direction
+	SQL        string
+}
+
+// Status is the state of a migration in the database
+type Status struct {
+	Version    uint
+	Identifier string
+	Applied    bool
+	// Dirty is set on the current version when a migration failed halfway
+	Dirty bool
+}
+
+// Logger receives a line for every migration that has been run
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Migrator runs the embedded migrations against the configured database.
+// Steps of zero mean every pending migration for Up and every applied migration for Down.
+type Migrator interface {
+	// Up applies the next steps pending migrations
+	Up(steps int) error
+	// Down rolls back the last steps applied migrations
+	Down(steps int) error
+	// Fresh rolls back every applied migration then applies every migration
+	Fresh() error
+	// Force sets the version without running any migration, e.g. to clear the dirty state after a failure
+	Force(version int) error
+	// Version returns the current version, zero when no migration has been applied
+	Version() (version uint, dirty bool, err error)
+	// Status lists every migration and whether it has been applied
+	Status() ([]Status, error)
+	// Plan returns the migrations, with their SQL, that Up or Down would run for steps
+	Plan(direction Direction, steps int) ([]Migration, error)
+	// Close releases the database connection of the migrator
+	Close() error
+}
+
+type migrator struct {
+	m *migrate.Migrate
+}
+
+// NewMigratorFromConfig opens a dedicated connection to the database of cfg. logger may be nil.
+func NewMigratorFromConfig(ctx context.Context, cfg *config.Config, logger Logger) (Migrator, error) {
+	db, err := sql.Open("pgx", cfg.PostgresDSN)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	if logger != nil {
+		m.Log = migrateLogger{logger}
+	}
+
+	return &migrator{m: m}, nil
+}
+
+func (mg *migrator) Up(steps int) error {
+	plan, err := mg.Plan(Up, steps)
+	if err != nil || len(plan) == 0 {
+		return err
+	}
+	return ignoreNoChange(mg.m.Steps(len(plan)))
+}
+
+func (mg *migrator) Down(steps int) error {
+	plan, err := mg.Plan(Down, steps)
+	if err != nil || len(plan) == 0 {
+		return err
+	}
+	return ignoreNoChange(mg.m.Steps(-len(plan)))
+}
+
+func (mg *migrator) Fresh() error {
+	if err := ignoreNoChange(mg.m.Down()); err != nil {
+		return err
+	}
+	return ignoreNoChange(mg.m.Up())
+}
+
+func (mg *migrator) Force(version int) error {
+	// -1 clears the version as if no migration had been applied
+	if version == -1 {
+		return mg.m.Force(version)
+	}
+	if version <= 0 {
+		return fmt.Errorf("invalid version %d", version)
+	}
+	if _, err := mg.find(uint(version)); err != nil {
+		return err
+	}
+	return mg.m.Force(version)
+}
+
+func (mg *migrator) Version() (uint, bool, error) {
+	version, dirty, err := mg.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+func (mg *migrator) Status() ([]Status, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := Embedded(Up)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(all))
+	for _, migration := range all {
+		statuses = append(statuses, Status{
+			Version:    migration.Version,
+			Identifier: migration.Identifier,
+			Applied:    migration.Version <= current,
+			Dirty:      dirty && migration.Version == current,
+		})
+	}
+	return statuses, nil
+}
+
+func (mg *migrator) Plan(direction Direction, steps int) ([]Migration, error) {
+	current, dirty, err := mg.Version()
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, migrate.ErrDirty{Version: int(current)}
+	}
+
+	all, err := Embedded(direction)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []Migration
+	switch direction {
+	case Up:
+		for _, migration := range all {
+			if migration.Version > current {
+				plan = append(plan, migration)
+			}
+		}
+	case Down:
+		for _, migration := range slices.Backward(all) {
+			if migration.Version <= current {
+				plan = append(plan, migration)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown direction %q", direction)
+	}
+	if steps > 0 && steps < len(plan) {
+		plan = plan[:steps]
+	}
+	return plan, nil
+}
+
+func (mg *migrator) Close() error {
+	sourceErr, databaseErr := mg.m.Close()
+	return errors.Join(sourceErr, databaseErr)
+}
+
+func (mg *migrator) find(version uint) (Migration, error) {
+	all, err := Embedded(Up)
+	if err != nil {
+		return Migration{}, err
+	}
+	for _, migration := range all {
+		if migration.Version == version {
+			return migration, nil
+		}
+	}
+	return Migration{}, fmt.Errorf("migration %d does not exist", version)
+}
+
+// Embedded returns the migrations embedded in the binary in ascending version order,
+// with the SQL of the given direction
+func Embedded(direction Direction) ([]Migration, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	read := src.ReadUp
+	if direction == Down {
+		read = src.ReadDown
+	}
+
+	var all []Migration
+	version, err := src.First()
+	for err == nil {
+		migration := Migration{Version: version, Direction: direction}
+		body, identifier, readErr := read(version)
+		switch {
+		case readErr == nil:
+			content, readErr := io.ReadAll(body)
+			body.Close()
+			if readErr != nil {
+				return nil, readErr
+			}
+			migration.Identifier = identifier
+			migration.SQL = string(content)
+		case !errors.Is(readErr, fs.ErrNotExist):
+			return nil, readErr
+		}
+		all = append(all, migration)
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return all, nil
+}
+
+// LatestVersion returns the version of the last embedded migration
+func LatestVersion() (uint, error) {
+	all, err := Embedded(Up)
+	if err != nil {
+		return 0, err
+	}
+	if len(all) == 0 {
+		return 0, errors.New("no migration files found")
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// Create writes an empty up and down migration named after name into dir, numbered after the
+// last migration of dir, and returns their paths
+func Create(dir string, name string) ([]string, error) {
+	name = strcase.ToSnake(strings.TrimSpace(name))
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var latest uint
+	for _, entry := range entries {
+		if migration, err := source.DefaultParse(entry.Name()); err == nil {
+			latest = max(latest, migration.Version)
+		}
+	}
+
+	var paths []string
+	for _, direction := range []Direction{Up, Down} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", latest+1, name, direction))
+		content := fmt.Sprintf("-- %s migration of %s\n", strings.ToUpper(string(direction)), name)
+		// O_EXCL never overwrites an existing migration
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return paths, err
+		}
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// migrateLogger adapts Logger to golang-migrate
+type migrateLogger struct {
+	Logger
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
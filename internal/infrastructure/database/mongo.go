@@ -1 +1,51 @@
 package database
+
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+)
+
+// MongoDB stores the append-only, high-volume records, i.e. the audit events and the request logs.
+// Its Database is nil when MONGO_DSN is not configured, those records are then stored in Postgres.
+type MongoDB struct {
+	*mongo.Database
+}
+
+// IsConfigured reports whether MONGO_DSN is configured
+func (m MongoDB) IsConfigured() bool {
+	return m.Database != nil
+}
+
+func NewMongoDBFromConfig(lc fx.Lifecycle, cfg *config.Config) (MongoDB, error) {
+	if cfg.MongoDSN == "" {
+		return MongoDB{}, nil
+	}
+
+	clientOptions := options.Client().
+		ApplyURI(cfg.MongoDSN).
+		SetConnectTimeout(cfg.MongoConnectTimeout).
+		SetServerSelectionTimeout(cfg.MongoConnectTimeout)
+	client, err := mongo.Connect(context.Background(), clientOptions)
+	if err != nil {
+		return MongoDB{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MongoConnectTimeout)
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return MongoDB{}, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return client.Disconnect(ctx)
+		},
+	})
+
+	return MongoDB{Database: client.Database(cfg.MongoDatabase)}, nil
+}
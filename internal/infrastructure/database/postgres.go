@@ -1,17 +1,49 @@
 package database
 
 import (
+	"database/sql"
+
 	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/infrastructure/tracing"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-func NewPostgresGormDBFromConfig(cfg *config.Config) (*gorm.DB, error) {
-	gormCfg := &gorm.Config{}
+func NewPostgresGormDBFromConfig(cfg *config.Config, tp trace.TracerProvider, l *logger.Logger) (*gorm.DB, error) {
+	gormCfg := &gorm.Config{
+		Logger:      NewZapGormLogger(l, cfg.DBLogLevel, cfg.DBSlowQueryThreshold),
+		PrepareStmt: cfg.DBPrepareStmt,
+	}
 	db, err := gorm.Open(postgres.Open(cfg.PostgresDSN), gormCfg)
 	if err != nil {
 		return nil, err
 	}
 
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	configurePool(sqlDB, cfg)
+
+	if err := tracing.RegisterGormCallbacks(db, tp); err != nil {
+		return nil, err
+	}
+	if err := registerStatementTimeoutCallbacks(db, cfg.DBStatementTimeout); err != nil {
+		return nil, err
+	}
+	if err := registerRoleGrantScopeCallback(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
+
+// configurePool applies the DB_* connection pool settings to sqlDB
+func configurePool(sqlDB *sql.DB, cfg *config.Config) {
+	sqlDB.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+}
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// replicaPingTimeout bounds the connection check of the read replica on start
+const replicaPingTimeout = 10 * time.Second
+
+// ReadDB serves the reads that tolerate replication lag, i.e. the Get and Paginate repository methods.
+// It is a session of the primary database, sharing its logger, callbacks and statement timeout, whose
+// statements run on the read replica of POSTGRES_READ_DSN, or on the primary when none is configured.
+// Reads followed by a write, such as First, must keep using the primary.
+type ReadDB struct {
+	*gorm.DB
+}
+
+func NewPostgresReadDBFromConfig(lc fx.Lifecycle, cfg *config.Config, db *gorm.DB) (ReadDB, error) {
+	if cfg.PostgresReadDSN == "" {
+		return ReadDB{DB: db}, nil
+	}
+
+	replica, err := sql.Open("pgx", cfg.PostgresReadDSN)
+	if err != nil {
+		return ReadDB{}, err
+	}
+	configurePool(replica, cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+	if err := replica.PingContext(ctx); err != nil {
+		replica.Close()
+		return ReadDB{}, err
+	}
+
+	var connPool gorm.ConnPool = replica
+	var preparedStmt *gorm.PreparedStmtDB
+	if cfg.DBPrepareStmt {
+		preparedStmt = gorm.NewPreparedStmtDB(replica, db.PrepareStmtMaxSize, db.PrepareStmtTTL)
+		connPool = preparedStmt
+	}
+
+	// A session given a context gets its own statement, whose connection pool can be swapped
+	// without affecting the primary
+	readDB := db.Session(&gorm.Session{NewDB: true, Context: context.Background()})
+	readDB.Statement.ConnPool = connPool
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			if preparedStmt != nil {
+				preparedStmt.Close()
+			}
+			return replica.Close()
+		},
+	})
+
+	return ReadDB{DB: readDB}, nil
+}
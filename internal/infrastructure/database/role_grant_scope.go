@@ -0,0 +1,25 @@
+package database
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// registerRoleGrantScopeCallback limits the queries of role_user to the grants in effect, so that a user
+// holds a temporary role only from its start to its expiry whatever reads the roles, the preloads of
+// User.Roles and Role.Users included. helper.GormDBAllRoleGrants lifts the limit.
+func registerRoleGrantScopeCallback(db *gorm.DB) error {
+	roleUserTableName := entity.RoleUser{}.TableName()
+
+	return db.Callback().Query().Before("gorm:query").Register("database:role_grant_scope", func(db *gorm.DB) {
+		if db.Statement.Table != roleUserTableName {
+			return
+		}
+		if all, ok := db.Get(helper.GormDBAllRoleGrantsKey); ok && all.(bool) {
+			return
+		}
+		db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: helper.RoleUserInEffectCondition}}})
+	})
+}
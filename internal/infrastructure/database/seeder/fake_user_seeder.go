@@ -0,0 +1,116 @@
+package seeder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
+	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	factoryGo "github.com/bluele/factory-go/factory"
+	"github.com/samber/lo"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+var _ Seeder = (*FakeUserSeeder)(nil)
+
+// fakeUserBatchSize is the number of fake users inserted at once
+const fakeUserBatchSize = 500
+
+// FakeUserSeeder generates Options.Count users with the default role, e.g. for load testing
+type FakeUserSeeder struct {
+	db              *gorm.DB
+	userFactory     *factoryGo.Factory
+	employeeFactory *factoryGo.Factory
+	roleUserFactory *factoryGo.Factory
+	roleRepository  roleRepository.RoleRepository
+}
+
+type NewFakeUserSeederParams struct {
+	fx.In
+
+	DB              *gorm.DB
+	UserFactory     *factoryGo.Factory `name:"user_factory"`
+	EmployeeFactory *factoryGo.Factory `name:"employee_factory"`
+	RoleUserFactory *factoryGo.Factory `name:"role_user_factory"`
+	RoleRepository  roleRepository.RoleRepository
+}
+
+func NewFakeUserSeeder(params NewFakeUserSeederParams) Seeder {
+	return &FakeUserSeeder{
+		db:              params.DB,
+		userFactory:     params.UserFactory,
+		employeeFactory: params.EmployeeFactory,
+		roleUserFactory: params.RoleUserFactory,
+		roleRepository:  params.RoleRepository,
+	}
+}
+
+func (s *FakeUserSeeder) Name() string {
+	return "fake_user"
+}
+
+func (s *FakeUserSeeder) Dependencies() []string {
+	return []string{"role"}
+}
+
+// Seed inserts Options.Count new users, it does nothing when the count is zero
+func (s *FakeUserSeeder) Seed(ctx context.Context, options Options) error {
+	if options.Count <= 0 {
+		return nil
+	}
+
+	defaultRole, err := s.roleRepository.FindDefault(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Hashing is slow on purpose, every fake user shares the password hashed once
+	password := s.userFactory.MustCreate().(*entity.User).Password
+
+	for offset := 0; offset < options.Count; offset += fakeUserBatchSize {
+		users := make([]*entity.User, 0, min(fakeUserBatchSize, options.Count-offset))
+		for range cap(users) {
+			user := s.userFactory.MustCreateWithOption(map[string]any{
+				"Password": password,
+			}).(*entity.User)
+			// The id keeps the email unique however many users are generated
+			user.Email = fmt.Sprintf("%s.%s@example.com", strings.Split(user.Email, "@")[0], strings.ToLower(user.Id))
+			users = append(users, user)
+		}
+
+		// A user whose random phone number is taken is skipped
+		if _, err := helper.GormDBCreateManyIgnoringConflicts(s.db, users); err != nil {
+			return err
+		}
+		var storedIds []string
+		if err := s.db.Model(&entity.User{}).
+			Where("id IN ?", lo.Map(users, func(user *entity.User, _ int) string { return user.Id })).
+			Pluck("id", &storedIds).Error; err != nil {
+			return err
+		}
+
+		var employees []*entity.Employee
+		var roleUsers []*entity.RoleUser
+		for _, userId := range storedIds {
+			employees = append(employees, s.employeeFactory.MustCreateWithOption(map[string]any{
+				"UserId": userId,
+			}).(*entity.Employee))
+			roleUsers = append(roleUsers, s.roleUserFactory.MustCreateWithOption(map[string]any{
+				"RoleId": defaultRole.Id,
+				"UserId": userId,
+			}).(*entity.RoleUser))
+		}
+
+		if _, err := helper.GormDBCreateManyIgnoringConflicts(s.db, employees); err != nil {
+			return err
+		}
+		if _, err := helper.GormDBCreateManyIgnoringConflicts(s.db, roleUsers, "role_id", "user_id"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
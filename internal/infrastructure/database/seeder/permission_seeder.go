@@ -1,51 +1,43 @@
 package seeder
 
 import (
-	"github.com/arfanxn/welding/internal/infrastructure/id"
-	"github.com/arfanxn/welding/internal/module/permission/domain/enum"
-	"github.com/arfanxn/welding/internal/module/permission/domain/repository"
-	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"context"
+	"github.com/arfanxn/welding/internal/module/permission/usecase"
+	"github.com/arfanxn/welding/internal/module/permission/usecase/dto"
 	"go.uber.org/fx"
 )
 
 var _ Seeder = (*PermissionSeeder)(nil)
 
 type PermissionSeeder struct {
-	idService            id.IdService
-	permissionRepository repository.PermissionRepository
+	permissionUsecase usecase.PermissionUsecase
 }
 
 type NewPermissionSeederParams struct {
 	fx.In
 
-	IdService            id.IdService
-	PermissionRepository repository.PermissionRepository
+	PermissionUsecase usecase.PermissionUsecase
 }
 
 func NewPermissionSeeder(
 	params NewPermissionSeederParams,
 ) Seeder {
 	return &PermissionSeeder{
-		idService:            params.IdService,
-		permissionRepository: params.PermissionRepository,
+		permissionUsecase: params.PermissionUsecase,
 	}
 }
 
-func (s *PermissionSeeder) Seed() error {
-	permissionNames := enum.PermissionNames
-	var permissions []*entity.Permission
-
-	for _, permissionName := range permissionNames {
-		permissions = append(permissions, &entity.Permission{
-			Id:   s.idService.Generate(),
-			Name: permissionName,
-		})
-	}
-
-	err := s.permissionRepository.SaveMany(permissions)
-	if err != nil {
-		return err
-	}
+func (s *PermissionSeeder) Name() string {
+	return "permission"
+}
 
+func (s *PermissionSeeder) Dependencies() []string {
 	return nil
 }
+
+// Seed synchronizes the permissions of enum.PermissionNames to the database, like permissions:sync
+// without pruning
+func (s *PermissionSeeder) Seed(ctx context.Context, options Options) error {
+	_, err := s.permissionUsecase.Sync(ctx, &dto.SyncPermissions{})
+	return err
+}
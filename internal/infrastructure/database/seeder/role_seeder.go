@@ -1,52 +1,63 @@
 package seeder
 
 import (
+	"context"
+	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	"github.com/arfanxn/welding/internal/infrastructure/id"
 	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	permissionRepository "github.com/arfanxn/welding/internal/module/permission/domain/repository"
-	permissionRoleRepository "github.com/arfanxn/welding/internal/module/permission_role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/bluele/factory-go/factory"
 	"github.com/samber/lo"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 )
 
 var _ Seeder = (*RoleSeeder)(nil)
 
 type RoleSeeder struct {
-	idService                id.IdService
-	roleFactory              *factory.Factory
-	permissionRoleFactory    *factory.Factory
-	roleRepository           repository.RoleRepository
-	permissionRepository     permissionRepository.PermissionRepository
-	permissionRoleRepository permissionRoleRepository.PermissionRoleRepository
+	db                    *gorm.DB
+	idService             id.IdService
+	roleFactory           *factory.Factory
+	permissionRoleFactory *factory.Factory
+	roleRepository        repository.RoleRepository
+	permissionRepository  permissionRepository.PermissionRepository
 }
 
 type NewRoleSeederParams struct {
 	fx.In
 
-	IdService                id.IdService
-	RoleFactory              *factory.Factory `name:"role_factory"`
-	PermissionRoleFactory    *factory.Factory `name:"permission_role_factory"`
-	RoleRepository           repository.RoleRepository
-	PermissionRepository     permissionRepository.PermissionRepository
-	PermissionRoleRepository permissionRoleRepository.PermissionRoleRepository
+	DB                    *gorm.DB
+	IdService             id.IdService
+	RoleFactory           *factory.Factory `name:"role_factory"`
+	PermissionRoleFactory *factory.Factory `name:"permission_role_factory"`
+	RoleRepository        repository.RoleRepository
+	PermissionRepository  permissionRepository.PermissionRepository
 }
 
 func NewRoleSeeder(params NewRoleSeederParams) Seeder {
 	return &RoleSeeder{
-		idService:                params.IdService,
-		roleFactory:              params.RoleFactory,
-		permissionRoleFactory:    params.PermissionRoleFactory,
-		roleRepository:           params.RoleRepository,
-		permissionRepository:     params.PermissionRepository,
-		permissionRoleRepository: params.PermissionRoleRepository,
+		db:                    params.DB,
+		idService:             params.IdService,
+		roleFactory:           params.RoleFactory,
+		permissionRoleFactory: params.PermissionRoleFactory,
+		roleRepository:        params.RoleRepository,
+		permissionRepository:  params.PermissionRepository,
 	}
 }
 
-func (s *RoleSeeder) Seed() error {
+func (s *RoleSeeder) Name() string {
+	return "role"
+}
+
+func (s *RoleSeeder) Dependencies() []string {
+	return []string{"permission"}
+}
+
+// Seed inserts the roles of enum.RoleNames missing from the database and grants them their permissions
+func (s *RoleSeeder) Seed(ctx context.Context, options Options) error {
 	roleFactory := s.roleFactory
 	permissionRoleFactory := s.permissionRoleFactory
 
@@ -105,17 +116,52 @@ func (s *RoleSeeder) Seed() error {
 		customerRole,
 	}
 
-	// Save all roles to the database
-	err := s.roleRepository.SaveMany(roles)
+	// Each role of the hierarchy inherits the permissions of the role below it
+	parentNames := map[enum.RoleName]enum.RoleName{
+		enum.Head:       enum.Manager,
+		enum.Manager:    enum.Supervisor,
+		enum.Supervisor: enum.Engineer,
+		enum.Engineer:   enum.Staff,
+		enum.Staff:      enum.Operator,
+	}
+
+	// The roles seeded by an earlier run, which are left untouched
+	existingRoles, err := s.roleRepository.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Save the roles missing from the database, existing roles are left untouched
+	_, err = helper.GormDBCreateManyIgnoringConflicts(s.db, roles, "name")
 	if err != nil {
 		return err
 	}
 
+	// Reload the roles as stored, a role seeded by an earlier run keeps its own id
+	storedRoles, err := s.roleRepository.All(ctx)
+	if err != nil {
+		return err
+	}
+	roles = lo.Filter(storedRoles, func(storedRole *entity.Role, _ int) bool {
+		return lo.ContainsBy(roles, func(role *entity.Role) bool { return role.Name == storedRole.Name })
+	})
+
 	// Create a map of role names to role objects for easy lookup
 	roleMap := make(map[enum.RoleName]*entity.Role)
 	for _, role := range roles {
 		roleMap[role.Name] = role
 	}
+	superAdminRole = roleMap[enum.SuperAdmin]
+
+	// Give the roles created by this run their parent in the hierarchy
+	for name, parentName := range parentNames {
+		if lo.ContainsBy(existingRoles, func(role *entity.Role) bool { return role.Name == name }) {
+			continue
+		}
+		if err := s.db.Model(roleMap[name]).Update("parent_id", roleMap[parentName].Id).Error; err != nil {
+			return err
+		}
+	}
 
 	{
 		// ========== PermissionRole ==========
@@ -123,7 +169,7 @@ func (s *RoleSeeder) Seed() error {
 		// Each role is granted specific permissions based on their access level.
 
 		// Fetch all available permissions from the database
-		permissions, err := s.permissionRepository.All()
+		permissions, err := s.permissionRepository.All(ctx)
 		if err != nil {
 			return err
 		}
@@ -131,11 +177,13 @@ func (s *RoleSeeder) Seed() error {
 		// Initialize slice to store all permission-role relationships that will be created
 		var permissionRoles []*entity.PermissionRole
 
-		// Super admin gets all permissions without any restrictions
-		superAdminPermissions := permissions
+		// Super admin gets the * wildcard, which grants every permission without any restrictions
+		superAdminPermissions := lo.Filter(permissions, func(permission *entity.Permission, _ int) bool {
+			return permissionEnum.PermissionName(permission.Name) == permissionEnum.All
+		})
 
-		// Assign all available permissions to super admin role
-		// This ensures super admins have full access to all features
+		// Assign the wildcard to super admin role
+		// This ensures super admins have full access to all features, including those added later
 		for _, permission := range superAdminPermissions {
 			permissionRoles = append(permissionRoles, permissionRoleFactory.MustCreateWithOption(map[string]any{
 				"RoleId":       superAdminRole.Id,
@@ -173,9 +221,9 @@ func (s *RoleSeeder) Seed() error {
 			}
 		}
 
-		// Save all permission-role relationships to the database in a single batch
-		// This is more efficient than saving each relationship individually
-		err = s.permissionRoleRepository.SaveMany(permissionRoles)
+		// Save the missing permission-role relationships to the database in batches
+		// Permissions added since the last run are granted, existing grants are skipped
+		_, err = helper.GormDBCreateManyIgnoringConflicts(s.db, permissionRoles, "permission_id", "role_id")
 		if err != nil {
 			return err
 		}
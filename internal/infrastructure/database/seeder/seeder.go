@@ -1,5 +1,138 @@
 package seeder
 
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Environment tells which records a seed run creates
+type Environment string
+
+const (
+	// Development seeds every record, including sample users
+	Development Environment = "development"
+	// Production seeds only the permissions, the roles and the super admin
+	Production Environment = "production"
+)
+
+var Environments = []Environment{Development, Production}
+
+// Options configures a seed run
+type Options struct {
+	Environment Environment
+	// Count is the number of fake users generated by the fake user seeder
+	Count int
+	// SuperAdminEmail and SuperAdminPassword are the credentials of the super admin, required in production
+	SuperAdminEmail    string
+	SuperAdminPassword string
+}
+
+// Seeder inserts one kind of record. Seeding inserts only the records missing from the
+// database, so seeders can run again on an already seeded database.
 type Seeder interface {
-	Seed() error
+	// Name identifies the seeder in seed --only and in Dependencies, e.g. "permission"
+	Name() string
+	// Dependencies lists the names of the seeders whose records must exist before this one runs
+	Dependencies() []string
+	Seed(ctx context.Context, options Options) error
+}
+
+// Order sorts seeders so that every seeder comes after its dependencies, seeders without
+// a dependency between them are kept sorted by name
+//
+// Parameters:
+//   - seeders: Seeders to sort
+//
+// Returns:
+//   - []Seeder: The sorted seeders
+//   - error: When a dependency is unknown or the dependencies form a cycle
+func Order(seeders []Seeder) ([]Seeder, error) {
+	byName := make(map[string]Seeder, len(seeders))
+	for _, s := range seeders {
+		if _, ok := byName[s.Name()]; ok {
+			return nil, fmt.Errorf("seeder %q is registered twice", s.Name())
+		}
+		byName[s.Name()] = s
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := make(map[string]int, len(names))
+	ordered := make([]Seeder, 0, len(names))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch states[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("seeder dependency cycle: %s", strings.Join(slices.Concat(path, []string{name}), " -> "))
+		}
+
+		states[name] = visiting
+		dependencies := slices.Sorted(slices.Values(byName[name].Dependencies()))
+		for _, dependency := range dependencies {
+			if _, ok := byName[dependency]; !ok {
+				return fmt.Errorf("seeder %q depends on unknown seeder %q", name, dependency)
+			}
+			if err := visit(dependency, slices.Concat(path, []string{name})); err != nil {
+				return err
+			}
+		}
+		states[name] = visited
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Select keeps the seeders named by names, in the order of seeders. Their dependencies are not
+// added, they are expected to have been seeded already.
+//
+// Parameters:
+//   - seeders: Seeders to select from
+//   - names: Names of the seeders to keep, every seeder when empty
+//
+// Returns:
+//   - []Seeder: The selected seeders
+//   - error: When a name matches no seeder
+func Select(seeders []Seeder, names []string) ([]Seeder, error) {
+	if len(names) == 0 {
+		return seeders, nil
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(seeders, func(s Seeder) bool { return s.Name() == name }) {
+			return nil, fmt.Errorf("unknown seeder %q, available seeders are %s", name, strings.Join(Names(seeders), ", "))
+		}
+	}
+	return slices.DeleteFunc(slices.Clone(seeders), func(s Seeder) bool {
+		return !slices.Contains(names, s.Name())
+	}), nil
+}
+
+// Names returns the names of seeders
+func Names(seeders []Seeder) []string {
+	names := make([]string, 0, len(seeders))
+	for _, s := range seeders {
+		names = append(names, s.Name())
+	}
+	return names
 }
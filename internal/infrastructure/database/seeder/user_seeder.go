@@ -1,62 +1,80 @@
 package seeder
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	"github.com/arfanxn/welding/internal/infrastructure/id"
-	employeeRepository "github.com/arfanxn/welding/internal/module/employee/domain/repository"
+	"github.com/arfanxn/welding/internal/infrastructure/security"
 	"github.com/arfanxn/welding/internal/module/role/domain/enum"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
-	roleUserRepository "github.com/arfanxn/welding/internal/module/role_user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/user/domain/repository"
 	factoryGo "github.com/bluele/factory-go/factory"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/guregu/null/v6"
 	"github.com/iancoleman/strcase"
 	"go.uber.org/fx"
+	"gorm.io/gorm"
 )
 
 var _ Seeder = (*UserSeeder)(nil)
 
 type UserSeeder struct {
-	idService          id.IdService
-	userFactory        *factoryGo.Factory
-	employeeFactory    *factoryGo.Factory
-	roleUserFactory    *factoryGo.Factory
-	userRepository     repository.UserRepository
-	employeeRepository employeeRepository.EmployeeRepository
-	roleRepository     roleRepository.RoleRepository
-	roleUserRepository roleUserRepository.RoleUserRepository
+	db              *gorm.DB
+	idService       id.IdService
+	userFactory     *factoryGo.Factory
+	employeeFactory *factoryGo.Factory
+	roleUserFactory *factoryGo.Factory
+	userRepository  repository.UserRepository
+	roleRepository  roleRepository.RoleRepository
+	passwordService security.PasswordService
 }
 
 type NewUserSeederParams struct {
 	fx.In
 
-	IdService          id.IdService
-	UserFactory        *factoryGo.Factory `name:"user_factory"`
-	EmployeeFactory    *factoryGo.Factory `name:"employee_factory"`
-	RoleUserFactory    *factoryGo.Factory `name:"role_user_factory"`
-	UserRepository     repository.UserRepository
-	EmployeeRepository employeeRepository.EmployeeRepository
-	RoleRepository     roleRepository.RoleRepository
-	RoleUserRepository roleUserRepository.RoleUserRepository
+	DB              *gorm.DB
+	IdService       id.IdService
+	UserFactory     *factoryGo.Factory `name:"user_factory"`
+	EmployeeFactory *factoryGo.Factory `name:"employee_factory"`
+	RoleUserFactory *factoryGo.Factory `name:"role_user_factory"`
+	UserRepository  repository.UserRepository
+	RoleRepository  roleRepository.RoleRepository
+	PasswordService security.PasswordService
 }
 
 func NewUserSeeder(params NewUserSeederParams) Seeder {
 	return &UserSeeder{
-		idService:          params.IdService,
-		userFactory:        params.UserFactory,
-		employeeFactory:    params.EmployeeFactory,
-		roleUserFactory:    params.RoleUserFactory,
-		userRepository:     params.UserRepository,
-		employeeRepository: params.EmployeeRepository,
-		roleRepository:     params.RoleRepository,
-		roleUserRepository: params.RoleUserRepository,
+		db:              params.DB,
+		idService:       params.IdService,
+		userFactory:     params.UserFactory,
+		employeeFactory: params.EmployeeFactory,
+		roleUserFactory: params.RoleUserFactory,
+		userRepository:  params.UserRepository,
+		roleRepository:  params.RoleRepository,
+		passwordService: params.PasswordService,
 	}
 }
 
-func (s *UserSeeder) Seed() error {
+func (s *UserSeeder) Name() string {
+	return "user"
+}
+
+func (s *UserSeeder) Dependencies() []string {
+	return []string{"role"}
+}
+
+// Seed inserts the sample staff users missing from the database, only the super admin in production.
+// The super admin is given the credentials of options, which production requires, the factory password
+// being public.
+func (s *UserSeeder) Seed(ctx context.Context, options Options) error {
+	if err := validateSuperAdminCredentials(options); err != nil {
+		return err
+	}
+
 	var err error
 	userFactory := s.userFactory
 	employeeFactory := s.employeeFactory
@@ -88,16 +106,38 @@ func (s *UserSeeder) Seed() error {
 		head,
 		customerServiceAdmin,
 	}
+	if options.Environment == Production {
+		users = []*entity.User{superAdmin}
+	}
 
 	for _, user := range users {
 		user.Email = fmt.Sprintf("%s@gmail.com", strcase.ToSnake(user.Name))
 	}
+	if options.SuperAdminEmail != "" {
+		superAdmin.Email = options.SuperAdminEmail
+	}
+	if options.SuperAdminPassword != "" {
+		superAdmin.Password, err = s.passwordService.Hash(options.SuperAdminPassword)
+		if err != nil {
+			return err
+		}
+	}
 
-	err = s.userRepository.SaveMany(users)
+	// Save the users missing from the database, existing users keep their password and profile
+	_, err = helper.GormDBCreateManyIgnoringConflicts(s.db, users, "email")
 	if err != nil {
 		return err
 	}
 
+	// Reload the users as stored, a user seeded by an earlier run keeps its own id
+	for i, user := range users {
+		storedUser, err := s.userRepository.FindByEmail(ctx, user.Email)
+		if err != nil {
+			return err
+		}
+		users[i] = storedUser
+	}
+
 	{
 		// ========== Employee ==========
 		employees := []*entity.Employee{}
@@ -108,7 +148,8 @@ func (s *UserSeeder) Seed() error {
 			employees = append(employees, employee)
 		}
 
-		err = s.employeeRepository.SaveMany(employees)
+		// Any conflict means the user already has an employee record
+		_, err = helper.GormDBCreateManyIgnoringConflicts(s.db, employees)
 		if err != nil {
 			return err
 		}
@@ -123,7 +164,7 @@ func (s *UserSeeder) Seed() error {
 		// This ensures proper role-based access control in the application.
 
 		// Fetch all available roles from the database
-		roles, err := s.roleRepository.All()
+		roles, err := s.roleRepository.All(ctx)
 		if err != nil {
 			return err
 		}
@@ -134,34 +175,29 @@ func (s *UserSeeder) Seed() error {
 			roleMap[role.Name] = role
 		}
 
+		// Each seeded user is given the role it is named after
+		userRoleNames := map[string]enum.RoleName{
+			superAdmin.Email:           enum.SuperAdmin,
+			admin.Email:                enum.Admin,
+			head.Email:                 enum.Head,
+			customerServiceAdmin.Email: enum.CustomerServiceAdmin,
+		}
+
 		// Create role-user relationships for each user with their respective roles
-		roleUsers := []*entity.RoleUser{
-			// Super Admin role assignment
-			roleUserFactory.MustCreateWithOption(map[string]any{
-				"RoleId": roleMap[enum.SuperAdmin].Id,
-				"UserId": superAdmin.Id,
-			}).(*entity.RoleUser),
-			// Admin role assignment
-			roleUserFactory.MustCreateWithOption(map[string]any{
-				"RoleId": roleMap[enum.Admin].Id,
-				"UserId": admin.Id,
-			}).(*entity.RoleUser),
-
-			// Head role assignment
-			roleUserFactory.MustCreateWithOption(map[string]any{
-				"RoleId": roleMap[enum.Head].Id,
-				"UserId": head.Id,
-			}).(*entity.RoleUser),
-
-			// Customer Service Admin role assignment
-			roleUserFactory.MustCreateWithOption(map[string]any{
-				"RoleId": roleMap[enum.CustomerServiceAdmin].Id,
-				"UserId": customerServiceAdmin.Id,
-			}).(*entity.RoleUser),
+		var roleUsers []*entity.RoleUser
+		for _, user := range users {
+			role, ok := roleMap[userRoleNames[user.Email]]
+			if !ok {
+				return fmt.Errorf("role %s is not seeded", userRoleNames[user.Email])
+			}
+			roleUsers = append(roleUsers, roleUserFactory.MustCreateWithOption(map[string]any{
+				"RoleId": role.Id,
+				"UserId": user.Id,
+			}).(*entity.RoleUser))
 		}
 
-		// Save all role-user relationships to the database
-		err = s.roleUserRepository.SaveMany(roleUsers)
+		// Save the missing role-user relationships to the database
+		_, err = helper.GormDBCreateManyIgnoringConflicts(s.db, roleUsers, "role_id", "user_id")
 		if err != nil {
 			return err
 		}
@@ -170,3 +206,19 @@ func (s *UserSeeder) Seed() error {
 
 	return nil
 }
+
+// validateSuperAdminCredentials checks the credentials given to the super admin, required in production
+func validateSuperAdminCredentials(options Options) error {
+	if options.Environment == Production && (options.SuperAdminEmail == "" || options.SuperAdminPassword == "") {
+		return fmt.Errorf("the super admin email and password are required in production")
+	}
+	if options.SuperAdminEmail != "" {
+		if err := is.EmailFormat.Validate(options.SuperAdminEmail); err != nil {
+			return fmt.Errorf("invalid super admin email %q", options.SuperAdminEmail)
+		}
+	}
+	if options.SuperAdminPassword != "" && len(options.SuperAdminPassword) < 8 {
+		return fmt.Errorf("the super admin password must be at least 8 characters long")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStatementTimeoutKey = "database:statement_timeout"
+
+// statementTimeout holds the context a statement ran with before its timeout was applied
+type statementTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerStatementTimeoutCallbacks cancels every GORM statement running longer than timeout.
+// The timeout is derived from the statement context (see gorm.DB.WithContext), so a statement
+// also stops as soon as the request it serves is cancelled or reaches its own deadline.
+// Row statements are left out, their rows are read after the callbacks returned.
+func registerStatementTimeoutCallbacks(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+
+	before := func(db *gorm.DB) {
+		parent := db.Statement.Context
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormStatementTimeoutKey, statementTimeout{parent: parent, cancel: cancel})
+	}
+
+	after := func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStatementTimeoutKey)
+		if !ok {
+			return
+		}
+		timeout, ok := value.(statementTimeout)
+		if !ok {
+			return
+		}
+		timeout.cancel()
+		// The statement may be reused by the next call of a chain, e.g. Count then Find
+		db.Statement.Context = timeout.parent
+	}
+
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		// Writes run in a transaction which is rolled back once the context of its BEGIN is cancelled,
+		// so the timeout spans the whole transaction and ends after its commit
+		{"create", callback.Create().Before("gorm:begin_transaction").Register, callback.Create().After("gorm:commit_or_rollback_transaction").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:begin_transaction").Register, callback.Update().After("gorm:commit_or_rollback_transaction").Register},
+		{"delete", callback.Delete().Before("gorm:begin_transaction").Register, callback.Delete().After("gorm:commit_or_rollback_transaction").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range registrations {
+		if err := r.before("database:before_"+r.operation, before); err != nil {
+			return err
+		}
+		if err := r.after("database:after_"+r.operation, after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,19 +1,31 @@
 package di
 
 import (
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
 	"github.com/arfanxn/welding/internal/infrastructure/config"
 	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/infrastructure/event"
+	"github.com/arfanxn/welding/internal/infrastructure/health"
 	"github.com/arfanxn/welding/internal/infrastructure/http"
 	"github.com/arfanxn/welding/internal/infrastructure/http/jwt"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
 	"github.com/arfanxn/welding/internal/infrastructure/id"
+	"github.com/arfanxn/welding/internal/infrastructure/idempotency"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	loggerHttp "github.com/arfanxn/welding/internal/infrastructure/logger/presentation/http"
 	"github.com/arfanxn/welding/internal/infrastructure/mail"
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
 	"github.com/arfanxn/welding/internal/infrastructure/middleware"
 	"github.com/arfanxn/welding/internal/infrastructure/security"
+	"github.com/arfanxn/welding/internal/infrastructure/tracing"
+	auditLogDi "github.com/arfanxn/welding/internal/module/audit_log/infrastructure/di"
 	codeDi "github.com/arfanxn/welding/internal/module/code/infrastructure/di"
 	employeeDi "github.com/arfanxn/welding/internal/module/employee/infrastructure/di"
 	permissionDi "github.com/arfanxn/welding/internal/module/permission/infrastructure/di"
 	permissionRoleDi "github.com/arfanxn/welding/internal/module/permission_role/infrastructure/di"
+	requestLogDi "github.com/arfanxn/welding/internal/module/request_log/infrastructure/di"
 	roleDi "github.com/arfanxn/welding/internal/module/role/infrastructure/di"
 	roleUserDi "github.com/arfanxn/welding/internal/module/role_user/infrastructure/di"
 	userDi "github.com/arfanxn/welding/internal/module/user/infrastructure/di"
@@ -29,21 +41,51 @@ var Module = fx.Module("infrastructure",
 		// Core
 		config.NewConfigFromEnv,
 		database.NewPostgresGormDBFromConfig,
+		database.NewPostgresReadDBFromConfig,
+		database.NewMongoDBFromConfig,
 		logger.NewLoggerFromConfig,
+		loggerHttp.NewLogLevelHandler,
+		i18n.NewTranslatorFromConfig,
+		tracing.NewTracerProviderFromConfig,
 		mail.NewSmtpMailServiceFromConfig,
 		jwt.NewJWTServiceFromConfig,
 		security.NewBcryptPasswordService,
 		id.NewULIDIdService,
+		event.NewInProcessEventBus,
+		authz.NewPolicyEngine,
+		idempotency.NewGormStore,
+		metrics.NewPrometheusMetricsServiceFromConfig,
 		http.NewRouterFromConfig,
 		func(engine *gin.Engine) gin.IRouter { return engine },
+		openapi.NewRouteRequirements,
+		http.NewOpenAPIGenerator,
+		openapi.NewOpenAPIHandler,
+
+		// Health
+		health.NewHealthServiceFromConfig,
+		health.NewHealthHandler,
+		fx.Annotate(health.NewPostgresChecker, fx.As(new(health.Checker)), fx.ResultTags(`group:"health_checkers"`)),
+		fx.Annotate(health.NewMongoChecker, fx.As(new(health.Checker)), fx.ResultTags(`group:"health_checkers"`)),
+		fx.Annotate(health.NewMigrationChecker, fx.As(new(health.Checker)), fx.ResultTags(`group:"health_checkers"`)),
+		fx.Annotate(health.NewMailChecker, fx.As(new(health.Checker)), fx.ResultTags(`group:"health_checkers"`)),
 
 		// Middleware(s)
+		middleware.NewRequestIdMiddleware,
+		middleware.NewTrustedProxyMiddleware,
+		middleware.NewLocaleMiddleware,
+		middleware.NewSecurityHeadersMiddleware,
+		middleware.NewCORSMiddleware,
+		middleware.NewAccessLogMiddleware,
+		middleware.NewRequestLogMiddleware,
 		middleware.NewHttpErrorRecoveryMiddleware,
+		middleware.NewMetricsMiddleware,
 		middleware.NewRateLimiterMiddleware,
 		middleware.NewAuthenticateMiddleware,
 		middleware.NewAuthorizeMiddleware,
 		middleware.NewUserActiveMiddleware,
 		middleware.NewUserEmailVerifiedMiddleware,
+		middleware.NewIdempotencyMiddleware,
+		middleware.NewPreconditionMiddleware,
 	),
 
 	// Modules
@@ -54,6 +96,8 @@ var Module = fx.Module("infrastructure",
 	permissionRoleDi.Module,
 	employeeDi.Module,
 	codeDi.Module,
+	auditLogDi.Module,
+	requestLogDi.Module,
 
 	// Logger
 	fx.WithLogger(func(logger *logger.Logger) fxevent.Logger {
@@ -62,5 +106,7 @@ var Module = fx.Module("infrastructure",
 	}),
 
 	// Invoke
+	fx.Invoke(problem.CheckTranslations),
+	fx.Invoke(idempotency.RegisterCleanup),
 	fx.Invoke(http.RegisterRoutes),
 )
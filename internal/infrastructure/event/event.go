@@ -0,0 +1,62 @@
+// Package event provides an in-process bus of internal events, letting a module react to the
+// changes made by another one without depending on it.
+package event
+
+import (
+	"context"
+	"sync"
+
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+// Event is a change that already happened, identified by its name
+type Event interface {
+	EventName() string
+}
+
+// Handler reacts to an event. The change it reports is already made, so an error is only logged.
+type Handler func(ctx context.Context, event Event) error
+
+type EventBus interface {
+	// Subscribe registers handler to be called with every event named name
+	Subscribe(name string, handler Handler)
+	// Publish calls the handlers subscribed to the event in the order they subscribed, synchronously
+	// so the event is handled by the time the request that published it responds
+	Publish(ctx context.Context, event Event)
+}
+
+var _ EventBus = (*inProcessEventBus)(nil)
+
+type inProcessEventBus struct {
+	logger *logger.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewInProcessEventBus(logger *logger.Logger) EventBus {
+	return &inProcessEventBus{
+		logger:   logger,
+		handlers: map[string][]Handler{},
+	}
+}
+
+func (b *inProcessEventBus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *inProcessEventBus) Publish(ctx context.Context, event Event) {
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			b.logger.FromContext(ctx).Error("failed to handle the event", zap.Error(err), zap.String("event", event.EventName()))
+		}
+	}
+}
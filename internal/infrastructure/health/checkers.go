@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/infrastructure/database/migration"
+	"github.com/arfanxn/welding/internal/infrastructure/mail"
+	"gorm.io/gorm"
+)
+
+// ==================================================
+// Postgres
+// ==================================================
+
+var _ Checker = (*postgresChecker)(nil)
+
+type postgresChecker struct {
+	db *gorm.DB
+}
+
+func NewPostgresChecker(db *gorm.DB) Checker {
+	return &postgresChecker{db: db}
+}
+
+func (c *postgresChecker) Name() string {
+	return "postgres"
+}
+
+func (c *postgresChecker) Check(ctx context.Context) (map[string]any, error) {
+	sqlDB, err := c.db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return nil, err
+	}
+
+	stats := sqlDB.Stats()
+	return map[string]any{
+		"open_connections": stats.OpenConnections,
+		"in_use":           stats.InUse,
+		"idle":             stats.Idle,
+	}, nil
+}
+
+// ==================================================
+// Mongo
+// ==================================================
+
+var _ Checker = (*mongoChecker)(nil)
+
+type mongoChecker struct {
+	mongoDB database.MongoDB
+}
+
+func NewMongoChecker(mongoDB database.MongoDB) Checker {
+	return &mongoChecker{mongoDB: mongoDB}
+}
+
+func (c *mongoChecker) Name() string {
+	return "mongo"
+}
+
+// Check pings Mongo, it is reported as up without being pinged when MONGO_DSN is not configured
+func (c *mongoChecker) Check(ctx context.Context) (map[string]any, error) {
+	if !c.mongoDB.IsConfigured() {
+		return map[string]any{"configured": false}, nil
+	}
+
+	if err := c.mongoDB.Client().Ping(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"configured": true,
+		"database":   c.mongoDB.Name(),
+	}, nil
+}
+
+// ==================================================
+// Migration
+// ==================================================
+
+var _ Checker = (*migrationChecker)(nil)
+
+type migrationChecker struct {
+	db *gorm.DB
+}
+
+func NewMigrationChecker(db *gorm.DB) Checker {
+	return &migrationChecker{
+		db: db,
+	}
+}
+
+func (c *migrationChecker) Name() string {
+	return "migration"
+}
+
+// Check compares the version recorded by golang-migrate with the latest embedded migration.
+// A dirty or outdated schema is reported as unhealthy.
+func (c *migrationChecker) Check(ctx context.Context) (map[string]any, error) {
+	latestVersion, err := migration.LatestVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	var row struct {
+		Version uint
+		Dirty   bool
+	}
+	err = c.db.WithContext(ctx).
+		Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{
+		"version":        row.Version,
+		"latest_version": latestVersion,
+		"dirty":          row.Dirty,
+	}
+
+	if row.Dirty {
+		return details, errors.New("database schema is dirty")
+	}
+
+	if row.Version != latestVersion {
+		return details, fmt.Errorf("database schema version %d does not match latest version %d", row.Version, latestVersion)
+	}
+
+	return details, nil
+}
+
+// ==================================================
+// Mail
+// ==================================================
+
+var _ Checker = (*mailChecker)(nil)
+
+type mailChecker struct {
+	mailService mail.MailService
+}
+
+func NewMailChecker(mailService mail.MailService) Checker {
+	return &mailChecker{mailService: mailService}
+}
+
+func (c *mailChecker) Name() string {
+	return "mail"
+}
+
+func (c *mailChecker) Check(ctx context.Context) (map[string]any, error) {
+	return nil, c.mailService.Ping(ctx)
+}
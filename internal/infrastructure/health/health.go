@@ -0,0 +1,175 @@
+// Package health provides liveness and readiness reporting backed by a pluggable
+// registry of dependency checks (database, migrations, mail, ...).
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"go.uber.org/fx"
+)
+
+const (
+	// defaultCheckTimeout is used when HEALTH_CHECK_TIMEOUT is not configured
+	defaultCheckTimeout = 3 * time.Second
+	// defaultCacheTTL is used when HEALTH_CACHE_TTL is not configured
+	defaultCacheTTL = 5 * time.Second
+)
+
+// Status represents the state of a single check or of the whole report
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Checker is implemented by every dependency that takes part in the readiness report.
+// Check returns optional details for operators and a non-nil error when the dependency is unhealthy.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) (map[string]any, error)
+}
+
+// CheckResult is the outcome of a single Checker
+type CheckResult struct {
+	Name     string         `json:"name"`
+	Status   Status         `json:"status"`
+	Duration string         `json:"duration"`
+	Error    string         `json:"error,omitempty"`
+	Details  map[string]any `json:"details,omitempty"`
+}
+
+// Report is the aggregated outcome of all checks
+type Report struct {
+	Status    Status        `json:"status"`
+	Checks    []CheckResult `json:"checks,omitempty"`
+	CheckedAt time.Time     `json:"checked_at"`
+	Cached    bool          `json:"cached"`
+}
+
+// IsUp reports whether every check in the report passed
+func (r *Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+type HealthService interface {
+	// Liveness reports whether the process is able to serve requests at all.
+	// It never touches external dependencies.
+	Liveness(ctx context.Context) *Report
+	// Readiness runs every registered check and reports whether the application
+	// is ready to receive traffic. Results are cached to avoid hammering dependencies.
+	Readiness(ctx context.Context) *Report
+}
+
+type healthService struct {
+	checkers     []Checker
+	checkTimeout time.Duration
+	cacheTTL     time.Duration
+
+	mu     sync.Mutex
+	cached *Report
+}
+
+type NewHealthServiceParams struct {
+	fx.In
+
+	Config   *config.Config
+	Checkers []Checker `group:"health_checkers"`
+}
+
+func NewHealthServiceFromConfig(params NewHealthServiceParams) HealthService {
+	checkTimeout := params.Config.HealthCheckTimeout
+	if checkTimeout <= 0 {
+		checkTimeout = defaultCheckTimeout
+	}
+
+	cacheTTL := params.Config.HealthCacheTTL
+	if cacheTTL < 0 {
+		cacheTTL = 0
+	} else if cacheTTL == 0 {
+		cacheTTL = defaultCacheTTL
+	}
+
+	return &healthService{
+		checkers:     params.Checkers,
+		checkTimeout: checkTimeout,
+		cacheTTL:     cacheTTL,
+	}
+}
+
+func (s *healthService) Liveness(_ context.Context) *Report {
+	return &Report{
+		Status:    StatusUp,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (s *healthService) Readiness(ctx context.Context) *Report {
+	// Holding the lock while checking also collapses concurrent probes into a single run
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cached.CheckedAt) < s.cacheTTL {
+		report := *s.cached
+		report.Cached = true
+		return &report
+	}
+
+	report := s.run(ctx)
+	s.cached = report
+
+	return report
+}
+
+// run executes all checks concurrently, each bounded by its own timeout
+func (s *healthService) run(ctx context.Context) *Report {
+	results := make([]CheckResult, len(s.checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = s.runCheck(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	status := StatusUp
+	for _, result := range results {
+		if result.Status != StatusUp {
+			status = StatusDown
+			break
+		}
+	}
+
+	return &Report{
+		Status:    status,
+		Checks:    results,
+		CheckedAt: time.Now(),
+	}
+}
+
+func (s *healthService) runCheck(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := checker.Check(ctx)
+
+	result := CheckResult{
+		Name:     checker.Name(),
+		Status:   StatusUp,
+		Duration: time.Since(start).String(),
+		Details:  details,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
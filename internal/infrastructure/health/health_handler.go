@@ -0,0 +1,40 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"github.com/arfanxn/welding/pkg/boolutil"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler interface {
+	Livez(c *gin.Context)
+	Readyz(c *gin.Context)
+}
+
+type healthHandler struct {
+	healthService HealthService
+}
+
+func NewHealthHandler(healthService HealthService) HealthHandler {
+	return &healthHandler{
+		healthService: healthService,
+	}
+}
+
+func (h *healthHandler) Livez(c *gin.Context) {
+	report := h.healthService.Liveness(c.Request.Context())
+
+	c.JSON(http.StatusOK, response.NewBodyWithData(http.StatusOK, helper.T(c, "message.health.up", nil), report))
+}
+
+func (h *healthHandler) Readyz(c *gin.Context) {
+	report := h.healthService.Readiness(c.Request.Context())
+
+	code := boolutil.Ternary(report.IsUp(), http.StatusOK, http.StatusServiceUnavailable)
+	message := helper.T(c, boolutil.Ternary(report.IsUp(), "message.health.up", "message.health.not_ready"), nil)
+
+	c.JSON(code, response.NewBodyWithData(code, message, report))
+}
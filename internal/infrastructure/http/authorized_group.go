@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
+	"github.com/arfanxn/welding/internal/infrastructure/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// authorizedGroup registers the routes of a group behind a requirement of the authorize middleware,
+// recording the requirement of each route for the OpenAPI document
+type authorizedGroup struct {
+	group        *gin.RouterGroup
+	authorize    middleware.AuthorizeMiddleware
+	requirements openapi.RouteRequirements
+}
+
+func newAuthorizedGroup(
+	group *gin.RouterGroup,
+	authorize middleware.AuthorizeMiddleware,
+	requirements openapi.RouteRequirements,
+) *authorizedGroup {
+	return &authorizedGroup{group: group, authorize: authorize, requirements: requirements}
+}
+
+func (g *authorizedGroup) Handle(method, relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.requirements[method+" "+joinPaths(g.group.BasePath(), relativePath)] = requirement
+	g.group.Handle(method, relativePath, append([]gin.HandlerFunc{g.authorize.Require(requirement)}, handlers...)...)
+}
+
+func (g *authorizedGroup) GET(relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, relativePath, requirement, handlers...)
+}
+
+func (g *authorizedGroup) POST(relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, relativePath, requirement, handlers...)
+}
+
+func (g *authorizedGroup) PUT(relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPut, relativePath, requirement, handlers...)
+}
+
+func (g *authorizedGroup) PATCH(relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, relativePath, requirement, handlers...)
+}
+
+func (g *authorizedGroup) DELETE(relativePath string, requirement authz.Requirement, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, relativePath, requirement, handlers...)
+}
+
+// joinPaths joins the base path of a group and a relative path the way Gin does, keeping the trailing
+// slash of the relative path, so the route is keyed by the path Gin registers it under
+func joinPaths(absolutePath, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	joined := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
@@ -0,0 +1,4 @@
+package http
+
+// RouteDocs exposes the route documentation to the tests of the registered routes
+var RouteDocs = routeDocs
@@ -0,0 +1,109 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+)
+
+// ETag returns the weak entity tag of a resource at the given version and of its representation, e.g.
+// W/"3.5f2b9c0e1a7d4c33". The version is what If-Match compares, the digest of the representation tells
+// apart what changes without the version, such as the relations loaded with the resource.
+//
+// Parameters:
+//   - version: Version of the resource
+//   - representation: The resource as it is sent, serialized to JSON
+//
+// Returns:
+//   - string: The entity tag
+func ETag(version int64, representation any) string {
+	content, err := json.Marshal(representation)
+	if err != nil {
+		// Without a digest the tag never matches If-None-Match
+		return `W/"` + strconv.FormatInt(version, 10) + `"`
+	}
+	digest := sha256.Sum256(content)
+	return `W/"` + strconv.FormatInt(version, 10) + "." + hex.EncodeToString(digest[:8]) + `"`
+}
+
+// ParseETagVersions parses the versions listed in an If-Match or If-None-Match header
+//
+// Parameters:
+//   - header: Comma separated entity tags (weak or strong) or "*"
+//
+// Returns:
+//   - []int64: The versions, nil for "*" which matches any version
+//   - bool: Whether the header is "*" or lists at least one entity tag produced by ETag
+func ParseETagVersions(header string) ([]int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true
+	}
+
+	var versions []int64
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		// The version precedes the digest of the representation
+		value, _, _ := strings.Cut(tag[1:len(tag)-1], ".")
+		version, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	return versions, len(versions) > 0
+}
+
+// NotModified sets the ETag of the resource and answers 304 Not Modified when it matches If-None-Match,
+// comparing the entity tags weakly
+//
+// Parameters:
+//   - c: Gin context containing the incoming HTTP request
+//   - version: Current version of the resource
+//   - representation: The resource as it is sent
+//
+// Returns:
+//   - bool: Whether the 304 response was written, the handler must return without a body
+func NotModified(c *gin.Context, version int64, representation any) bool {
+	etag := ETag(version, representation)
+	c.Header("ETag", etag)
+
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" {
+		return false
+	}
+	if header != "*" && !slices.ContainsFunc(strings.Split(header, ","), func(tag string) bool {
+		return strings.TrimPrefix(strings.TrimSpace(tag), "W/") == strings.TrimPrefix(etag, "W/")
+	}) {
+		return false
+	}
+
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// IfMatchVersionsFromC returns the versions listed in If-Match, stored by the precondition middleware
+//
+// Parameters:
+//   - c: Gin context containing the incoming HTTP request
+//
+// Returns:
+//   - []int64: The versions the resource is expected to be at, nil when any version is accepted
+func IfMatchVersionsFromC(c *gin.Context) []int64 {
+	value, ok := c.Get(contextkey.IfMatchKey)
+	if !ok {
+		return nil
+	}
+	versions, _ := value.([]int64)
+	return versions
+}
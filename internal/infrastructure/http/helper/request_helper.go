@@ -3,8 +3,11 @@ package helper
 import (
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/arfanxn/welding/internal/infrastructure/http/request"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/pkg/boolutil"
 	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
@@ -23,11 +26,12 @@ import (
 // 2. Validates the struct using the Validate() method from the request.Request interface
 //
 // If binding fails, it returns a 400 Bad Request error.
-// If validation fails, it returns a 422 Unprocessable Entity error with detailed field errors.
+// If validation fails, it returns a 422 Unprocessable Entity error with detailed field errors,
+// translated to the locale of the request.
 func MustBindValidate(c *gin.Context, req request.Request) {
 	// Step 1: Bind request data to the struct
 	if err := c.ShouldBind(req); err != nil {
-		err = httperror.New(http.StatusBadRequest, "Permintaan tidak valid. Silakan periksa kembali data yang dikirim.", nil)
+		err = httperror.New(http.StatusBadRequest, "error.request.invalid", nil)
 		panic(err)
 	}
 
@@ -40,7 +44,7 @@ func MustBindValidate(c *gin.Context, req request.Request) {
 
 			// Process each validation error
 			for field, err := range validationErrs {
-				errStr := err.Error()
+				errStr := translateValidationError(c, field, err)
 				// Use the first error message as the main message
 				if message == "" {
 					message = errStr
@@ -59,6 +63,51 @@ func MustBindValidate(c *gin.Context, req request.Request) {
 	}
 }
 
+// translateValidationError translates a validation error of field by its code. A message specific
+// to the field (validation.<field>.<code>) wins over the generic one (validation.<code>), and errors
+// without a message keep the ozzo-validation text. Nested errors, such as the ones of each item
+// of a slice, are joined the way ozzo-validation does.
+func translateValidationError(c *gin.Context, field string, err error) string {
+	if nestedErrs, ok := err.(validation.Errors); ok {
+		keys := make([]string, 0, len(nestedErrs))
+		for key := range nestedErrs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		messages := make([]string, 0, len(keys))
+		for _, key := range keys {
+			messages = append(messages, key+": "+translateValidationError(c, field, nestedErrs[key]))
+		}
+		return strings.Join(messages, "; ") + "."
+	}
+
+	validationErr, ok := err.(validation.Error)
+	translator, hasTranslator := TranslatorFromC(c)
+	if !ok || !hasTranslator {
+		return err.Error()
+	}
+
+	params := map[string]any{}
+	for name, value := range validationErr.Params() {
+		params[name] = value
+	}
+	params["attribute"] = field
+	if attributeKey := "attribute." + field; translator.Has(attributeKey) {
+		params["attribute"] = T(c, attributeKey, nil)
+	}
+
+	for _, key := range []string{
+		"validation." + field + "." + validationErr.Code(),
+		"validation." + validationErr.Code(),
+	} {
+		if translator.Has(key) {
+			return T(c, key, params)
+		}
+	}
+	return validationErr.Error()
+}
+
 // URLFromC returns the URL of the current request
 //
 // Parameters:
@@ -67,10 +116,7 @@ func MustBindValidate(c *gin.Context, req request.Request) {
 // Returns:
 //   - url.URL: The URL of the current request
 func URLFromC(c *gin.Context) url.URL {
-	scheme := boolutil.Ternary(
-		c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https",
-		"https",
-		"http")
+	scheme := SchemeFromC(c)
 	host := c.Request.Host
 	path := c.Request.URL.Path
 	rq := c.Request.URL.RawQuery
@@ -83,3 +129,19 @@ func URLFromC(c *gin.Context) url.URL {
 	}
 	return u
 }
+
+// SchemeFromC returns the scheme (http or https) of the current request as seen by the client.
+// It prefers the scheme resolved by the trusted proxy middleware, which only honors
+// X-Forwarded-Proto from trusted proxies, and falls back to the TLS state of the connection.
+//
+// Parameters:
+//   - c: Gin context containing the incoming HTTP request
+//
+// Returns:
+//   - string: Either "http" or "https"
+func SchemeFromC(c *gin.Context) string {
+	if scheme := c.GetString(contextkey.SchemeKey); scheme != "" {
+		return scheme
+	}
+	return boolutil.Ternary(c.Request.TLS != nil, "https", "http")
+}
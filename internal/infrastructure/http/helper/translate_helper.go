@@ -0,0 +1,53 @@
+package helper
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+)
+
+// T translates a message key to the locale of the current request
+//
+// Parameters:
+//   - c: Gin context carrying the translator and locale set by the locale middleware
+//   - key: Message key, e.g. message.user.shown
+//   - params: Values of the placeholders in the message, may be nil
+//
+// Returns:
+//   - string: The translated message, or the key itself when it has no message or no translator is set
+func T(c *gin.Context, key string, params map[string]any) string {
+	translator, ok := TranslatorFromC(c)
+	if !ok {
+		return key
+	}
+	return translator.Translate(LocaleFromC(c), key, params)
+}
+
+// TranslatorFromC returns the translator stored by the locale middleware
+//
+// Parameters:
+//   - c: Gin context containing the incoming HTTP request
+//
+// Returns:
+//   - i18n.Translator: The translator
+//   - bool: Whether a translator is set
+func TranslatorFromC(c *gin.Context) (i18n.Translator, bool) {
+	value, ok := c.Get(contextkey.TranslatorKey)
+	if !ok {
+		return nil, false
+	}
+	translator, ok := value.(i18n.Translator)
+	return translator, ok
+}
+
+// LocaleFromC returns the locale of the current request, i.e. the preferred locale of the
+// authenticated user or the one negotiated from Accept-Language
+//
+// Parameters:
+//   - c: Gin context containing the incoming HTTP request
+//
+// Returns:
+//   - string: The locale, e.g. id or en, empty when the locale middleware did not run
+func LocaleFromC(c *gin.Context) string {
+	return c.GetString(contextkey.LocaleKey)
+}
@@ -9,27 +9,33 @@ import (
 
 type Claims struct {
 	UserID string `json:"user_id"`
+	// PermissionVersion is the permission version of the user when the token was issued, set when
+	// JWT_PERMISSION_VERSION is enabled
+	PermissionVersion *int64 `json:"pv,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type JWTService interface {
-	CreateToken(userID string) (string, error)
+	CreateToken(userID string, permissionVersion int64) (string, error)
 	VerifyToken(tokenStr string) (*Claims, error)
 }
 
 type jwtService struct {
 	Duration  time.Duration
 	SecretKey string
+	// EmbedPermissionVersion sets the PermissionVersion claim of the created tokens
+	EmbedPermissionVersion bool
 }
 
 func NewJWTServiceFromConfig(cfg *config.Config) JWTService {
 	return &jwtService{
-		Duration:  time.Duration(cfg.JWTDuration) * time.Hour,
-		SecretKey: cfg.JWTSecret,
+		Duration:               time.Duration(cfg.JWTDuration) * time.Hour,
+		SecretKey:              cfg.JWTSecret,
+		EmbedPermissionVersion: cfg.JWTPermissionVersion,
 	}
 }
 
-func (s *jwtService) CreateToken(userID string) (string, error) {
+func (s *jwtService) CreateToken(userID string, permissionVersion int64) (string, error) {
 	claims := &Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -37,6 +43,9 @@ func (s *jwtService) CreateToken(userID string) (string, error) {
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
+	if s.EmbedPermissionVersion {
+		claims.PermissionVersion = &permissionVersion
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.SecretKey))
@@ -0,0 +1,376 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/request"
+	"github.com/arfanxn/welding/pkg/reflectutil"
+	"github.com/gin-gonic/gin"
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	// BodySchemaName is the component name of the response.Body envelope
+	BodySchemaName = "Body"
+	// ProblemSchemaName is the component name of the response.Problem details
+	ProblemSchemaName = "Problem"
+	// ProblemMediaType is the media type of errors negotiated as RFC 9457 problem details
+	ProblemMediaType = "application/problem+json"
+	// BearerAuthScheme is the name of the JWT security scheme
+	BearerAuthScheme = "bearerAuth"
+	// IdempotencyKeyHeader is the header documented on idempotent routes
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+var pathParamExpr = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Route documents a single route, keyed in RouteDocs by "METHOD /path" exactly as registered in Gin.
+type Route struct {
+	Summary     string
+	Description string
+	// Tag groups the operation, it defaults to the first path segment after the API prefix
+	Tag string
+	// Public routes require no bearer token
+	Public bool
+	// Request is the body bound with helper.MustBindValidate, e.g. &request.StoreUser{}
+	Request request.Request
+	// Query is a struct whose form fields are bound from the query string, e.g. query.Query{}
+	Query any
+	// Status is the success status, http.StatusOK when zero
+	Status int
+	// Data is an example of the response.Body data, e.g. gin.H{"user": entity.User{}}
+	Data any
+	// Idempotent routes accept an Idempotency-Key header and replay the first response to retries
+	Idempotent bool
+	// Conditional routes serve a versioned resource: GET answers with an ETag and honours If-None-Match,
+	// other methods honour If-Match
+	Conditional bool
+	// Errors lists the error statuses returned besides the ones derived from the route
+	Errors     []int
+	Deprecated bool
+}
+
+// RouteDocs maps "METHOD /path" to the documentation of the route
+type RouteDocs map[string]Route
+
+// RouteRequirements maps "METHOD /path", keyed like RouteDocs, to the requirement of the authorize
+// middleware the route is registered behind. It is filled while the routes are registered, so the
+// documented permissions are the ones enforced.
+type RouteRequirements map[string]fmt.Stringer
+
+// NewRouteRequirements creates an empty set of route requirements
+func NewRouteRequirements() RouteRequirements {
+	return RouteRequirements{}
+}
+
+// Generator builds the OpenAPI document of the registered routes
+type Generator struct {
+	info          Info
+	apiPrefix     string
+	docs          RouteDocs
+	requirements  RouteRequirements
+	enums         map[reflect.Type][]any
+	bodySample    any
+	problemSample any
+}
+
+// NewGenerator creates a generator. apiPrefix (e.g. /api/v1) is stripped when deriving tags,
+// requirements lists the permissions of the protected routes, enums maps named string types to their allowed values, body is the response envelope
+// and problem is the alternative error body served as application/problem+json.
+func NewGenerator(info Info, apiPrefix string, docs RouteDocs, requirements RouteRequirements, enums map[reflect.Type][]any, body any, problem any) *Generator {
+	return &Generator{
+		info:          info,
+		apiPrefix:     apiPrefix,
+		docs:          docs,
+		requirements:  requirements,
+		enums:         enums,
+		bodySample:    body,
+		problemSample: problem,
+	}
+}
+
+// EnumValues converts a list of enum constants into schema enum values
+func EnumValues[T any](values []T) []any {
+	enumValues := make([]any, 0, len(values))
+	for _, value := range values {
+		enumValues = append(enumValues, value)
+	}
+	return enumValues
+}
+
+// Generate builds the document of routes. Routes without documentation are still listed,
+// flagged with x-undocumented, so the document never silently misses an endpoint.
+func (g *Generator) Generate(routes gin.RoutesInfo) *Document {
+	b := newSchemaBuilder(g.enums)
+	b.schemaOf(reflect.TypeOf(g.bodySample))
+	b.schemaOf(reflect.TypeOf(g.problemSample))
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    g.info,
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: b.components,
+			SecuritySchemes: map[string]*SecurityScheme{
+				BearerAuthScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	routes = slices.Clone(routes)
+	slices.SortStableFunc(routes, func(a, b gin.RouteInfo) int {
+		return strings.Compare(a.Path+" "+a.Method, b.Path+" "+b.Method)
+	})
+
+	operationIds := map[string]int{}
+	tags := []string{}
+	for _, route := range routes {
+		r, documented := g.docs[route.Method+" "+route.Path]
+		path := pathParamExpr.ReplaceAllString(route.Path, "{$1}")
+		op := g.operation(b, route, path, r, documented)
+
+		// Routes registered with and without a trailing slash share a generated id
+		operationIds[op.OperationId]++
+		if n := operationIds[op.OperationId]; n > 1 {
+			op.OperationId += strconv.Itoa(n)
+		}
+
+		for _, tag := range op.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+
+	for _, tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+
+	return doc
+}
+
+// operation documents route, whose path is the Gin path (e.g. /users/:id) and openAPIPath its OpenAPI form
+func (g *Generator) operation(b *schemaBuilder, route gin.RouteInfo, openAPIPath string, r Route, documented bool) *Operation {
+	op := &Operation{
+		OperationId:  operationId(route.Method, route.Path),
+		Summary:      r.Summary,
+		Description:  r.Description,
+		Tags:         []string{g.tagOf(openAPIPath, r)},
+		Responses:    map[string]*Response{},
+		Deprecated:   r.Deprecated,
+		Undocumented: !documented,
+	}
+	if !documented {
+		op.Summary = handlerName(route.Handler)
+	}
+
+	// Path parameters
+	var pathParams []string
+	for _, match := range pathParamExpr.FindAllStringSubmatch(route.Path, -1) {
+		pathParams = append(pathParams, match[1])
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	// Query parameters
+	if r.Query != nil {
+		explode := true
+		for _, f := range fieldsOf(reflect.TypeOf(r.Query), "form") {
+			param := &Parameter{
+				Name:        f.Name,
+				In:          "query",
+				Description: f.Description,
+				Schema:      b.schemaOf(f.Type),
+			}
+			if f.HasDefault && f.Default != "[]" {
+				defaultValue := reflect.New(f.Type).Elem()
+				if err := reflectutil.SetValueFromString(defaultValue, f.Default); err == nil {
+					param.Schema.Default = defaultValue.Interface()
+				}
+			}
+			if param.Schema.Type == "array" {
+				param.Explode = &explode
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+	}
+
+	// Idempotency key
+	if r.Idempotent {
+		maxLength := 255
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        IdempotencyKeyHeader,
+			In:          "header",
+			Description: "Unique key of the request. Retries with the same key and body replay the first response (flagged with `Idempotent-Replayed: true`) instead of executing it again.",
+			Schema:      &Schema{Type: "string", MaxLength: &maxLength},
+		})
+	}
+
+	// Conditional request headers
+	if r.Conditional {
+		header := "If-Match"
+		description := "ETag of the resource as last read. The request fails with 412 when the resource changed since, and with 428 when the header is missing while required by the server."
+		if route.Method == http.MethodGet {
+			header = "If-None-Match"
+			description = "ETag of the resource as last read, the response is 304 Not Modified without a body while it still matches."
+		}
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:        header,
+			In:          "header",
+			Description: description,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	// Request body
+	if r.Request != nil {
+		schema := b.requestSchemaOf(r.Request, pathParams)
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				"application/json":                  {Schema: schema},
+				"application/x-www-form-urlencoded": {Schema: schema},
+			},
+		}
+	}
+
+	// Security and permissions
+	if documented && !r.Public {
+		op.Security = []map[string][]string{{BearerAuthScheme: {}}}
+		if requirement, ok := g.requirements[route.Method+" "+route.Path]; ok {
+			op.Permissions = []string{requirement.String()}
+		}
+		if len(op.Permissions) > 0 {
+			op.Description = strings.TrimSpace(op.Description + "\n\nRequired permissions: `" + strings.Join(op.Permissions, "`, `") + "`.")
+		}
+	}
+
+	// Success response
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	op.Responses[strconv.Itoa(status)] = g.response(b, status, r.Data)
+	if r.Conditional {
+		if r.Data != nil {
+			op.Responses[strconv.Itoa(status)].Headers = map[string]*Header{
+				"ETag": {Description: "Weak entity tag of the current version of the resource", Schema: &Schema{Type: "string"}},
+			}
+		}
+		if route.Method == http.MethodGet {
+			op.Responses[strconv.Itoa(http.StatusNotModified)] = &Response{Description: http.StatusText(http.StatusNotModified)}
+		}
+	}
+
+	// Error responses
+	errorStatuses := slices.Clone(r.Errors)
+	if r.Request != nil {
+		errorStatuses = append(errorStatuses, http.StatusBadRequest, http.StatusUnprocessableEntity)
+	}
+	if op.Security != nil {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized)
+		if len(op.Permissions) > 0 {
+			errorStatuses = append(errorStatuses, http.StatusForbidden)
+		}
+	}
+	if r.Idempotent {
+		errorStatuses = append(errorStatuses, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity)
+	}
+	if r.Conditional && route.Method != http.MethodGet {
+		errorStatuses = append(errorStatuses, http.StatusPreconditionFailed, http.StatusPreconditionRequired)
+	}
+	if len(pathParams) > 0 {
+		errorStatuses = append(errorStatuses, http.StatusNotFound)
+	}
+	if g.apiPrefix != "" && strings.HasPrefix(route.Path, g.apiPrefix) {
+		errorStatuses = append(errorStatuses, http.StatusTooManyRequests)
+	}
+	errorStatuses = append(errorStatuses, http.StatusInternalServerError)
+	slices.Sort(errorStatuses)
+	for _, errorStatus := range slices.Compact(errorStatuses) {
+		op.Responses[strconv.Itoa(errorStatus)] = g.errorResponse(errorStatus)
+	}
+
+	return op
+}
+
+// response describes a response whose body is the envelope, with data typed after the example
+func (g *Generator) response(b *schemaBuilder, status int, data any) *Response {
+	schema := RefSchema(BodySchemaName)
+	if data != nil {
+		schema = &Schema{AllOf: []*Schema{
+			RefSchema(BodySchemaName),
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": b.valueSchemaOf(data)},
+				Required:   []string{"data"},
+			},
+		}}
+	}
+
+	return &Response{
+		Description: http.StatusText(status),
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+}
+
+// errorResponse describes an error, sent as the envelope or as problem details depending on the Accept header
+func (g *Generator) errorResponse(status int) *Response {
+	return &Response{
+		Description: http.StatusText(status),
+		Content: map[string]*MediaType{
+			"application/json": {Schema: RefSchema(BodySchemaName)},
+			ProblemMediaType:   {Schema: RefSchema(ProblemSchemaName)},
+		},
+	}
+}
+
+func (g *Generator) tagOf(path string, r Route) string {
+	if r.Tag != "" {
+		return r.Tag
+	}
+	if g.apiPrefix == "" || !strings.HasPrefix(path, g.apiPrefix) {
+		return "system"
+	}
+	segment, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimPrefix(path, g.apiPrefix), "/"), "/")
+	if segment == "" {
+		return "system"
+	}
+	return segment
+}
+
+// operationId derives a stable id from the method and Gin path, e.g. GET /api/v1/users/:id becomes getApiV1UsersById
+func operationId(method string, path string) string {
+	path = pathParamExpr.ReplaceAllString(path, "by_$1")
+	return strcase.ToLowerCamel(strings.ToLower(method) + "_" + nonAlphanumericExpr.ReplaceAllString(path, "_"))
+}
+
+// handlerName shortens a Gin handler name such as
+// github.com/.../http.(*userHandler).Login-fm to userHandler.Login
+func handlerName(name string) string {
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if _, rest, ok := strings.Cut(name, "."); ok {
+		name = rest
+	}
+	return strings.NewReplacer("(", "", ")", "", "*", "").Replace(name)
+}
@@ -0,0 +1,88 @@
+package openapi
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+// docsAssets holds the Redoc bundle vendored by `make docs-assets`, served with the documentation page
+//
+//go:embed all:assets
+var docsAssets embed.FS
+
+// docsCSP relaxes the default Content-Security-Policy for the documentation page, which runs the
+// vendored Redoc bundle, styles itself inline and renders the spec in a web worker.
+const docsCSP = "default-src 'none'; " +
+	"script-src 'self'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; " +
+	"worker-src blob:; " +
+	"connect-src 'self'; " +
+	"frame-ancestors 'none'"
+
+type OpenAPIHandler interface {
+	// Document returns the JSON encoded OpenAPI document of the registered routes
+	Document() ([]byte, error)
+	Spec(c *gin.Context)
+	UI(c *gin.Context)
+	// Asset serves the files of the documentation page, the :name route parameter
+	Asset(c *gin.Context)
+}
+
+type openAPIHandler struct {
+	engine    *gin.Engine
+	generator *Generator
+	assets    http.FileSystem
+
+	once     sync.Once
+	document []byte
+	err      error
+}
+
+func NewOpenAPIHandler(engine *gin.Engine, generator *Generator) OpenAPIHandler {
+	assets, err := fs.Sub(docsAssets, "assets")
+	if err != nil {
+		panic(err)
+	}
+
+	return &openAPIHandler{
+		engine:    engine,
+		generator: generator,
+		assets:    http.FS(assets),
+	}
+}
+
+// Document generates the document on first use, once every route has been registered.
+func (h *openAPIHandler) Document() ([]byte, error) {
+	h.once.Do(func() {
+		h.document, h.err = json.MarshalIndent(h.generator.Generate(h.engine.Routes()), "", "  ")
+	})
+	return h.document, h.err
+}
+
+func (h *openAPIHandler) Spec(c *gin.Context) {
+	document, err := h.Document()
+	if err != nil {
+		panic(err)
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+func (h *openAPIHandler) UI(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
+
+func (h *openAPIHandler) Asset(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.FileFromFS(c.Param("name"), h.assets)
+}
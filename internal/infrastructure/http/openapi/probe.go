@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/request"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// probeLength is longer than any length rule in use, so it always trips them
+const probeLength = 10_000
+
+// dateLayouts are the layouts tried against fields validated by validation.Date
+var dateLayouts = []string{time.DateTime, time.DateOnly, time.RFC3339, time.TimeOnly}
+
+// formatsByCode maps ozzo validation error codes to JSON schema formats and patterns
+var formatsByCode = map[string]Schema{
+	"validation_is_email":            {Format: "email"},
+	"validation_is_email_format":     {Format: "email"},
+	"validation_is_url":              {Format: "uri"},
+	"validation_is_request_uri":      {Format: "uri-reference"},
+	"validation_is_uuid":             {Format: "uuid"},
+	"validation_is_ipv4":             {Format: "ipv4"},
+	"validation_is_ipv6":             {Format: "ipv6"},
+	"validation_is_alpha":            {Pattern: "^[a-zA-Z]+$"},
+	"validation_is_alphanumeric":     {Pattern: "^[a-zA-Z0-9]+$"},
+	"validation_is_digit":            {Pattern: "^[0-9]+$"},
+	"validation_is_lower_case":       {Pattern: "^[^A-Z]*$"},
+	"validation_is_upper_case":       {Pattern: "^[^a-z]*$"},
+	"validation_is_e164_number":      {Pattern: `^\+?[1-9]\d{1,14}$`},
+	"validation_is_utf_letter_num":   {Description: "Letters and numbers only"},
+	"validation_match_invalid":       {Description: "Must match the expected format"},
+	"validation_is_semantic_version": {Description: "Semantic version"},
+}
+
+// requestSchemaOf returns the body schema of req. Besides the field types, the ozzo rules of
+// req.Validate are probed: the struct is validated with crafted values for one field at a time
+// and the resulting error codes and params reveal required fields, length limits and formats.
+// Fields named in exclude (e.g. path parameters) are left out.
+func (b *schemaBuilder) requestSchemaOf(req request.Request, exclude []string) *Schema {
+	t := reflect.TypeOf(req).Elem()
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	// Fields failing with a required error on an empty struct are required
+	emptyErrs := validate(t, nil, reflect.Value{})
+
+	for _, f := range fieldsOf(t, "json") {
+		if slices.Contains(exclude, f.Name) {
+			continue
+		}
+
+		fieldSchema := b.schemaOf(f.Type)
+		fieldSchema.Description = f.Description
+		if isRequiredError(emptyErrs[f.Name]) {
+			schema.Required = append(schema.Required, f.Name)
+		}
+
+		elemType := f.Type
+		for elemType.Kind() == reflect.Pointer {
+			elemType = elemType.Elem()
+		}
+		switch {
+		case elemType.Kind() == reflect.String && fieldSchema.Enum == nil:
+			probeString(t, f, fieldSchema, func(s string) reflect.Value { return reflect.ValueOf(s).Convert(elemType) }, nil)
+		case elemType.Kind() == reflect.Slice && elemType.Elem().Kind() == reflect.String && fieldSchema.Items != nil:
+			itemType := elemType.Elem()
+			probeString(t, f, fieldSchema.Items, func(s string) reflect.Value {
+				slice := reflect.MakeSlice(elemType, 1, 1)
+				slice.Index(0).Set(reflect.ValueOf(s).Convert(itemType))
+				return slice
+			}, func(err error) error {
+				// validation.Each reports item errors keyed by index
+				if errs, ok := err.(validation.Errors); ok {
+					return errs["0"]
+				}
+				return err
+			})
+		}
+
+		schema.Properties[f.Name] = fieldSchema
+	}
+
+	return schema
+}
+
+// probeString probes the length and format rules of a string (or string slice) field.
+// makeValue builds the field value from a probe string, unwrap extracts the relevant error.
+func probeString(t reflect.Type, f field, schema *Schema, makeValue func(string) reflect.Value, unwrap func(error) error) {
+	errorOf := func(s string) validation.Error {
+		err := validate(t, f.Index, makeValue(s))[f.Name]
+		if unwrap != nil && err != nil {
+			err = unwrap(err)
+		}
+		ve, _ := err.(validation.Error)
+		return ve
+	}
+
+	// An overlong value trips the length rule, whose params carry both limits
+	minLength := 1
+	if ve := errorOf(strings.Repeat("a", probeLength)); ve != nil && strings.HasPrefix(ve.Code(), "validation_length") {
+		if min, ok := ve.Params()["min"].(int); ok && min > 0 {
+			schema.MinLength = &min
+			minLength = min
+		}
+		if max, ok := ve.Params()["max"].(int); ok && max > 0 {
+			schema.MaxLength = &max
+		}
+	}
+
+	// A value of valid length made of symbols trips format rules only
+	ve := errorOf(strings.Repeat("!", minLength))
+	if ve == nil {
+		return
+	}
+	if format, ok := formatsByCode[ve.Code()]; ok {
+		schema.Format = format.Format
+		schema.Pattern = format.Pattern
+		if schema.Description == "" {
+			schema.Description = format.Description
+		}
+		return
+	}
+	if ve.Code() == "validation_date_invalid" {
+		future := time.Now().AddDate(1, 0, 0)
+		for _, layout := range dateLayouts {
+			example := future.Format(layout)
+			if errorOf(example) == nil {
+				schema.Example = example
+				schema.Description = strings.TrimSpace(schema.Description + " Layout: " + layout)
+				if layout == time.RFC3339 {
+					schema.Format = "date-time"
+				}
+				return
+			}
+		}
+	}
+}
+
+// validate validates a zero value of struct type t with the field at index set to value,
+// returning the errors keyed by field name. A nil index validates the zero value as is.
+func validate(t reflect.Type, index []int, value reflect.Value) (errs validation.Errors) {
+	ptr := reflect.New(t)
+	if index != nil {
+		fieldValue := ptr.Elem().FieldByIndex(index)
+		if fieldValue.Kind() == reflect.Pointer {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			fieldValue = fieldValue.Elem()
+		}
+		fieldValue.Set(value)
+	}
+
+	// Validation rules written for real input may panic on crafted values, treat it as no error
+	defer func() {
+		if r := recover(); r != nil {
+			errs = validation.Errors{}
+		}
+	}()
+
+	err := ptr.Interface().(request.Request).Validate()
+	if errs, ok := err.(validation.Errors); ok {
+		return errs
+	}
+	return validation.Errors{}
+}
+
+func isRequiredError(err error) bool {
+	ve, ok := err.(validation.Error)
+	return ok && (ve.Code() == validation.ErrRequired.Code() || ve.Code() == validation.ErrNilOrNotEmpty.Code())
+}
@@ -0,0 +1,218 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const nullPkgPath = "github.com/guregu/null/v6"
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	nonAlphanumericExpr = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// schemaBuilder derives JSON schemas from Go types via reflection.
+// Named structs become component schemas referenced by $ref, so recursive types are supported.
+type schemaBuilder struct {
+	components map[string]*Schema
+	enums      map[reflect.Type][]any
+}
+
+func newSchemaBuilder(enums map[reflect.Type][]any) *schemaBuilder {
+	return &schemaBuilder{
+		components: map[string]*Schema{},
+		enums:      enums,
+	}
+}
+
+// field is an exported struct field as seen by encoding/json (or by form binding)
+type field struct {
+	Name        string
+	Type        reflect.Type
+	Index       []int
+	OmitEmpty   bool
+	Default     string
+	HasDefault  bool
+	Description string
+}
+
+// fieldsOf returns the fields of struct type t named by tagKey ("json" or "form").
+// Anonymous embedded structs are flattened, fields tagged "-" are skipped.
+func fieldsOf(t reflect.Type, tagKey string) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		tag := sf.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" {
+			embedded := sf.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for _, f := range fieldsOf(embedded, tagKey) {
+					f.Index = append([]int{i}, f.Index...)
+					fields = append(fields, f)
+				}
+				continue
+			}
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		defaultValue, hasDefault := sf.Tag.Lookup("default")
+
+		fields = append(fields, field{
+			Name:        name,
+			Type:        sf.Type,
+			Index:       []int{i},
+			OmitEmpty:   strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero"),
+			Default:     defaultValue,
+			HasDefault:  hasDefault,
+			Description: sf.Tag.Get("description"),
+		})
+	}
+	return fields
+}
+
+// schemaOf returns the schema of t, registering component schemas for named structs.
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if values, ok := b.enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.PkgPath() == nullPkgPath:
+		return b.nullSchemaOf(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		// Raw JSON (e.g. json.RawMessage, datatypes.JSON) may hold any value
+		if t.Elem().Kind() == reflect.Uint8 && t.Implements(jsonMarshalerType) {
+			return &Schema{}
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.objectSchemaOf(t)
+		}
+		name := componentName(t)
+		if _, ok := b.components[name]; !ok {
+			// Register a placeholder first so recursive references terminate
+			b.components[name] = &Schema{}
+			*b.components[name] = *b.objectSchemaOf(t)
+		}
+		return RefSchema(name)
+	default:
+		// interface{} and anything else accepts any value
+		return &Schema{}
+	}
+}
+
+// objectSchemaOf returns the inline object schema of struct type t using its json tags.
+// Fields without omitempty are listed as required, as they are always present in responses.
+func (b *schemaBuilder) objectSchemaOf(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fieldsOf(t, "json") {
+		schema.Properties[f.Name] = b.schemaOf(f.Type)
+		if !f.OmitEmpty {
+			schema.Required = append(schema.Required, f.Name)
+		}
+	}
+	return schema
+}
+
+// nullSchemaOf maps the guregu/null types to nullable schemas
+func (b *schemaBuilder) nullSchemaOf(t reflect.Type) *Schema {
+	switch t.Name() {
+	case "Time":
+		return &Schema{Type: []string{"string", "null"}, Format: "date-time"}
+	case "String":
+		return &Schema{Type: []string{"string", "null"}}
+	case "Int", "Int32", "Int16", "Byte":
+		return &Schema{Type: []string{"integer", "null"}}
+	case "Float":
+		return &Schema{Type: []string{"number", "null"}}
+	case "Bool":
+		return &Schema{Type: []string{"boolean", "null"}}
+	default:
+		return &Schema{}
+	}
+}
+
+// valueSchemaOf returns the schema of an example value. Maps with string keys are described
+// property by property, which allows response data such as gin.H{"user": entity.User{}}.
+func (b *schemaBuilder) valueSchemaOf(value any) *Schema {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			schema.Properties[key] = b.valueSchemaOf(iter.Value().Interface())
+			schema.Required = append(schema.Required, key)
+		}
+		slices.Sort(schema.Required)
+		return schema
+	}
+	if value == nil {
+		return &Schema{}
+	}
+	return b.schemaOf(v.Type())
+}
+
+// componentName returns a readable component name for a named type,
+// e.g. PagePagination[*entity.User] becomes PagePaginationUser.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	base, args, isGeneric := strings.Cut(name, "[")
+	if !isGeneric {
+		return name
+	}
+
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		if i := strings.LastIndex(arg, "."); i >= 0 {
+			arg = arg[i+1:]
+		}
+		sb.WriteString(nonAlphanumericExpr.ReplaceAllString(arg, ""))
+	}
+	return sb.String()
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/health"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
+	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	loggerRequest "github.com/arfanxn/welding/internal/infrastructure/logger/presentation/http/request"
+	auditLogEnum "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	codeEnum "github.com/arfanxn/welding/internal/module/code/domain/enum"
+	codeRequest "github.com/arfanxn/welding/internal/module/code/presentation/http/request"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	roleRequest "github.com/arfanxn/welding/internal/module/role/presentation/http/request"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	userRequest "github.com/arfanxn/welding/internal/module/user/presentation/http/request"
+	"github.com/arfanxn/welding/pkg/pagination"
+	"github.com/arfanxn/welding/pkg/query"
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix is the path prefix of every versioned API route
+const APIPrefix = "/api/v1"
+
+// NewOpenAPIGenerator creates the OpenAPI generator of the routes registered in RegisterRoutes, their
+// permissions read from the requirements recorded on registration.
+// Keep routeDocs in sync when adding a route, undocumented routes show up as x-undocumented.
+func NewOpenAPIGenerator(cfg *config.Config, requirements openapi.RouteRequirements) *openapi.Generator {
+	return openapi.NewGenerator(
+		openapi.Info{
+			Title:       cfg.AppName + " API",
+			Version:     "v1",
+			Description: "Every response is wrapped in the `Body` envelope. Errors carry a stable `error_code` and are sent as RFC 9457 `Problem` details instead when the request accepts `application/problem+json`. Messages are in Indonesian (`id`) or English (`en`), taken from the preferred locale of the authenticated user, then `Accept-Language`. Protected routes require a bearer token obtained from the login endpoint.",
+		},
+		APIPrefix,
+		routeDocs,
+		requirements,
+		map[reflect.Type][]any{
+			reflect.TypeOf(roleEnum.RoleName("")):               openapi.EnumValues(roleEnum.RoleNames),
+			reflect.TypeOf(permissionEnum.PermissionName("")):   openapi.EnumValues(permissionEnum.PermissionNames),
+			reflect.TypeOf(codeEnum.CodeType("")):               openapi.EnumValues(codeEnum.CodeTypes),
+			reflect.TypeOf(auditLogEnum.AuditLogAction("")):     openapi.EnumValues(auditLogEnum.AuditLogActions),
+			reflect.TypeOf(auditLogEnum.AuditLogTargetType("")): openapi.EnumValues(auditLogEnum.AuditLogTargetTypes),
+		},
+		response.Body{},
+		response.Problem{},
+	)
+}
+
+// departmentLimit describes the department rule of the user policy to the routes managing users
+const departmentLimit = "Users who do not hold `users.any_department` only manage the users of their own department."
+
+var routeDocs = openapi.RouteDocs{
+	// System
+	"GET /livez": {Summary: "Liveness probe", Public: true, Data: health.Report{}},
+	"GET /readyz": {
+		Summary: "Readiness probe", Public: true, Data: health.Report{},
+		Errors: []int{http.StatusServiceUnavailable},
+	},
+	"GET /api/v1/health": {
+		Summary: "Readiness probe (alias of /readyz)", Tag: "system", Public: true, Data: health.Report{},
+		Errors: []int{http.StatusServiceUnavailable},
+	},
+	"GET /api/v1/openapi.json": {Summary: "OpenAPI document", Tag: "system", Public: true},
+	"GET /api/v1/docs":         {Summary: "API documentation UI", Tag: "system", Public: true},
+	"GET /api/v1/docs/:name":   {Summary: "API documentation UI assets", Tag: "system", Public: true},
+
+	// Users (public)
+	"POST /api/v1/users/": {
+		Summary: "Login (alias of /users/login)", Public: true, Deprecated: true,
+		Request: &userRequest.LoginUser{}, Data: gin.H{"user": entity.User{}, "token": ""},
+	},
+	"POST /api/v1/users/login": {
+		Summary: "Login", Public: true,
+		Request: &userRequest.LoginUser{}, Data: gin.H{"user": entity.User{}, "token": ""},
+	},
+	"POST /api/v1/users/register": {
+		Summary: "Register", Public: true, Status: http.StatusCreated,
+		Request: &userRequest.RegisterUser{}, Data: gin.H{"user": entity.User{}},
+		Errors: []int{http.StatusConflict},
+	},
+	"POST /api/v1/users/verify-email": {
+		Summary: "Verify email", Public: true,
+		Request: &userRequest.VerifyEmail{}, Data: gin.H{"user": entity.User{}},
+		Errors: []int{http.StatusNotFound},
+	},
+	"PATCH /api/v1/users/reset-password": {
+		Summary: "Reset password", Public: true,
+		Request: &userRequest.ResetPassword{}, Data: gin.H{"user": entity.User{}},
+		Errors: []int{http.StatusNotFound},
+	},
+
+	// Codes (public)
+	"POST /api/v1/codes/user-email-verification": {
+		Summary: "Create user email verification code", Public: true, Status: http.StatusCreated,
+		Request: &codeRequest.CreateUserEmailVerification{}, Idempotent: true,
+	},
+	"POST /api/v1/codes/user-reset-password": {
+		Summary: "Create user reset password code", Public: true, Status: http.StatusCreated,
+		Request: &codeRequest.CreateUserResetPassword{}, Idempotent: true,
+	},
+
+	// Me
+	"DELETE /api/v1/users/logout": {Summary: "Logout"},
+	"GET /api/v1/users/me": {
+		Summary: "Show the authenticated user", Query: query.Query{}, Data: gin.H{"user": entity.User{}}, Conditional: true,
+	},
+	"PUT /api/v1/users/me": {
+		Summary: "Update the authenticated user profile",
+		Request: &userRequest.UpdateUserMeProfile{}, Data: gin.H{"user": entity.User{}},
+		Errors: []int{http.StatusConflict},
+	},
+	"PATCH /api/v1/users/me/password": {
+		Summary: "Update the authenticated user password",
+		Request: &userRequest.UpdateUserMePassword{}, Data: gin.H{"user": entity.User{}},
+	},
+
+	// Users
+	"GET /api/v1/users": {
+		Summary: "Paginate users",
+		Query:   query.Query{}, Data: pagination.PagePagination[*entity.User]{},
+	},
+	"GET /api/v1/users/:id": {
+		Summary: "Show a user",
+		Query:   query.Query{}, Data: gin.H{"user": entity.User{}}, Conditional: true,
+	},
+	"POST /api/v1/users": {
+		Summary: "Store a user", Description: departmentLimit, Status: http.StatusCreated,
+		Request: &userRequest.StoreUser{}, Data: gin.H{"user": entity.User{}}, Idempotent: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	"PUT /api/v1/users/:id": {
+		Summary: "Update a user", Description: "Users update their own profile, but not their roles or department. " + departmentLimit,
+		Request: &userRequest.UpdateUser{}, Data: gin.H{"user": entity.User{}}, Conditional: true,
+		Errors: []int{http.StatusBadRequest, http.StatusConflict},
+	},
+	"PATCH /api/v1/users/:id/activation/toggle": {
+		Summary: "Toggle the activation of a user", Description: departmentLimit,
+		Data: gin.H{"user": entity.User{}}, Conditional: true,
+	},
+	"POST /api/v1/users/:id/role-grants": {
+		Summary: "Grant a role to a user temporarily", Description: "The user holds the role from `starts_at`, now when omitted, until `expires_at`, and is notified when the grant begins and ends. A temporary grant of the role replaces the previous one, a permanent one is kept. " + departmentLimit,
+		Status: http.StatusCreated, Request: &userRequest.GrantUserRole{}, Data: gin.H{"role_user": entity.RoleUser{}}, Idempotent: true,
+		Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+	"DELETE /api/v1/users/:id": {
+		Summary: "Destroy a user", Description: departmentLimit, Conditional: true,
+	},
+
+	// Roles
+	"GET /api/v1/roles": {
+		Summary: "Paginate roles",
+		Query:   query.Query{}, Data: pagination.PagePagination[*entity.Role]{},
+	},
+	"GET /api/v1/roles/:id": {
+		Summary: "Show a role",
+		Query:   query.Query{}, Data: gin.H{"role": entity.Role{}}, Conditional: true,
+	},
+	"POST /api/v1/roles": {
+		Summary: "Store a role", Status: http.StatusCreated,
+		Request: roleRequest.NewStoreRole(), Data: gin.H{"role": entity.Role{}}, Idempotent: true,
+		Errors: []int{http.StatusNotFound, http.StatusConflict},
+	},
+	"PUT /api/v1/roles/:id": {
+		Summary: "Update a role",
+		Request: roleRequest.NewUpdateRole(), Data: gin.H{"role": entity.Role{}}, Conditional: true,
+		Errors: []int{http.StatusConflict},
+	},
+	"PATCH /api/v1/roles/:id/set-default": {
+		Summary: "Set a role as the default role",
+		Data:    gin.H{"role": entity.Role{}}, Conditional: true,
+	},
+	"DELETE /api/v1/roles/:id": {
+		Summary: "Destroy a role", Conditional: true,
+	},
+
+	// Permissions
+	"GET /api/v1/permissions": {
+		Summary: "Paginate permissions",
+		Query:   query.Query{}, Data: pagination.PagePagination[*entity.Permission]{},
+	},
+
+	// Codes
+	"POST /api/v1/codes/user-register-invitation": {
+		Summary: "Create user register invitation code",
+		Status:  http.StatusCreated, Request: &codeRequest.CreateUserRegisterInvitation{}, Data: gin.H{"code": entity.Code{}},
+		Idempotent: true,
+		Errors:     []int{http.StatusNotFound},
+	},
+
+	// Logs
+	"GET /api/v1/logs/level": {
+		Summary: "Show the log level of every output",
+		Data:    map[string]string{},
+	},
+	"PUT /api/v1/logs/level": {
+		Summary: "Change the log level at runtime",
+		Request: loggerRequest.NewUpdateLogLevel(), Data: map[string]string{},
+	},
+
+	// Audit logs
+	"GET /api/v1/audit-logs": {
+		Summary: "Paginate audit logs, filtered by actor_id, action, target_type, target_id and created_at (>= and <=)",
+		Query:   query.Query{}, Data: pagination.PagePagination[*entity.AuditLog]{},
+		Errors: []int{http.StatusBadRequest},
+	},
+}
@@ -0,0 +1,81 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+)
+
+// statuses is the single place where domain errors are assigned an HTTP status.
+// Their default message and problem title is the catalog message error.<code>,
+// handlers only override the message where the endpoint needs a more specific one.
+var statuses = map[errorx.Errorx]int{
+	// Auth
+	errorx.ErrAuthTokenMissing:   http.StatusUnauthorized,
+	errorx.ErrAuthTokenMalformed: http.StatusUnauthorized,
+	errorx.ErrAuthTokenInvalid:   http.StatusUnauthorized,
+	errorx.ErrAuthTokenStale:     http.StatusUnauthorized,
+	errorx.ErrAuthForbidden:      http.StatusForbidden,
+
+	// Request
+	errorx.ErrRequestPreconditionRequired: http.StatusPreconditionRequired,
+	errorx.ErrRequestPreconditionFailed:   http.StatusPreconditionFailed,
+	errorx.ErrRequestFilterInvalid:        http.StatusBadRequest,
+
+	// User
+	errorx.ErrUserNotFound:                      http.StatusNotFound,
+	errorx.ErrUserAlreadyExists:                 http.StatusConflict,
+	errorx.ErrUserEmailAlreadyVerified:          http.StatusBadRequest,
+	errorx.ErrUserPasswordIncorrect:             http.StatusBadRequest,
+	errorx.ErrUserCredentialsInvalid:            http.StatusUnauthorized,
+	errorx.ErrUserInactive:                      http.StatusUnauthorized,
+	errorx.ErrUserEmailNotVerified:              http.StatusUnauthorized,
+	errorx.ErrUserSuperAdminUpdateForbidden:     http.StatusForbidden,
+	errorx.ErrUserSuperAdminRoleChangeForbidden: http.StatusForbidden,
+	errorx.ErrUserSuperAdminAssignmentForbidden: http.StatusForbidden,
+	errorx.ErrUserOutsideDepartment:             http.StatusForbidden,
+	errorx.ErrUserNotEmployee:                   http.StatusBadRequest,
+	errorx.ErrUserModified:                      http.StatusPreconditionFailed,
+
+	// Role
+	errorx.ErrRoleNotFound:                      http.StatusNotFound,
+	errorx.ErrRolesNotFound:                     http.StatusNotFound,
+	errorx.ErrRoleAlreadyExists:                 http.StatusConflict,
+	errorx.ErrRoleAlreadyDefault:                http.StatusConflict,
+	errorx.ErrRoleDefaultNotConfigured:          http.StatusBadRequest,
+	errorx.ErrRoleDefaultDestroyForbidden:       http.StatusForbidden,
+	errorx.ErrRoleSuperAdminStoreForbidden:      http.StatusForbidden,
+	errorx.ErrRoleSuperAdminUpdateForbidden:     http.StatusForbidden,
+	errorx.ErrRoleSuperAdminSetDefaultForbidden: http.StatusForbidden,
+	errorx.ErrRoleSuperAdminDestroyForbidden:    http.StatusForbidden,
+	errorx.ErrRoleParentNotFound:                http.StatusNotFound,
+	errorx.ErrRoleParentCycle:                   http.StatusConflict,
+	errorx.ErrRoleModified:                      http.StatusPreconditionFailed,
+
+	// Role user
+	errorx.ErrRoleUserNotFound:       http.StatusNotFound,
+	errorx.ErrRoleUserAlreadyGranted: http.StatusConflict,
+
+	// Permission
+	errorx.ErrPermissionNotFound:      http.StatusNotFound,
+	errorx.ErrPermissionsNotFound:     http.StatusNotFound,
+	errorx.ErrPermissionAlreadyExists: http.StatusConflict,
+
+	// Permission role
+	errorx.ErrPermissionRoleNotFound:      http.StatusNotFound,
+	errorx.ErrPermissionRoleAlreadyExists: http.StatusConflict,
+
+	// Code
+	errorx.ErrCodeNotFound:      http.StatusBadRequest,
+	errorx.ErrCodeAlreadyExists: http.StatusConflict,
+	errorx.ErrCodeAlreadyUsed:   http.StatusBadRequest,
+	errorx.ErrCodeExpired:       http.StatusBadRequest,
+
+	// Employee
+	errorx.ErrEmployeeNotFound: http.StatusNotFound,
+
+	// Idempotency
+	errorx.ErrIdempotencyKeyInvalid:        http.StatusBadRequest,
+	errorx.ErrIdempotencyKeyReused:         http.StatusUnprocessableEntity,
+	errorx.ErrIdempotencyRequestInProgress: http.StatusConflict,
+}
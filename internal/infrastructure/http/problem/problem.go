@@ -0,0 +1,147 @@
+// Package problem turns errors into HTTP error responses. Domain errors are mapped to a status
+// in a single table, their messages are translated from the error.<code> catalog keys, and responses
+// are rendered either as the standard Body envelope or as RFC 9457 problem details, depending on
+// the Accept header.
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/arfanxn/welding/pkg/httperror"
+	"github.com/gin-gonic/gin"
+)
+
+// TypeURIPrefix prefixes the stable error code to form the problem type URI
+const TypeURIPrefix = "urn:welding:problem:"
+
+// Details overrides the default message key of mapped errors for a single endpoint,
+// e.g. error.code.invitation_already_used rather than the generic error.code.already_used
+type Details map[errorx.Errorx]string
+
+// codesByStatus are the stable codes of errors that are not raised from an errorx.Errorx
+var codesByStatus = map[int]string{
+	http.StatusBadRequest:            "request.invalid",
+	http.StatusUnauthorized:          "auth.unauthenticated",
+	http.StatusForbidden:             "auth.forbidden",
+	http.StatusNotFound:              "route.not_found",
+	http.StatusMethodNotAllowed:      "route.method_not_allowed",
+	http.StatusConflict:              "request.conflict",
+	http.StatusUnprocessableEntity:   "request.validation_failed",
+	http.StatusTooManyRequests:       "request.rate_limited",
+	http.StatusInternalServerError:   "server.internal_error",
+	http.StatusServiceUnavailable:    "server.unavailable",
+	http.StatusRequestEntityTooLarge: "request.too_large",
+}
+
+// FromError converts a domain error, possibly wrapped, into an HttpError using the central mapping.
+// It returns false for errors without a mapping, which should be treated as internal errors.
+func FromError(err error, details Details) (*httperror.HttpError, bool) {
+	var httpErr *httperror.HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+
+	var domainErr errorx.Errorx
+	if !errors.As(err, &domainErr) {
+		return nil, false
+	}
+
+	status, ok := statuses[domainErr]
+	if !ok {
+		return nil, false
+	}
+
+	message := MessageKey(domainErr.Code())
+	if detail, ok := details[domainErr]; ok {
+		message = detail
+	}
+	return httperror.New(status, message, nil).WithErrorCode(domainErr.Code()), true
+}
+
+// Panic panics with the HttpError mapped from err, so the recovery middleware renders it.
+// Errors without a mapping are re-panicked unchanged and answered as internal errors.
+func Panic(err error, details Details) {
+	if httpErr, ok := FromError(err, details); ok {
+		panic(httpErr)
+	}
+	panic(err)
+}
+
+// Render aborts the request with the error, as problem details when the client prefers
+// application/problem+json and as the standard Body envelope otherwise.
+// The message of the error is translated when it is a message key.
+func Render(c *gin.Context, err *httperror.HttpError) {
+	errorCode := CodeOf(err)
+	message := helper.T(c, err.Message, nil)
+
+	if c.NegotiateFormat(gin.MIMEJSON, response.ProblemContentType) != response.ProblemContentType {
+		body := response.NewBodyWithErrors(err.Code, message, err.Errors)
+		body.ErrorCode = errorCode
+		c.AbortWithStatusJSON(err.Code, body)
+		return
+	}
+
+	c.Header("Content-Type", response.ProblemContentType)
+	c.AbortWithStatusJSON(err.Code, &response.Problem{
+		Type:      TypeURIPrefix + errorCode,
+		Title:     titleOf(c, err, errorCode),
+		Status:    err.Code,
+		Detail:    message,
+		Instance:  c.Request.URL.Path,
+		Code:      errorCode,
+		RequestId: c.GetString(contextkey.RequestIdKey),
+		Errors:    err.Errors,
+	})
+}
+
+// CodeOf returns the stable code of the error, falling back to a code derived from its status
+func CodeOf(err *httperror.HttpError) string {
+	if err.ErrorCode != "" {
+		return err.ErrorCode
+	}
+	if code, ok := codesByStatus[err.Code]; ok {
+		return code
+	}
+	return "http." + strconv.Itoa(err.Code)
+}
+
+// MessageKey returns the catalog key of the default message of an error code
+func MessageKey(errorCode string) string {
+	return "error." + errorCode
+}
+
+// CheckTranslations reports every domain error without a status and every error code without a
+// message in the catalogs, so an incomplete mapping stops the application at startup
+func CheckTranslations(translator i18n.Translator) error {
+	var problems []error
+	for _, domainErr := range errorx.All() {
+		if _, ok := statuses[domainErr]; !ok {
+			problems = append(problems, fmt.Errorf("problem: error %s has no HTTP status", domainErr.Code()))
+		}
+		if !translator.Has(MessageKey(domainErr.Code())) {
+			problems = append(problems, fmt.Errorf("problem: error %s has no message %s", domainErr.Code(), MessageKey(domainErr.Code())))
+		}
+	}
+	for _, errorCode := range codesByStatus {
+		if !translator.Has(MessageKey(errorCode)) {
+			problems = append(problems, fmt.Errorf("problem: error %s has no message %s", errorCode, MessageKey(errorCode)))
+		}
+	}
+	return errors.Join(problems...)
+}
+
+// titleOf returns the title of the problem type, which unlike the detail does not vary between occurrences
+func titleOf(c *gin.Context, err *httperror.HttpError, errorCode string) string {
+	if translator, ok := helper.TranslatorFromC(c); ok && translator.Has(MessageKey(errorCode)) {
+		return helper.T(c, MessageKey(errorCode), nil)
+	}
+	return http.StatusText(err.Code)
+}
@@ -23,6 +23,8 @@ type Body struct {
 	Status string `json:"status"`
 	// Message provides a human-readable message about the operation result
 	Message string `json:"message"`
+	// ErrorCode is the stable, machine readable code of an error, e.g. code.already_used
+	ErrorCode string `json:"error_code,omitempty"`
 	// Errors contains validation or business logic error details, if any
 	Errors map[string][]string `json:"errors,omitempty"`
 	// Data contains the response payload, if any
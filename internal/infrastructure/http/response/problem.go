@@ -0,0 +1,25 @@
+package response
+
+// ProblemContentType is the media type of RFC 9457 problem details
+const ProblemContentType = "application/problem+json"
+
+// Problem represents an RFC 9457 problem details response. It is sent instead of Body
+// when the client prefers application/problem+json in its Accept header.
+type Problem struct {
+	// Type is a URI reference identifying the problem type, derived from Code
+	Type string `json:"type"`
+	// Title is a short summary of the problem type, the same for every occurrence
+	Title string `json:"title"`
+	// Status is the HTTP status code
+	Status int `json:"status"`
+	// Detail explains this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that caused the problem
+	Instance string `json:"instance,omitempty"`
+	// Code is the stable, machine readable code of the problem, e.g. code.already_used
+	Code string `json:"code"`
+	// RequestId correlates the problem with the server logs
+	RequestId string `json:"request_id,omitempty"`
+	// Errors contains the validation errors per field, if any
+	Errors map[string][]string `json:"errors,omitempty"`
+}
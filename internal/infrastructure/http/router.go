@@ -1,11 +1,26 @@
 package http
 
 import (
+	"net/http"
+
 	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
+	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
 )
 
-func NewRouterFromConfig(cfg *config.Config) *gin.Engine {
-	r := gin.Default()
-	return r
+// NewRouterFromConfig creates a bare Gin engine.
+// Logging and panic recovery are registered in RegisterRoutes through the zap based
+// access log and recovery middlewares instead of Gin's default text logger.
+// X-Forwarded-For and X-Real-IP are only honored for requests coming from TRUSTED_PROXIES.
+// Unknown routes are answered like any other error instead of Gin's plain text 404.
+func NewRouterFromConfig(cfg *config.Config) (*gin.Engine, error) {
+	r := gin.New()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, err
+	}
+	r.NoRoute(func(c *gin.Context) {
+		problem.Render(c, httperror.New(http.StatusNotFound, "error.route.not_found", nil))
+	})
+	return r, nil
 }
@@ -1,17 +1,22 @@
 package http
 
 import (
-	"net/http"
-
-	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/health"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	loggerHttp "github.com/arfanxn/welding/internal/infrastructure/logger/presentation/http"
 	"github.com/arfanxn/welding/internal/infrastructure/middleware"
+	auditLogHttp "github.com/arfanxn/welding/internal/module/audit_log/presentation/http"
 	codeHttp "github.com/arfanxn/welding/internal/module/code/presentation/http"
 	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	permissionHttp "github.com/arfanxn/welding/internal/module/permission/presentation/http"
 	roleHttp "github.com/arfanxn/welding/internal/module/role/presentation/http"
 	userHttp "github.com/arfanxn/welding/internal/module/user/presentation/http"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/fx"
 )
 
@@ -20,35 +25,77 @@ type RegisterRoutesParams struct {
 
 	// Router
 	Router gin.IRouter
+	// RouteRequirements records the requirement of every authorized route
+	RouteRequirements openapi.RouteRequirements
 
 	// Utilities
-	Logger *logger.Logger
+	Config         *config.Config
+	Logger         *logger.Logger
+	TracerProvider trace.TracerProvider
 
 	// Middlewares
+	RequestIdMiddleware         middleware.RequestIdMiddleware
+	TrustedProxyMiddleware      middleware.TrustedProxyMiddleware
+	LocaleMiddleware            middleware.LocaleMiddleware
+	SecurityHeadersMiddleware   middleware.SecurityHeadersMiddleware
+	CORSMiddleware              middleware.CORSMiddleware
+	AccessLogMiddleware         middleware.AccessLogMiddleware
+	RequestLogMiddleware        middleware.RequestLogMiddleware
 	HttpErrorRecoveryMiddleware middleware.HttpErrorRecoveryMiddleware
+	MetricsMiddleware           middleware.MetricsMiddleware
 	RateLimiterMiddleware       middleware.RateLimiterMiddleware
 	AuthenticateMiddleware      middleware.AuthenticateMiddleware
 	AuthorizeMiddleware         middleware.AuthorizeMiddleware
 	UserActiveMiddleware        middleware.UserActiveMiddleware
 	UserEmailVerifiedMiddleware middleware.UserEmailVerifiedMiddleware
+	IdempotencyMiddleware       middleware.IdempotencyMiddleware
+	PreconditionMiddleware      middleware.PreconditionMiddleware
 
 	// Handlers
+	HealthHandler     health.HealthHandler
+	OpenAPIHandler    openapi.OpenAPIHandler
+	LogLevelHandler   loggerHttp.LogLevelHandler
 	UserHandler       userHttp.UserHandler
 	RoleHandler       roleHttp.RoleHandler
 	PermissionHandler permissionHttp.PermissionHandler
 	CodeHandler       codeHttp.CodeHandler
+	AuditLogHandler   auditLogHttp.AuditLogHandler
 }
 
 func RegisterRoutes(params RegisterRoutesParams) error {
+	// Global middlewares
+	params.Router.Use(
+		params.RequestIdMiddleware.MiddlewareFunc(),
+		params.TrustedProxyMiddleware.MiddlewareFunc(),
+		params.LocaleMiddleware.MiddlewareFunc(),
+		otelgin.Middleware(params.Config.AppName, otelgin.WithTracerProvider(params.TracerProvider)),
+		params.AccessLogMiddleware.MiddlewareFunc(),
+		params.MetricsMiddleware.MiddlewareFunc(),
+		// Before the recovery, so it sees the status of the errors the handlers panic with
+		params.RequestLogMiddleware.MiddlewareFunc(),
+		params.HttpErrorRecoveryMiddleware.MiddlewareFunc(),
+		params.SecurityHeadersMiddleware.MiddlewareFunc(),
+		params.CORSMiddleware.MiddlewareFunc(),
+	)
+
+	// Probes (kept outside the API group so they are never rate limited)
+	params.Router.GET("/livez", params.HealthHandler.Livez)
+	params.Router.GET("/readyz", params.HealthHandler.Readyz)
+
 	// API v1
-	apiV1 := params.Router.Group("/api/v1")
+	apiV1 := params.Router.Group(APIPrefix)
 	apiV1.Use(
-		params.HttpErrorRecoveryMiddleware.MiddlewareFunc(),
 		params.RateLimiterMiddleware.MiddlewareFunc(),
 	)
-	apiV1.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, response.NewBody(http.StatusOK, "OK"))
-	})
+	apiV1.GET("/health", params.HealthHandler.Readyz)
+
+	// Documentation
+	apiV1.GET("/openapi.json", params.OpenAPIHandler.Spec)
+	apiV1.GET("/docs", params.OpenAPIHandler.UI)
+	apiV1.GET("/docs/:name", params.OpenAPIHandler.Asset)
+
+	// Creations are safe to retry with an Idempotency-Key
+	idempotent := params.IdempotencyMiddleware.MiddlewareFunc()
 	{
 		// --------------------------------------------------
 		// Public routes
@@ -62,15 +109,21 @@ func RegisterRoutes(params RegisterRoutesParams) error {
 		user.PATCH("/reset-password", params.UserHandler.ResetPassword)
 
 		code := apiV1.Group("/codes")
-		code.POST("/user-email-verification", params.CodeHandler.CreateUserEmailVerification)
-		code.POST("/user-reset-password", params.CodeHandler.CreateUserResetPassword)
+		code.POST("/user-email-verification", idempotent, params.CodeHandler.CreateUserEmailVerification)
+		code.POST("/user-reset-password", idempotent, params.CodeHandler.CreateUserResetPassword)
 	}
 	{
 		// --------------------------------------------------
 		// Protected routes
 		// --------------------------------------------------
 
-		requirePermissionName := params.AuthorizeMiddleware.RequirePermissionNames
+		// Routes registered through authorized record their requirement for the OpenAPI document
+		authorized := func(group *gin.RouterGroup) *authorizedGroup {
+			return newAuthorizedGroup(group, params.AuthorizeMiddleware, params.RouteRequirements)
+		}
+		permission := authz.AllPermissions
+		// Modifications of versioned resources honour If-Match
+		precondition := params.PreconditionMiddleware.MiddlewareFunc()
 
 		protected := apiV1.Group("")
 		protected.Use(
@@ -90,32 +143,43 @@ func RegisterRoutes(params RegisterRoutesParams) error {
 		user.PATCH("/me/password", params.UserHandler.UpdateMePassword)
 
 		// Users
-		user.GET("", requirePermissionName(permissionEnum.UsersIndex), params.UserHandler.Paginate)
-		user.GET("/:id", requirePermissionName(permissionEnum.UsersShow), params.UserHandler.Show)
-		user.POST("", requirePermissionName(permissionEnum.UsersStore), params.UserHandler.Store)
-		user.PUT("/:id", requirePermissionName(permissionEnum.UsersUpdate), params.UserHandler.Update)
+		users := authorized(user)
+		users.GET("", permission(permissionEnum.UsersIndex), params.UserHandler.Paginate)
+		// Users read and update themselves, the user policy limits the others to their department
+		users.GET("/:id", authz.MustParse("users.show OR owner"), params.UserHandler.Show)
+		users.POST("", permission(permissionEnum.UsersStore), idempotent, params.UserHandler.Store)
+		users.PUT("/:id", authz.MustParse("users.update OR owner"), precondition, params.UserHandler.Update)
 		// ! Deprecated
-		// user.PATCH("/:id/password", requirePermissionName(permissionEnum.UsersUpdate), params.UserHandler.UpdatePassword)
-		user.PATCH("/:id/activation/toggle", requirePermissionName(permissionEnum.UsersUpdate), params.UserHandler.ToggleActivation)
-		user.DELETE("/:id", requirePermissionName(permissionEnum.UsersDestroy), params.UserHandler.Destroy)
+		// users.PATCH("/:id/password", permission(permissionEnum.UsersUpdate), params.UserHandler.UpdatePassword)
+		users.PATCH("/:id/activation/toggle", permission(permissionEnum.UsersUpdate), precondition, params.UserHandler.ToggleActivation)
+		users.POST("/:id/role-grants", permission(permissionEnum.UsersUpdate), idempotent, params.UserHandler.GrantRole)
+		users.DELETE("/:id", permission(permissionEnum.UsersDestroy), precondition, params.UserHandler.Destroy)
 
 		// Roles
-		role := protected.Group("/roles")
-		role.GET("", requirePermissionName(permissionEnum.RolesIndex), params.RoleHandler.Paginate)
-		role.GET("/:id", requirePermissionName(permissionEnum.RolesShow), params.RoleHandler.Show)
-		role.POST("", requirePermissionName(permissionEnum.RolesStore), params.RoleHandler.Store)
-		role.PUT("/:id", requirePermissionName(permissionEnum.RolesUpdate), params.RoleHandler.Update)
-		role.PATCH("/:id/set-default", requirePermissionName(permissionEnum.RolesUpdate), params.RoleHandler.SetDefault)
-		role.DELETE("/:id", requirePermissionName(permissionEnum.RolesDestroy), params.RoleHandler.Destroy)
+		role := authorized(protected.Group("/roles"))
+		role.GET("", permission(permissionEnum.RolesIndex), params.RoleHandler.Paginate)
+		role.GET("/:id", permission(permissionEnum.RolesShow), params.RoleHandler.Show)
+		role.POST("", permission(permissionEnum.RolesStore), idempotent, params.RoleHandler.Store)
+		role.PUT("/:id", permission(permissionEnum.RolesUpdate), precondition, params.RoleHandler.Update)
+		role.PATCH("/:id/set-default", permission(permissionEnum.RolesUpdate), precondition, params.RoleHandler.SetDefault)
+		role.DELETE("/:id", permission(permissionEnum.RolesDestroy), precondition, params.RoleHandler.Destroy)
 
 		// Permissions
-		permission := protected.Group("/permissions")
-		permission.GET("", requirePermissionName(permissionEnum.PermissionsIndex), params.PermissionHandler.Paginate)
+		permissions := authorized(protected.Group("/permissions"))
+		permissions.GET("", permission(permissionEnum.PermissionsIndex), params.PermissionHandler.Paginate)
 
 		// Codes
-		code := protected.Group("/codes")
-		code.POST("/user-register-invitation", requirePermissionName(permissionEnum.UsersStore), params.CodeHandler.CreateUserRegisterInvitation)
+		code := authorized(protected.Group("/codes"))
+		code.POST("/user-register-invitation", permission(permissionEnum.InvitationsStore), idempotent, params.CodeHandler.CreateUserRegisterInvitation)
+
+		// Logs
+		log := authorized(protected.Group("/logs"))
+		log.GET("/level", permission(permissionEnum.LogsShow), params.LogLevelHandler.Show)
+		log.PUT("/level", permission(permissionEnum.LogsUpdate), params.LogLevelHandler.Update)
 
+		// Audit logs
+		auditLog := authorized(protected.Group("/audit-logs"))
+		auditLog.GET("", permission(permissionEnum.AuditLogsIndex), params.AuditLogHandler.Paginate)
 	}
 
 	return nil
@@ -0,0 +1,82 @@
+package http_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/infrastructure/di"
+	"github.com/arfanxn/welding/internal/infrastructure/http"
+	"github.com/arfanxn/welding/internal/infrastructure/http/openapi"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/fx"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// registerRoutes registers the routes of the application the way openapi:export does, the databases
+// never being dialed, and returns the engine and the requirements recorded on registration
+func registerRoutes(t *testing.T) (*gin.Engine, openapi.RouteRequirements) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	t.Setenv("POSTGRES_DSN", "postgres://welding@localhost/welding")
+	t.Setenv("JWT_SECRET", "secret")
+	t.Setenv("MAIL_HOST", "localhost")
+	t.Setenv("MAIL_FROM_ADDRESS", "welding@example.com")
+	t.Setenv("LOG_FILEPATH", filepath.Join(t.TempDir(), "application.log"))
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(postgres.Open(cfg.PostgresDSN), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		engine       *gin.Engine
+		requirements openapi.RouteRequirements
+	)
+	app := fx.New(
+		fx.NopLogger,
+		di.Module,
+		fx.Replace(cfg, db, database.ReadDB{DB: db}, database.MongoDB{}),
+		fx.Populate(&engine, &requirements),
+	)
+	if err := app.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return engine, requirements
+}
+
+func TestRouteDocsCoverRegisteredRoutes(t *testing.T) {
+	engine, requirements := registerRoutes(t)
+
+	registered := map[string]bool{}
+	for _, route := range engine.Routes() {
+		key := route.Method + " " + route.Path
+		registered[key] = true
+
+		doc, ok := http.RouteDocs[key]
+		if !ok {
+			t.Errorf("route %s is registered without routeDocs", key)
+			continue
+		}
+		if requirement, ok := requirements[key]; ok && doc.Public {
+			t.Errorf("route %s requires %q but is documented as public", key, requirement)
+		}
+	}
+
+	for key := range http.RouteDocs {
+		if !registered[key] {
+			t.Errorf("routeDocs documents %s, which is not registered", key)
+		}
+	}
+	for key := range requirements {
+		if !registered[key] {
+			t.Errorf("requirement recorded for %s, which is not registered", key)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Package i18n translates API messages. Messages live in one JSON catalog per locale, embedded in
+// the binary, and are text/template strings rendered with the params of the message.
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"golang.org/x/text/language"
+)
+
+const (
+	// LocaleId is Indonesian, the default locale
+	LocaleId = "id"
+	// LocaleEn is English
+	LocaleEn = "en"
+)
+
+// Locales lists every supported locale, each one has a catalog in the locales directory
+var Locales = []string{LocaleId, LocaleEn}
+
+//go:embed locales/*.json
+var catalogFS embed.FS
+
+type Translator interface {
+	// Translate renders the message of key in locale with params.
+	// A key without a message is returned as is, so already translated text passes through unchanged.
+	Translate(locale string, key string, params map[string]any) string
+	// Has reports whether key has a message
+	Has(key string) bool
+	// Negotiate picks the supported locale that best matches an Accept-Language header,
+	// falling back to the default locale
+	Negotiate(acceptLanguage string) string
+	// IsSupported reports whether locale has a catalog
+	IsSupported(locale string) bool
+}
+
+type translator struct {
+	defaultLocale string
+	// locales are the loaded locales in the order of the matcher tags, default locale first
+	locales  []string
+	catalogs map[string]map[string]*template.Template
+	matcher  language.Matcher
+}
+
+// NewTranslatorFromConfig loads the embedded catalogs. It fails when a catalog cannot be parsed
+// or when the catalogs do not define exactly the same keys, so a missing translation stops startup.
+func NewTranslatorFromConfig(cfg *config.Config) (Translator, error) {
+	t := &translator{
+		defaultLocale: cfg.DefaultLocale,
+		catalogs:      make(map[string]map[string]*template.Template, len(Locales)),
+	}
+
+	tags := make([]language.Tag, 0, len(Locales))
+	// The default locale goes first so it wins when nothing matches
+	for _, locale := range append([]string{cfg.DefaultLocale}, Locales...) {
+		if _, ok := t.catalogs[locale]; ok {
+			continue
+		}
+		catalog, err := loadCatalog(locale)
+		if err != nil {
+			return nil, err
+		}
+		t.catalogs[locale] = catalog
+		t.locales = append(t.locales, locale)
+		tags = append(tags, language.Make(locale))
+	}
+	t.matcher = language.NewMatcher(tags)
+
+	if missing := t.missingKeys(); len(missing) > 0 {
+		return nil, fmt.Errorf("i18n: missing translations: %s", strings.Join(missing, ", "))
+	}
+
+	return t, nil
+}
+
+// loadCatalog reads and parses the catalog of a locale
+func loadCatalog(locale string) (map[string]*template.Template, error) {
+	content, err := catalogFS.ReadFile(path.Join("locales", locale+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("i18n: catalog of locale %q: %w", locale, err)
+	}
+
+	var messages map[string]string
+	if err := json.Unmarshal(content, &messages); err != nil {
+		return nil, fmt.Errorf("i18n: catalog of locale %q: %w", locale, err)
+	}
+
+	catalog := make(map[string]*template.Template, len(messages))
+	for key, message := range messages {
+		tmpl, err := template.New(key).Option("missingkey=zero").Parse(message)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: message %q of locale %q: %w", key, locale, err)
+		}
+		catalog[key] = tmpl
+	}
+	return catalog, nil
+}
+
+// missingKeys lists, as locale:key, every key defined in one catalog but not in another
+func (t *translator) missingKeys() []string {
+	keys := map[string]struct{}{}
+	for _, catalog := range t.catalogs {
+		for key := range catalog {
+			keys[key] = struct{}{}
+		}
+	}
+
+	missing := []string{}
+	for locale, catalog := range t.catalogs {
+		for key := range keys {
+			if _, ok := catalog[key]; !ok {
+				missing = append(missing, locale+":"+key)
+			}
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+func (t *translator) Translate(locale string, key string, params map[string]any) string {
+	catalog, ok := t.catalogs[locale]
+	if !ok {
+		catalog = t.catalogs[t.defaultLocale]
+	}
+
+	tmpl, ok := catalog[key]
+	if !ok {
+		return key
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return key
+	}
+	return buf.String()
+}
+
+func (t *translator) Has(key string) bool {
+	_, ok := t.catalogs[t.defaultLocale][key]
+	return ok
+}
+
+func (t *translator) Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return t.defaultLocale
+	}
+
+	_, index, confidence := t.matcher.Match(tags...)
+	if confidence == language.No {
+		return t.defaultLocale
+	}
+
+	return t.locales[index]
+}
+
+func (t *translator) IsSupported(locale string) bool {
+	_, ok := t.catalogs[locale]
+	return ok
+}
@@ -0,0 +1,98 @@
+package i18n_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
+)
+
+// sourceRoot is the directory whose Go files are scanned for message keys, relative to this package
+const sourceRoot = "../.."
+
+// messageKeyPattern matches the string literals used as message keys
+var messageKeyPattern = regexp.MustCompile(`^(message|error|mail)(\.[a-z0-9_]+)+$`)
+
+func TestCatalogsHaveEveryKeyUsedInCode(t *testing.T) {
+	keys := referencedMessageKeys(t)
+	if len(keys) == 0 {
+		t.Fatalf("no message key found under %s", sourceRoot)
+	}
+
+	for _, locale := range i18n.Locales {
+		t.Run(locale, func(t *testing.T) {
+			// The default locale is the catalog Has looks keys up in
+			translator, err := i18n.NewTranslatorFromConfig(&config.Config{DefaultLocale: locale})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, key := range keys {
+				if !translator.Has(key) {
+					t.Errorf("missing key %s", key)
+				}
+			}
+			if err := problem.CheckTranslations(translator); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// referencedMessageKeys returns the message keys written as string literals in the Go files of sourceRoot,
+// leaving out the arguments of the otel attribute constructors, which are span attribute names
+func referencedMessageKeys(t *testing.T) []string {
+	t.Helper()
+
+	keys := map[string]struct{}{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(sourceRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				if selector, ok := node.Fun.(*ast.SelectorExpr); ok {
+					if pkg, ok := selector.X.(*ast.Ident); ok && pkg.Name == "attribute" {
+						return false
+					}
+				}
+			case *ast.BasicLit:
+				if node.Kind != token.STRING {
+					return true
+				}
+				if value, err := strconv.Unquote(node.Value); err == nil && messageKeyPattern.MatchString(value) {
+					keys[value] = struct{}{}
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lockAttempts bounds the retries of Lock when the existing record disappears or is taken over concurrently
+const lockAttempts = 3
+
+var _ Store = (*gormStore)(nil)
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{
+		db: db,
+	}
+}
+
+func (s *gormStore) Lock(ctx context.Context, record *Record, staleBefore time.Time) (*Record, bool, error) {
+	db := s.db.WithContext(ctx)
+
+	for range lockAttempts {
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return record, true, nil
+		}
+
+		var existing Record
+		if err := db.Where("scope = ? AND idempotency_key = ?", record.Scope, record.Key).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				// Deleted in between, try inserting again
+				continue
+			}
+			return nil, false, err
+		}
+
+		expired := !existing.ExpiresAt.After(record.LockedAt)
+		stale := !existing.IsCompleted() && existing.LockedAt.Before(staleBefore)
+		if !expired && !stale {
+			return &existing, false, nil
+		}
+
+		// Take over only if nobody else did since it was read
+		result = db.Model(&Record{}).
+			Where("scope = ? AND idempotency_key = ? AND locked_at = ?", existing.Scope, existing.Key, existing.LockedAt).
+			Updates(map[string]any{
+				"fingerprint":   record.Fingerprint,
+				"status_code":   nil,
+				"content_type":  "",
+				"location":      "",
+				"etag":          "",
+				"response_body": nil,
+				"locked_at":     record.LockedAt,
+				"completed_at":  nil,
+				"expires_at":    record.ExpiresAt,
+				"created_at":    record.CreatedAt,
+			})
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return record, true, nil
+		}
+	}
+
+	return nil, false, errors.New("idempotency: failed to lock key " + record.Key)
+}
+
+func (s *gormStore) Complete(ctx context.Context, record *Record) error {
+	return s.db.WithContext(ctx).Model(&Record{}).
+		Where("scope = ? AND idempotency_key = ? AND locked_at = ?", record.Scope, record.Key, record.LockedAt).
+		Updates(map[string]any{
+			"status_code":   record.StatusCode,
+			"content_type":  record.ContentType,
+			"location":      record.Location,
+			"etag":          record.ETag,
+			"response_body": record.ResponseBody,
+			"completed_at":  record.CompletedAt,
+		}).Error
+}
+
+func (s *gormStore) Unlock(ctx context.Context, record *Record) error {
+	return s.db.WithContext(ctx).
+		Where("scope = ? AND idempotency_key = ? AND locked_at = ?", record.Scope, record.Key, record.LockedAt).
+		Delete(&Record{}).Error
+}
+
+func (s *gormStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
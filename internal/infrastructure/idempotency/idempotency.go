@@ -0,0 +1,120 @@
+// Package idempotency stores the first response of a request sent with an Idempotency-Key
+// so that retries of the same request are answered with it instead of being executed again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/guregu/null/v6"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderKey is the request header carrying the client generated key
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses replayed from a stored record
+	HeaderReplayed = "Idempotent-Replayed"
+	// MaxKeyLength is the maximum length of an Idempotency-Key
+	MaxKeyLength = 255
+)
+
+// Record is the stored state of an Idempotency-Key within a scope (the authenticated user or the client IP).
+// A record without CompletedAt is in flight and acts as a lock held by the first request.
+type Record struct {
+	Scope        string    `gorm:"primaryKey"`
+	Key          string    `gorm:"column:idempotency_key;primaryKey"`
+	Fingerprint  string    `gorm:"column:fingerprint"`
+	StatusCode   null.Int  `gorm:"column:status_code"`
+	ContentType  string    `gorm:"column:content_type"`
+	Location     string    `gorm:"column:location"`
+	ETag         string    `gorm:"column:etag"`
+	ResponseBody []byte    `gorm:"column:response_body"`
+	LockedAt     time.Time `gorm:"column:locked_at"`
+	CompletedAt  null.Time `gorm:"column:completed_at"`
+	ExpiresAt    time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at"`
+}
+
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// IsCompleted reports whether the response of the record has been stored
+func (r *Record) IsCompleted() bool {
+	return r.CompletedAt.Valid
+}
+
+// Store persists idempotency records
+type Store interface {
+	// Lock inserts record as in flight. When the key already exists it is taken over only if
+	// the existing record expired or its lock is older than staleBefore, otherwise the existing
+	// record is returned with false.
+	Lock(ctx context.Context, record *Record, staleBefore time.Time) (*Record, bool, error)
+	// Complete stores the response of a locked record
+	Complete(ctx context.Context, record *Record) error
+	// Unlock deletes a locked record so the key can be used again
+	Unlock(ctx context.Context, record *Record) error
+	// DeleteExpired deletes the records expired before now and returns how many were deleted
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// Fingerprint identifies a request by its method, path, query and body so that a key
+// reused for a different request can be told apart from a retry
+func Fingerprint(method string, path string, rawQuery string, body []byte) string {
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(method), []byte(path), []byte(rawQuery)} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// RegisterCleanup periodically deletes expired records while the application is running
+func RegisterCleanup(lc fx.Lifecycle, cfg *config.Config, store Store, logger *logger.Logger) {
+	if cfg.IdempotencyCleanupInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.IdempotencyCleanupInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case now := <-ticker.C:
+						deleted, err := store.DeleteExpired(ctx, now)
+						if err != nil {
+							logger.Warn("Failed to delete expired idempotency keys", zap.Error(err))
+							continue
+						}
+						if deleted > 0 {
+							logger.Debug("Deleted expired idempotency keys", zap.Int64("count", deleted))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
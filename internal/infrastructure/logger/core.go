@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue replaces the value of every sensitive field
+const RedactedValue = "[REDACTED]"
+
+// SensitiveKeys lists the field keys (case-insensitive) whose values are never written to the logs.
+// Keys ending with one of SensitiveKeySuffixes are redacted as well.
+var SensitiveKeys = []string{
+	"password",
+	"password_confirmation",
+	"token",
+	"authorization",
+	"secret",
+	"code",
+	"otp",
+}
+
+var SensitiveKeySuffixes = []string{
+	"_password",
+	"_token",
+	"_secret",
+	"_code",
+}
+
+// redactCore is a zapcore.Core that replaces the value of sensitive fields with RedactedValue.
+// It must wrap leaf cores, since the fields of an entry are handed directly to the cores
+// registered on the checked entry.
+type redactCore struct {
+	zapcore.Core
+}
+
+func newRedactCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := fields
+	copied := false
+	for i, field := range fields {
+		if !isSensitiveKey(field.Key) {
+			continue
+		}
+		// Copy lazily so the caller's slice is never mutated
+		if !copied {
+			redacted = append([]zapcore.Field(nil), fields...)
+			copied = true
+		}
+		redacted[i] = zap.String(field.Key, RedactedValue)
+	}
+	return redacted
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range SensitiveKeys {
+		if key == sensitiveKey {
+			return true
+		}
+	}
+	for _, suffix := range SensitiveKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// debugSamplerCore samples debug entries only, entries of any other level always reach the core.
+type debugSamplerCore struct {
+	zapcore.Core
+	sampled zapcore.Core
+}
+
+func newDebugSamplerCore(core zapcore.Core, initial int, thereafter int) zapcore.Core {
+	return &debugSamplerCore{
+		Core:    core,
+		sampled: zapcore.NewSamplerWithOptions(core, defaultSamplingTick, initial, thereafter),
+	}
+}
+
+func (c *debugSamplerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugSamplerCore{
+		Core:    c.Core.With(fields),
+		sampled: c.sampled.With(fields),
+	}
+}
+
+func (c *debugSamplerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level == zapcore.DebugLevel {
+		return c.sampled.Check(ent, ce)
+	}
+	return c.Core.Check(ent, ce)
+}
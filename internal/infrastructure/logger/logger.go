@@ -1,16 +1,44 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Names of the logger outputs whose level can be configured and changed at runtime
+const (
+	OutputConsole = "console"
+	OutputFile    = "file"
+)
+
+// Defaults used when the corresponding LOG_* variable is not configured
+const (
+	defaultLevel              = zapcore.InfoLevel
+	defaultMaxSizeMB          = 100
+	defaultMaxAgeDays         = 30
+	defaultMaxBackups         = 10
+	defaultSamplingTick       = time.Second
+	defaultSamplingInitial    = 100
+	defaultSamplingThereafter = 100
 )
 
 type Logger struct {
 	*zap.Logger
+
+	// levels holds the atomic level of every output, keyed by output name.
+	// It is only set on the root logger built by NewLoggerFromConfig.
+	levels map[string]zap.AtomicLevel
 }
 
 // NewLoggerFromConfig creates a new logger that writes to both console and file
@@ -22,42 +50,64 @@ func NewLoggerFromConfig(cfg *config.Config) (*Logger, error) {
 		return nil, err
 	}
 
-	// Create or open the log file
-	logFile, err := os.OpenFile(
-		cfg.LogFilepath,
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		0644,
-	)
+	// Resolve the level of each output, LOG_CONSOLE_LEVEL and LOG_FILE_LEVEL override LOG_LEVEL
+	baseLevel, err := parseLevel(cfg.LogLevel, defaultLevel)
+	if err != nil {
+		return nil, err
+	}
+	consoleLevel, err := parseLevel(cfg.LogConsoleLevel, baseLevel)
+	if err != nil {
+		return nil, err
+	}
+	fileLevel, err := parseLevel(cfg.LogFileLevel, baseLevel)
 	if err != nil {
 		return nil, err
 	}
+	levels := map[string]zap.AtomicLevel{
+		OutputConsole: zap.NewAtomicLevelAt(consoleLevel),
+		OutputFile:    zap.NewAtomicLevelAt(fileLevel),
+	}
 
 	// Configure encoder
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	// Create file write syncer
-	fileSyncer := zapcore.AddSync(logFile)
+	// Create a rotating file write syncer, old files are rotated by size and removed by age/count
+	fileSyncer := zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.LogFilepath,
+		MaxSize:    valueOrDefault(cfg.LogMaxSize, defaultMaxSizeMB),
+		MaxAge:     valueOrDefault(cfg.LogMaxAge, defaultMaxAgeDays),
+		MaxBackups: valueOrDefault(cfg.LogMaxBackups, defaultMaxBackups),
+		Compress:   cfg.LogCompress,
+		LocalTime:  true,
+	})
 
-	// Create core for file logging
-	fileCore := zapcore.NewCore(
+	// Create core for file logging, sensitive fields are redacted before being written
+	fileCore := newRedactCore(zapcore.NewCore(
 		zapcore.NewJSONEncoder(encoderConfig),
-		zapcore.NewMultiWriteSyncer(fileSyncer),
-		zap.InfoLevel,
-	)
+		fileSyncer,
+		levels[OutputFile],
+	))
 
-	// Create core for console logging
-	consoleCore := zapcore.NewCore(
+	// Create core for console logging, sensitive fields are redacted before being written
+	consoleCore := newRedactCore(zapcore.NewCore(
 		zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
 		zapcore.AddSync(os.Stdout),
-		zap.InfoLevel,
-	)
+		levels[OutputConsole],
+	))
 
 	// Combine cores
 	core := zapcore.NewTee(
 		fileCore,
 		consoleCore,
 	)
+	if cfg.LogSampling {
+		core = newDebugSamplerCore(
+			core,
+			valueOrDefault(cfg.LogSamplingInitial, defaultSamplingInitial),
+			valueOrDefault(cfg.LogSamplingThereafter, defaultSamplingThereafter),
+		)
+	}
 
 	// Create the logger with a core that writes to both file and console.
 	// The logger is configured with:
@@ -70,6 +120,7 @@ func NewLoggerFromConfig(cfg *config.Config) (*Logger, error) {
 			zap.AddCaller(),
 			zap.AddStacktrace(zapcore.ErrorLevel),
 		),
+		levels: levels,
 	}
 
 	// Ensure logs are written on program exit
@@ -77,3 +128,87 @@ func NewLoggerFromConfig(cfg *config.Config) (*Logger, error) {
 
 	return lgr, nil
 }
+
+// Levels returns the current level of every output, keyed by output name.
+func (l *Logger) Levels() map[string]string {
+	levels := make(map[string]string, len(l.levels))
+	for output, level := range l.levels {
+		levels[output] = level.String()
+	}
+	return levels
+}
+
+// SetLevel changes the level of the given output at runtime.
+// An empty output changes the level of every output.
+func (l *Logger) SetLevel(output string, level string) error {
+	parsedLevel, err := parseLevel(level, defaultLevel)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		for _, atomicLevel := range l.levels {
+			atomicLevel.SetLevel(parsedLevel)
+		}
+		return nil
+	}
+
+	atomicLevel, ok := l.levels[output]
+	if !ok {
+		return fmt.Errorf("unknown log output: %s", output)
+	}
+	atomicLevel.SetLevel(parsedLevel)
+
+	return nil
+}
+
+// WithTrace returns a logger annotated with the trace and span ids of the span found in ctx.
+// The logger is returned unchanged when ctx carries no valid span.
+func (l *Logger) WithTrace(ctx context.Context) *Logger {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return l
+	}
+
+	return &Logger{
+		Logger: l.Logger.With(
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		),
+	}
+}
+
+// NewContext returns a copy of ctx carrying l as the request-scoped logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextkey.LoggerKey, l)
+}
+
+// FromContext returns the request-scoped logger stored in ctx.
+// When ctx carries none, l annotated with the trace of ctx is returned instead,
+// so callers outside of a request (e.g. background jobs) still get a usable logger.
+func (l *Logger) FromContext(ctx context.Context) *Logger {
+	if ctxLogger, ok := ctx.Value(contextkey.LoggerKey).(*Logger); ok && ctxLogger != nil {
+		return ctxLogger
+	}
+	return l.WithTrace(ctx)
+}
+
+// parseLevel parses a level name such as "debug" or "warn", returning fallback for an empty string.
+func parseLevel(level string, fallback zapcore.Level) (zapcore.Level, error) {
+	level = strings.TrimSpace(level)
+	if level == "" {
+		return fallback, nil
+	}
+	parsedLevel, err := zapcore.ParseLevel(strings.ToLower(level))
+	if err != nil {
+		return fallback, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	return parsedLevel, nil
+}
+
+func valueOrDefault(value int, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/infrastructure/logger/presentation/http/request"
+	"github.com/arfanxn/welding/pkg/httperror"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type LogLevelHandler interface {
+	Show(c *gin.Context)
+	Update(c *gin.Context)
+}
+
+type logLevelHandler struct {
+	logger *logger.Logger
+}
+
+func NewLogLevelHandler(logger *logger.Logger) LogLevelHandler {
+	return &logLevelHandler{
+		logger: logger,
+	}
+}
+
+func (h *logLevelHandler) Show(c *gin.Context) {
+	c.JSON(http.StatusOK, response.NewBodyWithData(
+		http.StatusOK,
+		helper.T(c, "message.log.level_shown", nil),
+		h.logger.Levels(),
+	))
+}
+
+func (h *logLevelHandler) Update(c *gin.Context) {
+	req := request.NewUpdateLogLevel()
+	helper.MustBindValidate(c, req)
+
+	if err := h.logger.SetLevel(req.Output, req.Level); err != nil {
+		httperror.Panic(http.StatusUnprocessableEntity, err.Error(), nil)
+	}
+
+	h.logger.FromContext(c.Request.Context()).Warn(
+		"Log level changed",
+		zap.String("output", req.Output),
+		zap.String("level", req.Level),
+	)
+
+	c.JSON(http.StatusOK, response.NewBodyWithData(
+		http.StatusOK,
+		helper.T(c, "message.log.level_updated", nil),
+		h.logger.Levels(),
+	))
+}
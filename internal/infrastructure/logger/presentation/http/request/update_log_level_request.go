@@ -0,0 +1,32 @@
+package request
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/http/request"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var _ request.Request = (*UpdateLogLevel)(nil)
+
+type UpdateLogLevel struct {
+	// Level is the new level, one of debug, info, warn, error, dpanic, panic or fatal
+	Level string `form:"level" json:"level"`
+	// Output is the output to change, empty changes every output
+	Output string `form:"output" json:"output"`
+}
+
+func NewUpdateLogLevel() *UpdateLogLevel {
+	return &UpdateLogLevel{}
+}
+
+func (r *UpdateLogLevel) Validate() error {
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Level,
+			validation.Required,
+			validation.In("debug", "info", "warn", "error", "dpanic", "panic", "fatal"),
+		),
+		validation.Field(&r.Output,
+			validation.In(logger.OutputConsole, logger.OutputFile),
+		),
+	)
+}
@@ -1,15 +1,24 @@
 package mail
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/smtp"
 	"strings"
 
 	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/infrastructure/mail")
+
 type MailService interface {
-	Send(to []string, subject, body string) error
+	Send(ctx context.Context, to []string, subject, body string) error
+	// Ping checks that the mail server is reachable without sending anything
+	Ping(ctx context.Context) error
 }
 
 type smtpMailService struct {
@@ -43,9 +52,47 @@ func (smtpMailService) buildMessage(from, fromName string, recipients []string,
 	)
 }
 
-func (s *smtpMailService) Send(recipients []string, subject, body string) error {
+func (s *smtpMailService) Send(ctx context.Context, recipients []string, subject, body string) error {
+	_, span := tracer.Start(ctx, "MailService.Send")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("mail.host", s.host),
+		attribute.Int("mail.recipients", len(recipients)),
+	)
+
 	auth := smtp.PlainAuth(s.identity, s.username, s.password, s.host)
 	msg := s.buildMessage(s.fromAddress, s.fromName, recipients, subject, body)
 	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-	return smtp.SendMail(addr, auth, s.fromAddress, recipients, msg)
+
+	err := smtp.SendMail(addr, auth, s.fromAddress, recipients, msg)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+func (s *smtpMailService) Ping(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	// Wait for the server greeting to make sure an SMTP server is actually listening
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
 }
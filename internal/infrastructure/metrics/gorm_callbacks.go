@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartedAtKey = "metrics:started_at"
+
+// registerGormCallbacks hooks into every GORM processor to measure statement durations
+func registerGormCallbacks(db *gorm.DB, s MetricsService) error {
+	before := func(db *gorm.DB) {
+		db.InstanceSet(gormStartedAtKey, time.Now())
+	}
+
+	after := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			value, ok := db.InstanceGet(gormStartedAtKey)
+			if !ok {
+				return
+			}
+			startedAt, ok := value.(time.Time)
+			if !ok {
+				return
+			}
+
+			table := db.Statement.Table
+			if table == "" {
+				table = "unknown"
+			}
+
+			s.ObserveDBQuery(operation, table, time.Since(startedAt))
+		}
+	}
+
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range registrations {
+		if err := r.before("metrics:before_"+r.operation, before); err != nil {
+			return err
+		}
+		if err := r.after("metrics:after_"+r.operation, after(r.operation)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
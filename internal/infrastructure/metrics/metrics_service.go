@@ -0,0 +1,151 @@
+// Package metrics exposes application metrics in the Prometheus exposition format.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	codeEnum "github.com/arfanxn/welding/internal/module/code/domain/enum"
+	"github.com/iancoleman/strcase"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// Login results used as label values of the login attempts counter
+const (
+	LoginResultSuccess = "success"
+	LoginResultFailure = "failure"
+)
+
+type MetricsService interface {
+	// ObserveHTTPRequest records a served HTTP request labelled by its route template
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+	// ObserveDBQuery records the duration of a single GORM statement
+	ObserveDBQuery(operation, table string, duration time.Duration)
+	// IncLogin counts a login attempt with the given result
+	IncLogin(result string)
+	// IncCodeIssued counts a newly issued code of the given type
+	IncCodeIssued(codeType codeEnum.CodeType)
+	// IncCodeRedeemed counts a code of the given type that has been used
+	IncCodeRedeemed(codeType codeEnum.CodeType)
+	// IncRateLimiterRejection counts a request rejected by the rate limiter
+	IncRateLimiterRejection()
+	// Handler returns the HTTP handler serving the metrics endpoint
+	Handler() http.Handler
+}
+
+type prometheusMetricsService struct {
+	registry *prometheus.Registry
+
+	httpRequestsTotal          *prometheus.CounterVec
+	httpRequestDuration        *prometheus.HistogramVec
+	dbQueryDuration            *prometheus.HistogramVec
+	loginAttemptsTotal         *prometheus.CounterVec
+	codesIssuedTotal           *prometheus.CounterVec
+	codesRedeemedTotal         *prometheus.CounterVec
+	rateLimiterRejectionsTotal prometheus.Counter
+}
+
+func NewPrometheusMetricsServiceFromConfig(cfg *config.Config, db *gorm.DB) (MetricsService, error) {
+	namespace := strcase.ToSnake(cfg.AppName)
+
+	s := &prometheusMetricsService{
+		registry: prometheus.NewRegistry(),
+
+		httpRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "GORM statement latency by operation and table.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		loginAttemptsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "login_attempts_total",
+			Help:      "Total number of login attempts by result.",
+		}, []string{"result"}),
+		codesIssuedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "codes_issued_total",
+			Help:      "Total number of issued codes by type.",
+		}, []string{"type"}),
+		codesRedeemedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "codes_redeemed_total",
+			Help:      "Total number of redeemed codes by type.",
+		}, []string{"type"}),
+		rateLimiterRejectionsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limiter_rejections_total",
+			Help:      "Total number of requests rejected by the rate limiter.",
+		}),
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	s.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(sqlDB, "postgres"),
+		s.httpRequestsTotal,
+		s.httpRequestDuration,
+		s.dbQueryDuration,
+		s.loginAttemptsTotal,
+		s.codesIssuedTotal,
+		s.codesRedeemedTotal,
+		s.rateLimiterRejectionsTotal,
+	)
+
+	if err := registerGormCallbacks(db, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *prometheusMetricsService) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	statusStr := strconv.Itoa(status)
+	s.httpRequestsTotal.WithLabelValues(method, route, statusStr).Inc()
+	s.httpRequestDuration.WithLabelValues(method, route, statusStr).Observe(duration.Seconds())
+}
+
+func (s *prometheusMetricsService) ObserveDBQuery(operation, table string, duration time.Duration) {
+	s.dbQueryDuration.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+func (s *prometheusMetricsService) IncLogin(result string) {
+	s.loginAttemptsTotal.WithLabelValues(result).Inc()
+}
+
+func (s *prometheusMetricsService) IncCodeIssued(codeType codeEnum.CodeType) {
+	s.codesIssuedTotal.WithLabelValues(codeType.String()).Inc()
+}
+
+func (s *prometheusMetricsService) IncCodeRedeemed(codeType codeEnum.CodeType) {
+	s.codesRedeemedTotal.WithLabelValues(codeType.String()).Inc()
+}
+
+func (s *prometheusMetricsService) IncRateLimiterRejection() {
+	s.rateLimiterRejectionsTotal.Inc()
+}
+
+func (s *prometheusMetricsService) Handler() http.Handler {
+	return promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{Registry: s.registry})
+}
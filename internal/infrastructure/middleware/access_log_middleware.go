@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ Middleware = (*accessLogMiddleware)(nil)
+
+type AccessLogMiddleware interface {
+	Middleware
+}
+
+type accessLogMiddleware struct {
+	logger *logger.Logger
+}
+
+func NewAccessLogMiddleware(logger *logger.Logger) AccessLogMiddleware {
+	return &accessLogMiddleware{
+		logger: logger,
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that attaches a request-scoped logger
+// (annotated with request and trace ids) to the request context and writes one structured
+// access log entry per request once the handler chain has completed.
+func (m *accessLogMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestLogger := m.logger.WithTrace(c.Request.Context())
+		if requestId := c.GetString(contextkey.RequestIdKey); requestId != "" {
+			requestLogger = &logger.Logger{Logger: requestLogger.With(zap.String("request_id", requestId))}
+		}
+		c.Set(contextkey.LoggerKey, requestLogger)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), requestLogger))
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := c.Writer.Status()
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Int("response_size", c.Writer.Size()),
+		}
+		if userId, ok := c.Get(contextkey.UserIdKey); ok {
+			fields = append(fields, zap.Any("user_id", userId))
+		}
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= 500:
+			level = zapcore.ErrorLevel
+		case status >= 400:
+			level = zapcore.WarnLevel
+		}
+
+		// Stack traces are meaningless for access log entries, even for 5xx responses
+		requestLogger.WithOptions(zap.AddStacktrace(zapcore.FatalLevel)).Log(level, "http request", fields...)
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
 	"github.com/arfanxn/welding/internal/infrastructure/http/jwt"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	userRepository "github.com/arfanxn/welding/internal/module/user/domain/repository"
 	"github.com/arfanxn/welding/pkg/httperror"
+	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gin-gonic/gin"
 	"github.com/gookit/goutil"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 var _ Middleware = (*authenticateMiddleware)(nil)
@@ -48,32 +53,37 @@ func (m *authenticateMiddleware) MiddlewareFunc() gin.HandlerFunc {
 		// 1. Get the Authorization header from the request
 		authHeader := c.GetHeader("Authorization")
 		if goutil.IsEmpty(authHeader) {
-			httperror.Panic(http.StatusUnauthorized, "Header Authorization diperlukan", nil)
+			panic(errorx.ErrAuthTokenMissing)
 		}
 
 		// 2. Extract and validate the Bearer token format
 		// Expected format: "Bearer <token>"
 		tokenParts := strings.Split(authHeader, " ")
 		if len(tokenParts) != 2 || strings.ToLower(tokenParts[0]) != "bearer" {
-			httperror.Panic(http.StatusUnauthorized, "Format header Authorization tidak valid. Format yang benar: Bearer <token>", nil)
+			panic(errorx.ErrAuthTokenMalformed)
 		}
 
 		// 3. Verify the JWT token and extract claims
 		tokenStr := tokenParts[1]
 		claims, err := m.JWTService.VerifyToken(tokenStr)
 		if err != nil {
-			httperror.Panic(http.StatusUnauthorized, "Token tidak valid atau sudah kadaluarsa", nil)
+			panic(errorx.ErrAuthTokenInvalid)
 		}
 
-		// 4. Verify that the user exists in the database
-		user, err := m.UserRepository.Find(claims.UserID)
+		// 4. Verify that the user exists in the database, with the department its policies are evaluated against
+		user, err := m.UserRepository.First(c.Request.Context(), query.NewQuery().FilterById(claims.UserID))
 		if err != nil {
-			httperror.Panic(http.StatusUnauthorized, "User tidak ditemukan", nil)
+			httperror.Panic(http.StatusUnauthorized, "error.user.not_found", nil)
 		}
 
 		// 5. Check if the user account is active
 		if !user.IsActive() {
-			httperror.Panic(http.StatusUnauthorized, "User tidak aktif, silahkan hubungi admin", nil)
+			panic(errorx.ErrUserInactive)
+		}
+
+		// Refuse a token issued before the roles or permissions of the user changed, when it carries its permission version
+		if claims.PermissionVersion != nil && *claims.PermissionVersion != user.PermissionVersion {
+			panic(errorx.ErrAuthTokenStale)
 		}
 
 		// 6. Store user information in both Gin context and request context
@@ -87,8 +97,21 @@ func (m *authenticateMiddleware) MiddlewareFunc() gin.HandlerFunc {
 			c.Set(key, value)                        // Set in Gin context
 			ctx = context.WithValue(ctx, key, value) // Set in request context
 		}
+
+		// Annotate the request-scoped logger with the authenticated user
+		if requestLogger, ok := ctx.Value(contextkey.LoggerKey).(*logger.Logger); ok {
+			requestLogger = &logger.Logger{Logger: requestLogger.With(zap.String("user_id", user.Id))}
+			c.Set(contextkey.LoggerKey, requestLogger)
+			ctx = logger.NewContext(ctx, requestLogger)
+		}
 		c.Request = c.Request.WithContext(ctx)
 
+		// Respond in the preferred locale of the user over the one negotiated from Accept-Language
+		if translator, ok := helper.TranslatorFromC(c); ok && user.Locale.Valid && translator.IsSupported(user.Locale.String) {
+			c.Set(contextkey.LocaleKey, user.Locale.String)
+			c.Header("Content-Language", user.Locale.String)
+		}
+
 		// 7. Proceed to the next middleware/handler in the chain
 		c.Next()
 	}
@@ -1,54 +1,125 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
 	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
-	userRepository "github.com/arfanxn/welding/internal/module/user/domain/repository"
+	userService "github.com/arfanxn/welding/internal/module/user/usecase/service"
 	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/fx"
 )
 
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/infrastructure/middleware")
+
+// authorizeConditions are the conditions requirements may refer to, evaluated against the request
+var authorizeConditions = map[string]func(c *gin.Context, user *entity.User) bool{
+	// owner holds when the :id route parameter is the authenticated user, e.g. PUT /users/:id
+	"owner": func(c *gin.Context, user *entity.User) bool {
+		return c.Param("id") == user.Id
+	},
+}
+
 type AuthorizeMiddleware interface {
+	// RequirePermissionNames requires every one of the permissions
 	RequirePermissionNames(requiredPermNames ...permissionEnum.PermissionName) gin.HandlerFunc
+	// RequireAnyPermission requires one of the permissions at least
+	RequireAnyPermission(permNames ...permissionEnum.PermissionName) gin.HandlerFunc
+	// Require requires a requirement combining permissions, roles and conditions, e.g.
+	// authz.MustParse("users.update OR (users.show AND owner)"). It panics on a condition it does not know.
+	Require(requirement authz.Requirement) gin.HandlerFunc
 }
 
 type authorizeMiddleware struct {
-	userRepository userRepository.UserRepository
+	userPermissionService userService.UserPermissionService
 }
 
 type NewAuthorizeMiddlewareParams struct {
 	fx.In
 
-	UserRepository userRepository.UserRepository
+	UserPermissionService userService.UserPermissionService
 }
 
 func NewAuthorizeMiddleware(
 	params NewAuthorizeMiddlewareParams,
 ) (AuthorizeMiddleware, error) {
 	return &authorizeMiddleware{
-		userRepository: params.UserRepository,
+		userPermissionService: params.UserPermissionService,
 	}, nil
 }
 
 func (m *authorizeMiddleware) RequirePermissionNames(
 	requiredPermNames ...permissionEnum.PermissionName,
 ) gin.HandlerFunc {
+	return m.Require(authz.AllPermissions(requiredPermNames...))
+}
+
+func (m *authorizeMiddleware) RequireAnyPermission(
+	permNames ...permissionEnum.PermissionName,
+) gin.HandlerFunc {
+	return m.Require(authz.AnyPermission(permNames...))
+}
+
+// Require answers 403 auth.forbidden with the requirement and the permissions, roles and conditions
+// it missed, listed under the requirement and unmet errors, when the authenticated user fails it
+func (m *authorizeMiddleware) Require(requirement authz.Requirement) gin.HandlerFunc {
+	for _, name := range requirement.Conditions() {
+		if _, ok := authorizeConditions[name]; !ok {
+			panic(fmt.Sprintf("authorize middleware: unknown condition %q in requirement %q", name, requirement))
+		}
+	}
+
 	return func(c *gin.Context) {
 		user := c.MustGet(contextkey.UserKey).(*entity.User)
 
-		hasPermissions, err := m.userRepository.HasPermissionNames(user, requiredPermNames)
+		ctx, span := tracer.Start(c.Request.Context(), "AuthorizeMiddleware.Require")
+		permissions, err := m.userPermissionService.Resolve(ctx, user)
 		if err != nil {
+			span.End()
 			panic(err)
 		}
 
-		if !hasPermissions {
-			httperror.Panic(http.StatusForbidden, "User tidak memiliki hak akses", nil)
+		allowed, unmet := requirement.Evaluate(&authorizeSubject{c: c, user: user, permissions: permissions})
+		span.SetAttributes(
+			attribute.String("authz.requirement", requirement.String()),
+			attribute.Bool("authz.allowed", allowed),
+		)
+		span.End()
+
+		if !allowed {
+			panic(httperror.New(http.StatusForbidden, "error.auth.forbidden", httperror.ErrorsMap{
+				"requirement": {requirement.String()},
+				"unmet":       lo.Uniq(unmet),
+			}).WithErrorCode("auth.forbidden"))
 		}
 
 		c.Next()
 	}
 }
+
+// authorizeSubject evaluates requirements against the authenticated user and the request
+type authorizeSubject struct {
+	c           *gin.Context
+	user        *entity.User
+	permissions *userService.EffectivePermissions
+}
+
+func (s *authorizeSubject) HasPermissionName(name permissionEnum.PermissionName) bool {
+	return s.permissions.HasPermissionNames(name)
+}
+
+func (s *authorizeSubject) HasRoleName(name roleEnum.RoleName) bool {
+	return s.permissions.HasRoleNames(name)
+}
+
+func (s *authorizeSubject) Condition(name string) bool {
+	return authorizeConditions[name](s.c, s.user)
+}
@@ -0,0 +1,154 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	"github.com/arfanxn/welding/internal/infrastructure/middleware"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	userService "github.com/arfanxn/welding/internal/module/user/usecase/service"
+	"github.com/arfanxn/welding/pkg/httperror"
+	"github.com/gin-gonic/gin"
+)
+
+// userPermissionService resolves every user to the same effective permissions
+type userPermissionService struct {
+	permissions *userService.EffectivePermissions
+}
+
+func (s *userPermissionService) Resolve(ctx context.Context, user *entity.User) (*userService.EffectivePermissions, error) {
+	return s.permissions, nil
+}
+
+func (s *userPermissionService) HasPermissionNames(ctx context.Context, user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error) {
+	return s.permissions.HasPermissionNames(permissionNames...), nil
+}
+
+func (s *userPermissionService) HasRoleNames(ctx context.Context, user *entity.User, roleNames []roleEnum.RoleName) (bool, error) {
+	return s.permissions.HasRoleNames(roleNames...), nil
+}
+
+func TestAuthorizeMiddlewareRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		expression  string
+		permissions *userService.EffectivePermissions
+		// id is the :id route parameter, the authenticated user being user-1
+		id string
+		// unmet is nil when the request is authorized
+		unmet []string
+	}{
+		{
+			name:        "authorized by permission",
+			expression:  "users.update OR (users.show AND owner)",
+			permissions: &userService.EffectivePermissions{PermissionNames: []permissionEnum.PermissionName{permissionEnum.UsersUpdate}},
+			id:          "user-2",
+		},
+		{
+			name:        "authorized as owner",
+			expression:  "users.update OR (users.show AND owner)",
+			permissions: &userService.EffectivePermissions{PermissionNames: []permissionEnum.PermissionName{permissionEnum.UsersShow}},
+			id:          "user-1",
+		},
+		{
+			name:        "forbidden to another user",
+			expression:  "users.update OR (users.show AND owner)",
+			permissions: &userService.EffectivePermissions{PermissionNames: []permissionEnum.PermissionName{permissionEnum.UsersShow}},
+			id:          "user-2",
+			unmet:       []string{"users.update", "owner"},
+		},
+		{
+			name:        "unmet listed once",
+			expression:  "(users.update AND owner) OR (users.show AND owner)",
+			permissions: &userService.EffectivePermissions{},
+			id:          "user-2",
+			unmet:       []string{"users.update", "owner", "users.show"},
+		},
+		{
+			name:        "forbidden without role",
+			expression:  "role:super_admin",
+			permissions: &userService.EffectivePermissions{RoleNames: []roleEnum.RoleName{roleEnum.Admin}},
+			id:          "user-1",
+			unmet:       []string{"role:super_admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := middleware.NewAuthorizeMiddleware(middleware.NewAuthorizeMiddlewareParams{
+				UserPermissionService: &userPermissionService{permissions: tt.permissions},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			requirement := authz.MustParse(tt.expression)
+			handler := m.Require(requirement)
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPut, "/api/v1/users/"+tt.id, nil)
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			c.Set(contextkey.UserKey, &entity.User{Id: "user-1"})
+
+			httpErr := recoverHttpError(t, func() { handler(c) })
+			if tt.unmet == nil {
+				if httpErr != nil {
+					t.Fatalf("Require(%q) = %v, want authorized", tt.expression, httpErr.Errors)
+				}
+				return
+			}
+
+			if httpErr == nil {
+				t.Fatalf("Require(%q) authorized, want forbidden", tt.expression)
+			}
+			if httpErr.Code != http.StatusForbidden || httpErr.ErrorCode != "auth.forbidden" {
+				t.Errorf("Require(%q) = %d %s, want %d auth.forbidden", tt.expression, httpErr.Code, httpErr.ErrorCode, http.StatusForbidden)
+			}
+			if got := httpErr.Errors["requirement"]; !slices.Equal(got, []string{requirement.String()}) {
+				t.Errorf("Require(%q) requirement = %v, want %v", tt.expression, got, []string{requirement.String()})
+			}
+			if got := httpErr.Errors["unmet"]; !slices.Equal(got, tt.unmet) {
+				t.Errorf("Require(%q) unmet = %v, want %v", tt.expression, got, tt.unmet)
+			}
+		})
+	}
+}
+
+func TestAuthorizeMiddlewareRequireUnknownCondition(t *testing.T) {
+	m, err := middleware.NewAuthorizeMiddleware(middleware.NewAuthorizeMiddlewareParams{
+		UserPermissionService: &userPermissionService{permissions: &userService.EffectivePermissions{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Require with an unknown condition did not panic")
+		}
+	}()
+	m.Require(authz.MustParse("users.show AND manager_of"))
+}
+
+// recoverHttpError runs f and returns the HTTP error it panics with, nil when it does not panic
+func recoverHttpError(t *testing.T, f func()) (httpErr *httperror.HttpError) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			var ok bool
+			if httpErr, ok = r.(*httperror.HttpError); !ok {
+				t.Fatalf("panicked with %v, want an HTTP error", r)
+			}
+		}
+	}()
+	f()
+	return nil
+}
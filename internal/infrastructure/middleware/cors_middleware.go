@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*corsMiddleware)(nil)
+
+type CORSMiddleware interface {
+	Middleware
+}
+
+type corsMiddleware struct {
+	allowedOrigins   []string
+	allowAllOrigins  bool
+	allowedMethods   string
+	allowedHeaders   string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+func NewCORSMiddleware(cfg *config.Config) CORSMiddleware {
+	allowedOrigins := make([]string, 0, len(cfg.CORSAllowedOrigins))
+	for _, origin := range cfg.CORSAllowedOrigins {
+		allowedOrigins = append(allowedOrigins, strings.ToLower(strings.TrimSuffix(origin, "/")))
+	}
+
+	return &corsMiddleware{
+		allowedOrigins:   allowedOrigins,
+		allowAllOrigins:  slices.Contains(allowedOrigins, "*"),
+		allowedMethods:   strings.Join(cfg.CORSAllowedMethods, ", "),
+		allowedHeaders:   strings.Join(cfg.CORSAllowedHeaders, ", "),
+		exposedHeaders:   strings.Join(cfg.CORSExposedHeaders, ", "),
+		allowCredentials: cfg.CORSAllowCredentials,
+		maxAge:           strconv.Itoa(int(cfg.CORSMaxAge.Seconds())),
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that implements CORS for the configured origins.
+// Preflight requests are answered directly with 204 and never reach the routes.
+func (m *corsMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		isPreflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if !m.isAllowedOrigin(origin) {
+			// Without CORS headers the browser blocks the response
+			if isPreflight {
+				c.AbortWithStatus(http.StatusNoContent)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// A wildcard origin is not allowed together with credentials, echo the origin instead
+		if m.allowAllOrigins && !m.allowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if m.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if isPreflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+			c.Header("Access-Control-Allow-Methods", m.allowedMethods)
+			c.Header("Access-Control-Allow-Headers", m.allowedHeaders)
+			c.Header("Access-Control-Max-Age", m.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if m.exposedHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", m.exposedHeaders)
+		}
+
+		c.Next()
+	}
+}
+
+func (m *corsMiddleware) isAllowedOrigin(origin string) bool {
+	return m.allowAllOrigins || slices.Contains(m.allowedOrigins, strings.ToLower(origin))
+}
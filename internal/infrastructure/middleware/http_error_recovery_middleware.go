@@ -1,9 +1,19 @@
 package middleware
 
 import (
-	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
 	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 type HttpErrorRecoveryMiddleware interface {
@@ -11,26 +21,73 @@ type HttpErrorRecoveryMiddleware interface {
 }
 
 type httpErrorRecoveryMiddleware struct {
+	logger *logger.Logger
 }
 
-func NewHttpErrorRecoveryMiddleware() HttpErrorRecoveryMiddleware {
-	return &httpErrorRecoveryMiddleware{}
+func NewHttpErrorRecoveryMiddleware(logger *logger.Logger) HttpErrorRecoveryMiddleware {
+	return &httpErrorRecoveryMiddleware{
+		logger: logger,
+	}
 }
 
+// MiddlewareFunc returns a Gin middleware handler that recovers from panics.
+// HttpError panics and mapped domain errors are rendered through the problem package;
+// any other panic is logged with its stack trace and answered with a 500 response.
 func (m *httpErrorRecoveryMiddleware) MiddlewareFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
-			if r := recover(); r != nil {
-				if err, ok := r.(*httperror.HttpError); ok {
-					c.AbortWithStatusJSON(err.Code, response.NewBodyWithErrors(err.Code, err.Error(), err.Errors))
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			if err, ok := r.(error); ok {
+				if httpErr, ok := problem.FromError(err, nil); ok {
+					problem.Render(c, httpErr)
 					return
 				}
+			}
+
+			requestLogger := m.logger.FromContext(c.Request.Context())
 
-				// If it's not an HttpError, re-panic to let Gin handle it
-				panic(r)
+			// A broken connection cannot receive a response, so only record it
+			if isBrokenPipe(r) {
+				requestLogger.Warn("Connection closed by client", zap.Any("panic", r))
+				c.Abort()
+				return
 			}
+
+			requestLogger.Error(
+				"Recovered from panic",
+				zap.String("panic", fmt.Sprint(r)),
+				zap.ByteString("stacktrace", debug.Stack()),
+			)
+
+			problem.Render(c, httperror.New(http.StatusInternalServerError, "error.server.internal_error", nil))
 		}()
 
 		c.Next()
 	}
 }
+
+// isBrokenPipe reports whether the recovered value is a network error caused by the client
+// closing the connection before the response was written.
+func isBrokenPipe(r any) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+
+	var syscallErr *os.SyscallError
+	if !errors.As(opErr, &syscallErr) {
+		return false
+	}
+
+	msg := strings.ToLower(syscallErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
+}
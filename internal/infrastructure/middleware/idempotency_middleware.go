@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/idempotency"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null/v6"
+	"go.uber.org/zap"
+)
+
+var _ Middleware = (*idempotencyMiddleware)(nil)
+
+// IdempotencyMiddleware makes a route safe to retry. The first response of a request sent
+// with an Idempotency-Key is stored per user (or client IP on public routes) and replayed
+// to retries of the same request until it expires, with its Content-Type, Location and ETag.
+// Error responses, 4xx and 5xx alike, are not stored, so a failed request can be retried with
+// the same key.
+type IdempotencyMiddleware interface {
+	Middleware
+}
+
+type idempotencyMiddleware struct {
+	store       idempotency.Store
+	logger      *logger.Logger
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+func NewIdempotencyMiddleware(cfg *config.Config, store idempotency.Store, logger *logger.Logger) IdempotencyMiddleware {
+	return &idempotencyMiddleware{
+		store:       store,
+		logger:      logger,
+		ttl:         cfg.IdempotencyTTL,
+		lockTimeout: cfg.IdempotencyLockTimeout,
+	}
+}
+
+func (m *idempotencyMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotency.HeaderKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !isValidIdempotencyKey(key) {
+			panic(errorx.ErrIdempotencyKeyInvalid)
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			panic(err)
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := time.Now().Truncate(time.Microsecond)
+		record := &idempotency.Record{
+			Scope:       idempotencyScope(c),
+			Key:         key,
+			Fingerprint: idempotency.Fingerprint(c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery, body),
+			LockedAt:    now,
+			ExpiresAt:   now.Add(m.ttl),
+			CreatedAt:   now,
+		}
+
+		existing, locked, err := m.store.Lock(c.Request.Context(), record, now.Add(-m.lockTimeout))
+		if err != nil {
+			panic(err)
+		}
+		if !locked {
+			m.replay(c, existing, record.Fingerprint)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		// The record is stored or released even if the client went away
+		ctx := context.WithoutCancel(c.Request.Context())
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			// The handler panicked or failed, release the key so the request can be retried
+			if err := m.store.Unlock(ctx, record); err != nil {
+				m.logger.Warn("Failed to release idempotency key", zap.String("key", key), zap.Error(err))
+			}
+		}()
+
+		c.Next()
+
+		if writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		record.StatusCode = null.IntFrom(int64(writer.Status()))
+		record.ContentType = writer.Header().Get("Content-Type")
+		record.Location = writer.Header().Get("Location")
+		record.ETag = writer.Header().Get("ETag")
+		record.ResponseBody = writer.body.Bytes()
+		record.CompletedAt = null.TimeFrom(time.Now())
+		if err := m.store.Complete(ctx, record); err != nil {
+			m.logger.Warn("Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+			return
+		}
+		completed = true
+	}
+}
+
+// replay answers a request whose key is held by existing
+func (m *idempotencyMiddleware) replay(c *gin.Context, existing *idempotency.Record, fingerprint string) {
+	if existing.Fingerprint != fingerprint {
+		panic(errorx.ErrIdempotencyKeyReused)
+	}
+
+	if !existing.IsCompleted() {
+		retryAfter := time.Until(existing.LockedAt.Add(m.lockTimeout)).Seconds()
+		c.Header("Retry-After", strconv.Itoa(max(1, int(math.Ceil(retryAfter)))))
+		panic(errorx.ErrIdempotencyRequestInProgress)
+	}
+
+	c.Header(idempotency.HeaderReplayed, "true")
+	if existing.Location != "" {
+		c.Header("Location", existing.Location)
+	}
+	if existing.ETag != "" {
+		c.Header("ETag", existing.ETag)
+	}
+	c.Data(int(existing.StatusCode.Int64), existing.ContentType, existing.ResponseBody)
+	c.Abort()
+}
+
+// idempotencyScope isolates keys per authenticated user, or per client IP on public routes
+func idempotencyScope(c *gin.Context) string {
+	if userId, ok := c.Get(contextkey.UserIdKey); ok {
+		if userId, ok := userId.(string); ok && userId != "" {
+			return "user:" + userId
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// isValidIdempotencyKey reports whether key is short enough and made of printable ASCII
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > idempotency.MaxKeyLength {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// idempotencyResponseWriter keeps a copy of the response body to be stored
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*localeMiddleware)(nil)
+
+type LocaleMiddleware interface {
+	Middleware
+}
+
+type localeMiddleware struct {
+	translator i18n.Translator
+}
+
+func NewLocaleMiddleware(translator i18n.Translator) LocaleMiddleware {
+	return &localeMiddleware{
+		translator: translator,
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that negotiates the locale of the response
+// from Accept-Language and stores it with the translator in the Gin context.
+// The authenticate middleware later replaces the locale with the preferred one of the user, if any.
+func (m *localeMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale := m.translator.Negotiate(c.GetHeader("Accept-Language"))
+
+		c.Set(contextkey.TranslatorKey, m.translator)
+		c.Set(contextkey.LocaleKey, locale)
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*metricsMiddleware)(nil)
+
+type MetricsMiddleware interface {
+	Middleware
+}
+
+type metricsMiddleware struct {
+	metricsService metrics.MetricsService
+}
+
+func NewMetricsMiddleware(metricsService metrics.MetricsService) MetricsMiddleware {
+	return &metricsMiddleware{
+		metricsService: metricsService,
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that records request count and latency.
+// Requests are labelled by route template (e.g. /api/v1/users/:id) to keep label cardinality bounded.
+func (m *metricsMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		m.metricsService.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*preconditionMiddleware)(nil)
+
+// PreconditionMiddleware guards routes modifying a versioned resource against lost updates.
+// The versions listed in If-Match are stored for the handler, which passes them to the usecase,
+// and the request fails with 412 when the resource is at another version. If-Match is
+// required (428 when missing) when HTTP_REQUIRE_IF_MATCH is enabled.
+type PreconditionMiddleware interface {
+	Middleware
+}
+
+type preconditionMiddleware struct {
+	requireIfMatch bool
+}
+
+func NewPreconditionMiddleware(cfg *config.Config) PreconditionMiddleware {
+	return &preconditionMiddleware{
+		requireIfMatch: cfg.RequireIfMatch,
+	}
+}
+
+func (m *preconditionMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("If-Match")
+		if header == "" {
+			if m.requireIfMatch {
+				panic(errorx.ErrRequestPreconditionRequired)
+			}
+			c.Next()
+			return
+		}
+
+		versions, ok := helper.ParseETagVersions(header)
+		if !ok {
+			panic(errorx.ErrRequestPreconditionFailed)
+		}
+		c.Set(contextkey.IfMatchKey, versions)
+
+		c.Next()
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
 	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
@@ -13,20 +14,23 @@ type RateLimiterMiddleware interface {
 }
 
 type rateLimiterMiddleware struct {
-	limiter *rate.Limiter
+	limiter        *rate.Limiter
+	metricsService metrics.MetricsService
 }
 
-func NewRateLimiterMiddleware() RateLimiterMiddleware {
+func NewRateLimiterMiddleware(metricsService metrics.MetricsService) RateLimiterMiddleware {
 	// Allow 4 requests per second with a burst of 4
 	return &rateLimiterMiddleware{
-		limiter: rate.NewLimiter(rate.Limit(4), 4),
+		limiter:        rate.NewLimiter(rate.Limit(4), 4),
+		metricsService: metricsService,
 	}
 }
 
 func (r *rateLimiterMiddleware) MiddlewareFunc() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		if !r.limiter.Allow() {
-			httperror.Panic(http.StatusTooManyRequests, "Terlalu banyak permintaan", nil)
+			r.metricsService.IncRateLimiterRejection()
+			httperror.Panic(http.StatusTooManyRequests, "error.request.rate_limited", nil)
 		}
 
 		c.Next()
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/arfanxn/welding/internal/infrastructure/id"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*requestIdMiddleware)(nil)
+
+// RequestIdHeader is the header used to receive and return the request ID
+const RequestIdHeader = "X-Request-ID"
+
+// requestIdPattern restricts incoming request IDs to a safe charset and length,
+// so client supplied values cannot inject arbitrary content into logs.
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+type RequestIdMiddleware interface {
+	Middleware
+}
+
+type requestIdMiddleware struct {
+	idService id.IdService
+}
+
+func NewRequestIdMiddleware(idService id.IdService) RequestIdMiddleware {
+	return &requestIdMiddleware{
+		idService: idService,
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that assigns every request an ID.
+// A valid incoming X-Request-ID is honored, otherwise a new one is generated.
+// The ID is echoed in the response header and stored in both Gin and request context.
+func (m *requestIdMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !requestIdPattern.MatchString(requestId) {
+			requestId = m.idService.Generate()
+		}
+
+		c.Header(RequestIdHeader, requestId)
+		c.Set(contextkey.RequestIdKey, requestId)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextkey.RequestIdKey, requestId))
+
+		c.Next()
+	}
+}
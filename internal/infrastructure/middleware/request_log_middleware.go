@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/id"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/module/request_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/guregu/null/v6"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	// requestLogSaveTimeout bounds the save of a single request log
+	requestLogSaveTimeout = 5 * time.Second
+	// maxPendingRequestLogs bounds the request logs being saved at once, the ones above it are dropped
+	maxPendingRequestLogs = 1000
+	// requestLogPathPrefix is the prefix of the paths of the API requests, the only ones recorded
+	requestLogPathPrefix = "/api/"
+)
+
+var _ Middleware = (*requestLogMiddleware)(nil)
+
+type RequestLogMiddleware interface {
+	Middleware
+}
+
+type requestLogMiddleware struct {
+	enabled              bool
+	logger               *logger.Logger
+	idService            id.IdService
+	requestLogRepository repository.RequestLogRepository
+	pending              chan struct{}
+	saves                sync.WaitGroup
+}
+
+// NewRequestLogMiddleware creates the request log middleware. On stop it waits for the request logs being
+// saved, until the stop context is done.
+func NewRequestLogMiddleware(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	logger *logger.Logger,
+	idService id.IdService,
+	requestLogRepository repository.RequestLogRepository,
+) RequestLogMiddleware {
+	m := &requestLogMiddleware{
+		enabled:              cfg.RequestLogEnabled,
+		logger:               logger,
+		idService:            idService,
+		requestLogRepository: requestLogRepository,
+		pending:              make(chan struct{}, maxPendingRequestLogs),
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(stopCtx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				m.saves.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+				m.logger.Warn("request logs left unsaved on stop", zap.Int("pending", len(m.pending)))
+			}
+			return nil
+		},
+	})
+
+	return m
+}
+
+// MiddlewareFunc returns a Gin middleware handler that stores a request log of the API requests once
+// the handler chain has completed, when REQUEST_LOG_ENABLED is set. It must run before the error
+// recovery to record the failed requests. Request logs are saved in the background so the store never
+// delays the response, a request log that cannot be saved is only reported in the logs.
+func (m *requestLogMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !m.enabled || !strings.HasPrefix(c.Request.URL.Path, requestLogPathPrefix) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requestLog := &entity.RequestLog{
+			Id:           m.idService.Generate(),
+			RequestId:    c.GetString(contextkey.RequestIdKey),
+			Method:       c.Request.Method,
+			Route:        route,
+			Path:         c.Request.URL.Path,
+			Status:       c.Writer.Status(),
+			LatencyMs:    time.Since(start).Milliseconds(),
+			ClientIp:     c.ClientIP(),
+			UserAgent:    c.Request.UserAgent(),
+			ResponseSize: max(c.Writer.Size(), 0),
+			CreatedAt:    start,
+		}
+		if userId := c.GetString(contextkey.UserIdKey); userId != "" {
+			requestLog.UserId = null.StringFrom(userId)
+		}
+
+		// The request context is cancelled once the response is sent, its values are kept for the logs and traces
+		ctx := context.WithoutCancel(c.Request.Context())
+		select {
+		case m.pending <- struct{}{}:
+			m.saves.Add(1)
+			go m.save(ctx, requestLog)
+		default:
+			m.logger.FromContext(ctx).Warn("request log dropped, too many request logs pending")
+		}
+	}
+}
+
+func (m *requestLogMiddleware) save(ctx context.Context, requestLog *entity.RequestLog) {
+	defer func() {
+		<-m.pending
+		m.saves.Done()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, requestLogSaveTimeout)
+	defer cancel()
+
+	if err := m.requestLogRepository.Save(ctx, requestLog); err != nil {
+		m.logger.FromContext(ctx).Error("failed to save the request log", zap.Error(err))
+	}
+}
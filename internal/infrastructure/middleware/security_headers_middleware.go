@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*securityHeadersMiddleware)(nil)
+
+type SecurityHeadersMiddleware interface {
+	Middleware
+}
+
+type securityHeadersMiddleware struct {
+	hsts string
+	csp  string
+}
+
+func NewSecurityHeadersMiddleware(cfg *config.Config) SecurityHeadersMiddleware {
+	hsts := ""
+	if cfg.SecurityHSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d; includeSubDomains", int(cfg.SecurityHSTSMaxAge.Seconds()))
+	}
+
+	return &securityHeadersMiddleware{
+		hsts: hsts,
+		csp:  cfg.SecurityCSP,
+	}
+}
+
+// MiddlewareFunc returns a Gin middleware handler that sets the default security headers.
+// The Content-Security-Policy is strict by default, handlers serving HTML may override it.
+// HSTS is only sent over HTTPS, as resolved by the trusted proxy middleware.
+func (m *securityHeadersMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("X-Frame-Options", "DENY")
+		c.Header("Referrer-Policy", "no-referrer")
+		c.Header("Cross-Origin-Opener-Policy", "same-origin")
+		if m.csp != "" {
+			c.Header("Content-Security-Policy", m.csp)
+		}
+		if m.hsts != "" && helper.SchemeFromC(c) == "https" {
+			c.Header("Strict-Transport-Security", m.hsts)
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/gin-gonic/gin"
+)
+
+var _ Middleware = (*trustedProxyMiddleware)(nil)
+
+type TrustedProxyMiddleware interface {
+	Middleware
+}
+
+type trustedProxyMiddleware struct {
+	trustedNets []*net.IPNet
+}
+
+// NewTrustedProxyMiddleware creates the middleware from TRUSTED_PROXIES,
+// a list of IP addresses and/or CIDR ranges.
+func NewTrustedProxyMiddleware(cfg *config.Config) (TrustedProxyMiddleware, error) {
+	trustedNets := make([]*net.IPNet, 0, len(cfg.TrustedProxies))
+	for _, proxy := range cfg.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		trustedNets = append(trustedNets, ipNet)
+	}
+
+	return &trustedProxyMiddleware{
+		trustedNets: trustedNets,
+	}, nil
+}
+
+// MiddlewareFunc returns a Gin middleware handler that resolves the scheme of the request
+// as seen by the client. X-Forwarded-Proto is only honored when the direct peer is a trusted proxy,
+// otherwise it is removed so nothing downstream can be fooled by a spoofed header.
+// The client IP and user agent are stored in the request context for the layers without
+// access to the Gin context, e.g. the audit logs of the use cases.
+func (m *trustedProxyMiddleware) MiddlewareFunc() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scheme := "http"
+		if c.Request.TLS != nil {
+			scheme = "https"
+		}
+
+		forwardedProto := strings.ToLower(strings.TrimSpace(strings.Split(c.GetHeader("X-Forwarded-Proto"), ",")[0]))
+		if m.isTrusted(c.RemoteIP()) {
+			if forwardedProto == "http" || forwardedProto == "https" {
+				scheme = forwardedProto
+			}
+		} else {
+			c.Request.Header.Del("X-Forwarded-Proto")
+			c.Request.Header.Del("X-Forwarded-Host")
+		}
+
+		c.Set(contextkey.SchemeKey, scheme)
+
+		ctx := context.WithValue(c.Request.Context(), contextkey.ClientIpKey, c.ClientIP())
+		ctx = context.WithValue(ctx, contextkey.UserAgentKey, c.Request.UserAgent())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func (m *trustedProxyMiddleware) isTrusted(remoteIP string) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, trustedNet := range m.trustedNets {
+		if trustedNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
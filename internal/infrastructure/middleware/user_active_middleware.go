@@ -1,11 +1,9 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
-	"github.com/arfanxn/welding/pkg/httperror"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	"github.com/gin-gonic/gin"
 )
 
@@ -29,7 +27,7 @@ func (m *userActiveMiddleware) MiddlewareFunc() gin.HandlerFunc {
 
 		// Check if the user account is active
 		if !user.IsActive() {
-			httperror.Panic(http.StatusUnauthorized, "User tidak aktif, silahkan hubungi admin", nil)
+			panic(errorx.ErrUserInactive)
 		}
 
 		c.Next()
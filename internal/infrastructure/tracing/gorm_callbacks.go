@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"gorm.io/gorm"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const gormSpanKey = "tracing:span"
+
+// RegisterGormCallbacks starts a client span for every GORM statement.
+// Spans are children of the span found in the statement context (see gorm.DB.WithContext).
+func RegisterGormCallbacks(db *gorm.DB, tp trace.TracerProvider) error {
+	tracer := tp.Tracer("gorm.io/gorm")
+
+	before := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			ctx, span := tracer.Start(
+				db.Statement.Context,
+				"gorm."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(semconv.DBSystemPostgreSQL),
+			)
+			db.Statement.Context = ctx
+			db.InstanceSet(gormSpanKey, span)
+		}
+	}
+
+	after := func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		span, ok := value.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		span.SetAttributes(
+			semconv.DBCollectionName(db.Statement.Table),
+			semconv.DBQueryText(db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+		)
+		if db.Error != nil {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		}
+	}
+
+	callback := db.Callback()
+	registrations := []struct {
+		operation string
+		before    func(string, func(*gorm.DB)) error
+		after     func(string, func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, r := range registrations {
+		if err := r.before("tracing:before_"+r.operation, before(r.operation)); err != nil {
+			return err
+		}
+		if err := r.after("tracing:after_"+r.operation, after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
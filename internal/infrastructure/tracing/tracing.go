@@ -0,0 +1,101 @@
+// Package tracing configures OpenTelemetry tracing with W3C trace-context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+)
+
+// Supported values of TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// NewTracerProviderFromConfig creates the application TracerProvider, registers it
+// globally together with the W3C trace-context propagator and flushes it on shutdown.
+func NewTracerProviderFromConfig(lc fx.Lifecycle, cfg *config.Config) (trace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(cfg.TracingExporter)
+	if exporterName == "" || exporterName == ExporterNone {
+		tp := noop.NewTracerProvider()
+		otel.SetTracerProvider(tp)
+		return tp, nil
+	}
+
+	exporter, err := newExporter(exporterName, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(cfg.AppName),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRatio := cfg.TracingSampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = 1
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return tp.Shutdown(ctx)
+		},
+	})
+
+	return tp, nil
+}
+
+func newExporter(exporterName string, cfg *config.Config) (sdktrace.SpanExporter, error) {
+	switch exporterName {
+	case ExporterStdout:
+		return stdouttrace.New(
+			stdouttrace.WithWriter(os.Stdout),
+			stdouttrace.WithPrettyPrint(),
+		)
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.TracingOTLPEndpoint != nil {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.TracingOTLPEndpoint.String()))
+		}
+		if cfg.TracingOTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", exporterName)
+	}
+}
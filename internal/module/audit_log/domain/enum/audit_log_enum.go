@@ -0,0 +1,63 @@
+package enum
+
+// AuditLogAction names an audited administrative action as <target type>.<action>
+type AuditLogAction string
+
+const (
+	UserStore            AuditLogAction = "user.store"
+	UserUpdate           AuditLogAction = "user.update"
+	UserToggleActivation AuditLogAction = "user.toggle_activation"
+	UserDestroy          AuditLogAction = "user.destroy"
+	UserResetPassword    AuditLogAction = "user.reset_password"
+	UserGrantRole        AuditLogAction = "user.grant_role"
+	UserRoleGrantBegin   AuditLogAction = "user.role_grant_begin"
+	UserRoleGrantEnd     AuditLogAction = "user.role_grant_end"
+
+	RoleStore      AuditLogAction = "role.store"
+	RoleUpdate     AuditLogAction = "role.update"
+	RoleSetDefault AuditLogAction = "role.set_default"
+	RoleDestroy    AuditLogAction = "role.destroy"
+
+	CodeCreateUserRegisterInvitation AuditLogAction = "code.create_user_register_invitation"
+)
+
+func (a AuditLogAction) String() string {
+	return string(a)
+}
+
+var AuditLogActions = []AuditLogAction{
+	UserStore,
+	UserUpdate,
+	UserToggleActivation,
+	UserDestroy,
+	UserResetPassword,
+	UserGrantRole,
+	UserRoleGrantBegin,
+	UserRoleGrantEnd,
+
+	RoleStore,
+	RoleUpdate,
+	RoleSetDefault,
+	RoleDestroy,
+
+	CodeCreateUserRegisterInvitation,
+}
+
+// AuditLogTargetType names the kind of record an audited action is performed on
+type AuditLogTargetType string
+
+const (
+	TargetUser AuditLogTargetType = "user"
+	TargetRole AuditLogTargetType = "role"
+	TargetCode AuditLogTargetType = "code"
+)
+
+func (t AuditLogTargetType) String() string {
+	return string(t)
+}
+
+var AuditLogTargetTypes = []AuditLogTargetType{
+	TargetUser,
+	TargetRole,
+	TargetCode,
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/pkg/pagination"
+)
+
+// AuditLogFilter narrows down the audit logs to paginate, a nil field matches every audit log.
+// The created_at bounds are inclusive.
+type AuditLogFilter struct {
+	ActorId     *string
+	Action      *string
+	TargetType  *string
+	TargetId    *string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	Offset int
+	Limit  int
+}
+
+type AuditLogRepository interface {
+	Save(ctx context.Context, auditLog *entity.AuditLog) error
+	// Paginate returns the audit logs matching filter, the most recent first
+	Paginate(ctx context.Context, filter *AuditLogFilter) (*pagination.OffsetPagination[*entity.AuditLog], error)
+}
@@ -0,0 +1,18 @@
+package di
+
+import (
+	"github.com/arfanxn/welding/internal/module/audit_log/infrastructure/repository"
+	"github.com/arfanxn/welding/internal/module/audit_log/presentation/http"
+	"github.com/arfanxn/welding/internal/module/audit_log/usecase"
+	"github.com/arfanxn/welding/internal/module/audit_log/usecase/service"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("audit_log",
+	fx.Provide(
+		repository.NewAuditLogRepository,
+		service.NewAuditLogService,
+		usecase.NewAuditLogUsecase,
+		http.NewAuditLogHandler,
+	),
+)
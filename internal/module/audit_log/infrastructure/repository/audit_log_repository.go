@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/repository"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// NewAuditLogRepository stores the audit logs in Mongo when MONGO_DSN is configured, in Postgres otherwise
+func NewAuditLogRepository(
+	lc fx.Lifecycle,
+	db *gorm.DB,
+	readDB database.ReadDB,
+	mongoDB database.MongoDB,
+) repository.AuditLogRepository {
+	if mongoDB.IsConfigured() {
+		return NewMongoAuditLogRepository(lc, mongoDB)
+	}
+	return NewGormAuditLogRepository(db, readDB)
+}
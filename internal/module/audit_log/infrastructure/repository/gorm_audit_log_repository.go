@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+var _ repository.AuditLogRepository = (*GormAuditLogRepository)(nil)
+
+type GormAuditLogRepository struct {
+	db *gorm.DB
+	// readDB serves Paginate, it may lag behind db when it is a read replica
+	readDB *gorm.DB
+}
+
+func NewGormAuditLogRepository(db *gorm.DB, readDB database.ReadDB) repository.AuditLogRepository {
+	return &GormAuditLogRepository{
+		db:     db,
+		readDB: readDB.DB,
+	}
+}
+
+func (r *GormAuditLogRepository) Save(ctx context.Context, auditLog *entity.AuditLog) error {
+	return r.db.WithContext(ctx).Create(auditLog).Error
+}
+
+func (r *GormAuditLogRepository) Paginate(ctx context.Context, filter *repository.AuditLogFilter) (*pagination.OffsetPagination[*entity.AuditLog], error) {
+	db := r.readDB.WithContext(ctx).Model(&entity.AuditLog{})
+
+	if filter.ActorId != nil {
+		db = db.Where("actor_id = ?", *filter.ActorId)
+	}
+	if filter.Action != nil {
+		db = db.Where("action = ?", *filter.Action)
+	}
+	if filter.TargetType != nil {
+		db = db.Where("target_type = ?", *filter.TargetType)
+	}
+	if filter.TargetId != nil {
+		db = db.Where("target_id = ?", *filter.TargetId)
+	}
+	if filter.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *filter.CreatedTo)
+	}
+
+	var totalItems int64
+	if err := db.Count(&totalItems).Error; err != nil {
+		return nil, err
+	}
+
+	var auditLogs []*entity.AuditLog
+	err := db.Order("created_at DESC").Order("id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&auditLogs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.NewOffsetPagination(filter.Offset, filter.Limit, int(totalItems), auditLogs), nil
+}
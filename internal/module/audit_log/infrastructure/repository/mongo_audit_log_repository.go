@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/pkg/pagination"
+	"github.com/guregu/null/v6"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/fx"
+	"gorm.io/datatypes"
+)
+
+var _ repository.AuditLogRepository = (*MongoAuditLogRepository)(nil)
+
+// mongoAuditLog is the document of an audit log, its before and after states are stored as
+// embedded documents so they can be queried
+type mongoAuditLog struct {
+	Id         string                  `bson:"_id"`
+	ActorId    *string                 `bson:"actor_id"`
+	Action     enum.AuditLogAction     `bson:"action"`
+	TargetType enum.AuditLogTargetType `bson:"target_type"`
+	TargetId   string                  `bson:"target_id"`
+	Before     any                     `bson:"before"`
+	After      any                     `bson:"after"`
+	IpAddress  string                  `bson:"ip_address"`
+	UserAgent  string                  `bson:"user_agent"`
+	RequestId  string                  `bson:"request_id"`
+	CreatedAt  time.Time               `bson:"created_at"`
+}
+
+// storedMongoAuditLog is a mongoAuditLog read back, its states are kept raw to be turned into JSON
+type storedMongoAuditLog struct {
+	Id         string                  `bson:"_id"`
+	ActorId    *string                 `bson:"actor_id"`
+	Action     enum.AuditLogAction     `bson:"action"`
+	TargetType enum.AuditLogTargetType `bson:"target_type"`
+	TargetId   string                  `bson:"target_id"`
+	Before     bson.RawValue           `bson:"before"`
+	After      bson.RawValue           `bson:"after"`
+	IpAddress  string                  `bson:"ip_address"`
+	UserAgent  string                  `bson:"user_agent"`
+	RequestId  string                  `bson:"request_id"`
+	CreatedAt  time.Time               `bson:"created_at"`
+}
+
+type MongoAuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoAuditLogRepository(lc fx.Lifecycle, mongoDB database.MongoDB) repository.AuditLogRepository {
+	r := &MongoAuditLogRepository{
+		collection: mongoDB.Collection("audit_logs"),
+	}
+	lc.Append(fx.Hook{
+		OnStart: r.createIndexes,
+	})
+	return r
+}
+
+// createIndexes creates the indexes of the audit logs queries, existing indexes are left untouched
+func (r *MongoAuditLogRepository) createIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (r *MongoAuditLogRepository) Save(ctx context.Context, auditLog *entity.AuditLog) error {
+	document, err := toMongoAuditLog(auditLog)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.InsertOne(ctx, document)
+	return err
+}
+
+func (r *MongoAuditLogRepository) Paginate(ctx context.Context, filter *repository.AuditLogFilter) (*pagination.OffsetPagination[*entity.AuditLog], error) {
+	conditions := bson.D{}
+	if filter.ActorId != nil {
+		conditions = append(conditions, bson.E{Key: "actor_id", Value: *filter.ActorId})
+	}
+	if filter.Action != nil {
+		conditions = append(conditions, bson.E{Key: "action", Value: *filter.Action})
+	}
+	if filter.TargetType != nil {
+		conditions = append(conditions, bson.E{Key: "target_type", Value: *filter.TargetType})
+	}
+	if filter.TargetId != nil {
+		conditions = append(conditions, bson.E{Key: "target_id", Value: *filter.TargetId})
+	}
+	createdAt := bson.D{}
+	if filter.CreatedFrom != nil {
+		createdAt = append(createdAt, bson.E{Key: "$gte", Value: *filter.CreatedFrom})
+	}
+	if filter.CreatedTo != nil {
+		createdAt = append(createdAt, bson.E{Key: "$lte", Value: *filter.CreatedTo})
+	}
+	if len(createdAt) > 0 {
+		conditions = append(conditions, bson.E{Key: "created_at", Value: createdAt})
+	}
+
+	totalItems, err := r.collection.CountDocuments(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(filter.Offset)).
+		SetLimit(int64(filter.Limit))
+	cursor, err := r.collection.Find(ctx, conditions, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []*storedMongoAuditLog
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	auditLogs := make([]*entity.AuditLog, 0, len(documents))
+	for _, document := range documents {
+		auditLog, err := fromMongoAuditLog(document)
+		if err != nil {
+			return nil, err
+		}
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return pagination.NewOffsetPagination(filter.Offset, filter.Limit, int(totalItems), auditLogs), nil
+}
+
+func toMongoAuditLog(auditLog *entity.AuditLog) (*mongoAuditLog, error) {
+	before, err := jsonToDocument(auditLog.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := jsonToDocument(auditLog.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongoAuditLog{
+		Id:         auditLog.Id,
+		ActorId:    auditLog.ActorId.Ptr(),
+		Action:     auditLog.Action,
+		TargetType: auditLog.TargetType,
+		TargetId:   auditLog.TargetId,
+		Before:     before,
+		After:      after,
+		IpAddress:  auditLog.IpAddress,
+		UserAgent:  auditLog.UserAgent,
+		RequestId:  auditLog.RequestId,
+		CreatedAt:  auditLog.CreatedAt,
+	}, nil
+}
+
+func fromMongoAuditLog(document *storedMongoAuditLog) (*entity.AuditLog, error) {
+	before, err := documentToJSON(document.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := documentToJSON(document.After)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.AuditLog{
+		Id:         document.Id,
+		ActorId:    null.StringFromPtr(document.ActorId),
+		Action:     document.Action,
+		TargetType: document.TargetType,
+		TargetId:   document.TargetId,
+		Before:     before,
+		After:      after,
+		IpAddress:  document.IpAddress,
+		UserAgent:  document.UserAgent,
+		RequestId:  document.RequestId,
+		CreatedAt:  document.CreatedAt,
+	}, nil
+}
+
+// jsonToDocument decodes data into a value stored as an embedded document, nil when data is empty
+func jsonToDocument(data datatypes.JSON) (any, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// documentToJSON encodes an embedded document stored by jsonToDocument back into JSON, nil when it is null
+func documentToJSON(value bson.RawValue) (datatypes.JSON, error) {
+	if value.Type != bson.TypeEmbeddedDocument {
+		return nil, nil
+	}
+
+	data, err := bson.MarshalExtJSON(value.Document(), false, false)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/response"
+	"github.com/arfanxn/welding/internal/module/audit_log/usecase"
+	"github.com/arfanxn/welding/pkg/pagination"
+	"github.com/arfanxn/welding/pkg/query"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditLogHandler interface {
+	Paginate(c *gin.Context)
+}
+
+type auditLogHandler struct {
+	auditLogUsecase usecase.AuditLogUsecase
+}
+
+func NewAuditLogHandler(auditLogUsecase usecase.AuditLogUsecase) AuditLogHandler {
+	return &auditLogHandler{
+		auditLogUsecase: auditLogUsecase,
+	}
+}
+
+func (h *auditLogHandler) Paginate(c *gin.Context) {
+	q := query.NewQuery()
+	c.ShouldBind(q)
+
+	op, err := h.auditLogUsecase.Paginate(c.Request.Context(), q)
+	if err != nil {
+		panic(err)
+	}
+
+	c.JSON(http.StatusOK, response.NewBodyWithData(
+		http.StatusOK,
+		helper.T(c, "message.audit_log.paginated", nil),
+		pagination.PPFromOP(op, helper.URLFromC(c)),
+	))
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/arfanxn/welding/pkg/pagination"
+	"github.com/arfanxn/welding/pkg/query"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/audit_log/usecase")
+
+// dateLayout is accepted in the created_at filters besides RFC 3339, as a whole day
+const dateLayout = time.DateOnly
+
+var _ AuditLogUsecase = (*auditLogUsecase)(nil)
+
+type AuditLogUsecase interface {
+	// Paginate returns the audit logs, the most recent first. They are filtered by the
+	// actor_id==, action==, target_type==, target_id==, created_at>= and created_at<= filters of q.
+	Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.AuditLog], error)
+}
+
+type auditLogUsecase struct {
+	auditLogRepository repository.AuditLogRepository
+}
+
+func NewAuditLogUsecase(auditLogRepository repository.AuditLogRepository) AuditLogUsecase {
+	return &auditLogUsecase{
+		auditLogRepository: auditLogRepository,
+	}
+}
+
+func (u *auditLogUsecase) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.AuditLog], error) {
+	ctx, span := tracer.Start(ctx, "AuditLogUsecase.Paginate")
+	defer span.End()
+
+	filter := &repository.AuditLogFilter{
+		Offset: q.GetOffset(),
+		Limit:  q.GetLimit(),
+	}
+	for column, value := range map[string]**string{
+		"actor_id":    &filter.ActorId,
+		"action":      &filter.Action,
+		"target_type": &filter.TargetType,
+		"target_id":   &filter.TargetId,
+	} {
+		if f := q.GetFilter(column, query.OperatorEqual); f != nil {
+			*value = &f.Value
+		}
+	}
+
+	if f := q.GetFilter("created_at", query.OperatorGreaterThanOrEqual); f != nil {
+		createdFrom, err := parseTime(f.Value, false)
+		if err != nil {
+			return nil, err
+		}
+		filter.CreatedFrom = &createdFrom
+	}
+	if f := q.GetFilter("created_at", query.OperatorLessThanOrEqual); f != nil {
+		createdTo, err := parseTime(f.Value, true)
+		if err != nil {
+			return nil, err
+		}
+		filter.CreatedTo = &createdTo
+	}
+
+	return u.auditLogRepository.Paginate(ctx, filter)
+}
+
+// parseTime parses an RFC 3339 time or a date, which stands for its first instant or, when endOfDay
+// is set, its last one
+func parseTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, errorx.ErrRequestFilterInvalid
+	}
+	if endOfDay {
+		return date.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return date, nil
+}
@@ -0,0 +1,14 @@
+package dto
+
+import "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+
+// RecordAuditLog describes an administrative action performed by the authenticated user
+type RecordAuditLog struct {
+	Action     enum.AuditLogAction
+	TargetType enum.AuditLogTargetType
+	TargetId   string
+	// Before is the state of the target before the action, nil when the action created it
+	Before any
+	// After is the state of the target after the action, nil when the action deleted it
+	After any
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/id"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/audit_log/usecase/dto"
+	"github.com/arfanxn/welding/internal/module/shared/contextkey"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/guregu/null/v6"
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+)
+
+type AuditLogService interface {
+	// Record stores an audit log of the action, attributed to the user, client and request of ctx.
+	// Of an updated target only the changed fields are kept. The action already happened, so a
+	// failure to record it is logged instead of being returned.
+	Record(ctx context.Context, _dto *dto.RecordAuditLog)
+}
+
+type auditLogService struct {
+	idService          id.IdService
+	logger             *logger.Logger
+	auditLogRepository repository.AuditLogRepository
+}
+
+func NewAuditLogService(
+	idService id.IdService,
+	logger *logger.Logger,
+	auditLogRepository repository.AuditLogRepository,
+) AuditLogService {
+	return &auditLogService{
+		idService:          idService,
+		logger:             logger,
+		auditLogRepository: auditLogRepository,
+	}
+}
+
+func (s *auditLogService) Record(ctx context.Context, _dto *dto.RecordAuditLog) {
+	before, after, err := diff(_dto.Before, _dto.After)
+	if err != nil {
+		s.logger.FromContext(ctx).Error("failed to diff the audit log states", zap.Error(err), zap.Stringer("action", _dto.Action))
+		return
+	}
+
+	auditLog := &entity.AuditLog{
+		Id:         s.idService.Generate(),
+		Action:     _dto.Action,
+		TargetType: _dto.TargetType,
+		TargetId:   _dto.TargetId,
+		Before:     before,
+		After:      after,
+		CreatedAt:  time.Now(),
+	}
+	if actorId, ok := ctx.Value(contextkey.UserIdKey).(string); ok {
+		auditLog.ActorId = null.StringFrom(actorId)
+	}
+	auditLog.IpAddress, _ = ctx.Value(contextkey.ClientIpKey).(string)
+	auditLog.UserAgent, _ = ctx.Value(contextkey.UserAgentKey).(string)
+	auditLog.RequestId, _ = ctx.Value(contextkey.RequestIdKey).(string)
+
+	// The action is recorded even when the client went away after it was performed
+	if err := s.auditLogRepository.Save(context.WithoutCancel(ctx), auditLog); err != nil {
+		s.logger.FromContext(ctx).Error("failed to record the audit log", zap.Error(err), zap.Stringer("action", _dto.Action))
+	}
+}
+
+// diff encodes the states of a target. When both are given only the fields whose value changed are
+// kept, a field missing from one of them is taken as null.
+func diff(before any, after any) (datatypes.JSON, datatypes.JSON, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		changedBefore := map[string]any{}
+		changedAfter := map[string]any{}
+		for _, fields := range []map[string]any{beforeFields, afterFields} {
+			for key := range fields {
+				if !reflect.DeepEqual(beforeFields[key], afterFields[key]) {
+					changedBefore[key] = beforeFields[key]
+					changedAfter[key] = afterFields[key]
+				}
+			}
+		}
+		beforeFields, afterFields = changedBefore, changedAfter
+	}
+
+	beforeJSON, err := toJSON(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := toJSON(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+// toFields returns the JSON fields of state, nil when state is nil
+func toFields(state any) (map[string]any, error) {
+	if state == nil || (reflect.ValueOf(state).Kind() == reflect.Pointer && reflect.ValueOf(state).IsNil()) {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// toJSON encodes fields, nil when there are none
+func toJSON(fields map[string]any) (datatypes.JSON, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
@@ -1,16 +1,18 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/arfanxn/welding/internal/module/code/domain/enum"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 )
 
 type CodeRepository interface {
-	Find(id string) (*entity.Code, error)
-	FindByValue(value string) (*entity.Code, error)
-	FindByTypeAndValue(_type enum.CodeType, value string) (*entity.Code, error)
-	FindByCodeableAndTypeAndValue(codeableId string, codeableType string, _type enum.CodeType, value string) (*entity.Code, error)
-	Save(code *entity.Code) error
-	SaveMany(codes []*entity.Code) error
-	Destroy(code *entity.Code) error
+	Find(ctx context.Context, id string) (*entity.Code, error)
+	FindByValue(ctx context.Context, value string) (*entity.Code, error)
+	FindByTypeAndValue(ctx context.Context, _type enum.CodeType, value string) (*entity.Code, error)
+	FindByCodeableAndTypeAndValue(ctx context.Context, codeableId string, codeableType string, _type enum.CodeType, value string) (*entity.Code, error)
+	Save(ctx context.Context, code *entity.Code) error
+	SaveMany(ctx context.Context, codes []*entity.Code) error
+	Destroy(ctx context.Context, code *entity.Code) error
 }
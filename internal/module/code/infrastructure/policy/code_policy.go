@@ -3,10 +3,14 @@ package policy
 import (
 	"context"
 
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
 	codeRepository "github.com/arfanxn/welding/internal/module/code/domain/repository"
 	"github.com/arfanxn/welding/internal/module/code/usecase/dto"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/arfanxn/welding/pkg/query"
 	"go.uber.org/fx"
 )
 
@@ -15,6 +19,7 @@ type CodePolicy interface {
 }
 
 type codePolicy struct {
+	policyEngine   authz.PolicyEngine
 	codeRepository codeRepository.CodeRepository
 	roleRepository roleRepository.RoleRepository
 }
@@ -22,12 +27,17 @@ type codePolicy struct {
 type NewCodePolicyParams struct {
 	fx.In
 
+	PolicyEngine   authz.PolicyEngine
 	CodeRepository codeRepository.CodeRepository
 	RoleRepository roleRepository.RoleRepository
 }
 
 func NewCodePolicy(params NewCodePolicyParams) CodePolicy {
+	// users.store implies invitations.store, those who create users may invite them as well
+	params.PolicyEngine.Register(permissionEnum.InvitationsStore, forbidSuperAdminInvitation)
+
 	return &codePolicy{
+		policyEngine:   params.PolicyEngine,
 		codeRepository: params.CodeRepository,
 		roleRepository: params.RoleRepository,
 	}
@@ -36,7 +46,8 @@ func NewCodePolicy(params NewCodePolicyParams) CodePolicy {
 // CreateUserRegisterInvitation validates the user registration invitation request.
 // It performs the following validations:
 // 1. Checks if the specified role exists in the system
-// 2. Ensures the role is not a super admin role (super admin invitations are not allowed)
+// 2. Authorizes the invitation to the role against the invitations.store rules, which refuse
+// super admin invitations
 //
 // Parameters:
 //   - ctx: Context for request-scoped values, cancellation signals, and deadlines
@@ -45,17 +56,20 @@ func NewCodePolicy(params NewCodePolicyParams) CodePolicy {
 // Returns:
 //   - error: Returns nil if validation passes, otherwise returns an appropriate HTTP error
 func (p *codePolicy) CreateUserRegisterInvitation(ctx context.Context, _dto *dto.CreateUserRegisterInvitation) error {
-	// Retrieve the role to validate its existence and type
-	role, err := p.roleRepository.Find(_dto.RoleId)
+	// Retrieve the role with its permissions to validate its existence and what it grants
+	role, err := p.roleRepository.First(ctx, query.NewQuery().FilterById(_dto.RoleId).Include("Permissions"))
 	if err != nil {
 		return err
 	}
 
-	// Prevent creating invitations for super admin roles
-	if role.IsSuperAdmin() {
-		return errorx.ErrUserSuperAdminAssignmentForbidden
-	}
+	// Authorize inviting users to the role
+	return p.policyEngine.Authorize(ctx, permissionEnum.InvitationsStore, role, _dto)
+}
 
-	// Return nil if all validations pass
-	return nil
+// forbidSuperAdminInvitation prevents creating invitations for the roles granting every permission, like super_admin
+func forbidSuperAdminInvitation(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	if role, ok := request.Resource.(*entity.Role); ok && role.GrantsAllPermissions() {
+		return authz.Deny, errorx.ErrUserSuperAdminAssignmentForbidden
+	}
+	return authz.Abstain, nil
 }
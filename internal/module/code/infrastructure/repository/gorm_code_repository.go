@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
 	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
@@ -23,10 +24,10 @@ func NewGormCodeRepository(db *gorm.DB) repository.CodeRepository {
 	}
 }
 
-func (r *GormCodeRepository) Find(id string) (*entity.Code, error) {
+func (r *GormCodeRepository) Find(ctx context.Context, id string) (*entity.Code, error) {
 	var code entity.Code
 
-	if err := r.db.Where("id = ?", id).First(&code).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&code).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrCodeNotFound
 		}
@@ -35,9 +36,9 @@ func (r *GormCodeRepository) Find(id string) (*entity.Code, error) {
 	return &code, nil
 }
 
-func (r *GormCodeRepository) FindByValue(value string) (*entity.Code, error) {
+func (r *GormCodeRepository) FindByValue(ctx context.Context, value string) (*entity.Code, error) {
 	var code entity.Code
-	if err := r.db.Where("value = ?", value).First(&code).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("value = ?", value).First(&code).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrCodeNotFound
 		}
@@ -46,9 +47,9 @@ func (r *GormCodeRepository) FindByValue(value string) (*entity.Code, error) {
 	return &code, nil
 }
 
-func (r *GormCodeRepository) FindByTypeAndValue(_type enum.CodeType, value string) (*entity.Code, error) {
+func (r *GormCodeRepository) FindByTypeAndValue(ctx context.Context, _type enum.CodeType, value string) (*entity.Code, error) {
 	var code entity.Code
-	if err := r.db.Where("type = ? AND value = ?", _type, value).First(&code).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("type = ? AND value = ?", _type, value).First(&code).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrCodeNotFound
 		}
@@ -57,9 +58,9 @@ func (r *GormCodeRepository) FindByTypeAndValue(_type enum.CodeType, value strin
 	return &code, nil
 }
 
-func (r *GormCodeRepository) FindByCodeableAndTypeAndValue(codeableId string, codeableType string, _type enum.CodeType, value string) (*entity.Code, error) {
+func (r *GormCodeRepository) FindByCodeableAndTypeAndValue(ctx context.Context, codeableId string, codeableType string, _type enum.CodeType, value string) (*entity.Code, error) {
 	var code entity.Code
-	if err := r.db.Where(
+	if err := r.db.WithContext(ctx).Where(
 		"codeable_id = ? AND codeable_type = ? AND type = ? AND value = ?",
 		codeableId, codeableType, _type, value,
 	).First(&code).Error; err != nil {
@@ -71,8 +72,8 @@ func (r *GormCodeRepository) FindByCodeableAndTypeAndValue(codeableId string, co
 	return &code, nil
 }
 
-func (r *GormCodeRepository) Save(code *entity.Code) error {
-	err := r.db.Save(code).Error
+func (r *GormCodeRepository) Save(ctx context.Context, code *entity.Code) error {
+	err := r.db.WithContext(ctx).Save(code).Error
 	if err != nil {
 		if helper.IsPostgresDuplicateKeyError(err) {
 			return errorx.ErrCodeAlreadyExists
@@ -82,10 +83,10 @@ func (r *GormCodeRepository) Save(code *entity.Code) error {
 	return nil
 }
 
-func (r *GormCodeRepository) SaveMany(codes []*entity.Code) error {
-	return r.db.CreateInBatches(codes, 100).Error
+func (r *GormCodeRepository) SaveMany(ctx context.Context, codes []*entity.Code) error {
+	return r.db.WithContext(ctx).CreateInBatches(codes, 100).Error
 }
 
-func (r *GormCodeRepository) Destroy(code *entity.Code) error {
-	return r.db.Delete(code).Error
+func (r *GormCodeRepository) Destroy(ctx context.Context, code *entity.Code) error {
+	return r.db.WithContext(ctx).Delete(code).Error
 }
@@ -1,18 +1,16 @@
 package http
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
 	"github.com/arfanxn/welding/internal/infrastructure/http/response"
 	"github.com/arfanxn/welding/internal/module/code/presentation/http/request"
 	"github.com/arfanxn/welding/internal/module/code/usecase"
 	"github.com/arfanxn/welding/internal/module/code/usecase/dto"
-	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
-	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/gin-gonic/gin"
 )
 
@@ -46,21 +44,15 @@ func (h *codeHandler) CreateUserRegisterInvitation(c *gin.Context) {
 		ExpiredAt: expiredAt,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleNotFound) {
-			httperror.Panic(http.StatusNotFound, "Role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminAssignmentForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role "+string(roleEnum.SuperAdmin)+" tidak dapat ditambahkan ke undangan", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "Gagal membuat kode undangan", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrUserSuperAdminAssignmentForbidden: "error.code.invitation_super_admin_forbidden",
+			errorx.ErrCodeAlreadyExists:                 "error.code.invitation_already_exists",
+		})
 	}
 
 	c.JSON(http.StatusCreated, response.NewBodyWithData(
 		http.StatusCreated,
-		"Kode undangan registrasi berhasil dibuat",
+		helper.T(c, "message.code.invitation_created", nil),
 		gin.H{"code": code},
 	))
 }
@@ -74,15 +66,14 @@ func (h *codeHandler) CreateUserEmailVerification(c *gin.Context) {
 		&dto.CreateUserEmailVerification{Email: req.Email},
 	)
 	if err != nil {
-		if errors.Is(err, errorx.ErrCodeAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "Gagal membuat kode verifikasi email", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrCodeAlreadyExists: "error.code.verification_already_exists",
+		})
 	}
 
 	c.JSON(http.StatusCreated, response.NewBody(
 		http.StatusCreated,
-		"Kode verifikasi email berhasil dibuat dan dikirim ke email",
+		helper.T(c, "message.code.email_verification_created", nil),
 	))
 }
 
@@ -95,14 +86,13 @@ func (h *codeHandler) CreateUserResetPassword(c *gin.Context) {
 		&dto.CreateUserResetPassword{Email: req.Email},
 	)
 	if err != nil {
-		if errors.Is(err, errorx.ErrCodeAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "Gagal membuat kode reset password", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrCodeAlreadyExists: "error.code.reset_password_already_exists",
+		})
 	}
 
 	c.JSON(http.StatusCreated, response.NewBody(
 		http.StatusCreated,
-		"Kode reset password berhasil dibuat dan dikirim ke email",
+		helper.T(c, "message.code.reset_password_created", nil),
 	))
 }
@@ -14,13 +14,13 @@ type CreateUserRegisterInvitation struct {
 func (r *CreateUserRegisterInvitation) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.RoleId,
-			validation.Required.Error("Role Id wajib diisi"),
-			validation.Length(26, 26).Error("Role Id harus 26 karakter"),
+			validation.Required,
+			validation.Length(26, 26),
 		),
 		validation.Field(&r.ExpiredAt,
-			validation.Required.Error("Expired in wajib diisi"),
-			validation.Date(time.DateTime).Error("Format tanggal tidak valid. Gunakan format: YYYY-MM-DD HH:MM:SS"),
-			validation.Date(time.DateTime).Min(time.Now().AddDate(0, 0, 1)).Error("Expired date harus lebih dari hari ini"),
+			validation.Required,
+			validation.Date(time.DateTime),
+			validation.Date(time.DateTime).Min(time.Now().AddDate(0, 0, 1)),
 		),
 	)
 }
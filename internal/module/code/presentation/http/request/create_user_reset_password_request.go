@@ -12,9 +12,9 @@ type CreateUserResetPassword struct {
 func (r *CreateUserResetPassword) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Email,
-			validation.Required.Error("Email wajib diisi"),
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.EmailFormat.Error("Format email tidak valid"),
+			validation.Required,
+			validation.Length(3, 50),
+			is.EmailFormat,
 		),
 	)
 }
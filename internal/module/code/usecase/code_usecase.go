@@ -2,12 +2,17 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
 	"github.com/arfanxn/welding/internal/infrastructure/id"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
 	"github.com/arfanxn/welding/internal/infrastructure/mail"
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
+	auditLogEnum "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	auditLogDto "github.com/arfanxn/welding/internal/module/audit_log/usecase/dto"
+	auditLogService "github.com/arfanxn/welding/internal/module/audit_log/usecase/service"
 	"github.com/arfanxn/welding/internal/module/code/domain/enum"
 	"github.com/arfanxn/welding/internal/module/code/domain/repository"
 	"github.com/arfanxn/welding/internal/module/code/infrastructure/policy"
@@ -15,10 +20,18 @@ import (
 	"github.com/arfanxn/welding/internal/module/code/usecase/service"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	userRepository "github.com/arfanxn/welding/internal/module/user/domain/repository"
 	"github.com/guregu/null/v6"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 )
 
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/code/usecase")
+
+// codeLifetime is how long the codes mailed to an email address are valid
+const codeLifetime = 30 * time.Minute
+
 type CodeUsecase interface {
 	CreateUserRegisterInvitation(ctx context.Context, _dto *dto.CreateUserRegisterInvitation) (*entity.Code, error)
 	CreateUserEmailVerification(ctx context.Context, _dto *dto.CreateUserEmailVerification) (*entity.Code, error)
@@ -26,13 +39,17 @@ type CodeUsecase interface {
 }
 
 type codeUsecase struct {
-	idService      id.IdService
-	codeService    service.CodeService
-	logger         *logger.Logger
-	codePolicy     policy.CodePolicy
-	codeRepository repository.CodeRepository
-	roleRepository roleRepository.RoleRepository
-	mailService    mail.MailService
+	idService       id.IdService
+	codeService     service.CodeService
+	logger          *logger.Logger
+	codePolicy      policy.CodePolicy
+	codeRepository  repository.CodeRepository
+	roleRepository  roleRepository.RoleRepository
+	userRepository  userRepository.UserRepository
+	mailService     mail.MailService
+	translator      i18n.Translator
+	metricsService  metrics.MetricsService
+	auditLogService auditLogService.AuditLogService
 }
 
 func NewCodeUsecase(
@@ -42,16 +59,24 @@ func NewCodeUsecase(
 	codePolicy policy.CodePolicy,
 	codeRepository repository.CodeRepository,
 	roleRepository roleRepository.RoleRepository,
+	userRepository userRepository.UserRepository,
 	mailService mail.MailService,
+	translator i18n.Translator,
+	metricsService metrics.MetricsService,
+	auditLogService auditLogService.AuditLogService,
 ) CodeUsecase {
 	return &codeUsecase{
-		idService:      idService,
-		codeService:    codeService,
-		logger:         logger,
-		codePolicy:     codePolicy,
-		codeRepository: codeRepository,
-		roleRepository: roleRepository,
-		mailService:    mailService,
+		idService:       idService,
+		codeService:     codeService,
+		logger:          logger,
+		codePolicy:      codePolicy,
+		codeRepository:  codeRepository,
+		roleRepository:  roleRepository,
+		userRepository:  userRepository,
+		mailService:     mailService,
+		translator:      translator,
+		metricsService:  metricsService,
+		auditLogService: auditLogService,
 	}
 }
 
@@ -71,6 +96,9 @@ func NewCodeUsecase(
 //   - *entity.Code: The created invitation code
 //   - error: Any error that occurred during the process
 func (s *codeUsecase) CreateUserRegisterInvitation(ctx context.Context, _dto *dto.CreateUserRegisterInvitation) (*entity.Code, error) {
+	ctx, span := tracer.Start(ctx, "CodeUsecase.CreateUserRegisterInvitation")
+	defer span.End()
+
 	var err error
 
 	// Validate the invitation request
@@ -92,15 +120,31 @@ func (s *codeUsecase) CreateUserRegisterInvitation(ctx context.Context, _dto *dt
 	code.ExpiredAt = _dto.ExpiredAt
 
 	// Save the invitation code to the repository
-	err = s.codeRepository.Save(code)
+	err = s.codeRepository.Save(ctx, code)
 	if err != nil {
 		return nil, err
 	}
+	s.metricsService.IncCodeIssued(code.Type)
+
+	// The code value is left out, an audit log reader must not be able to redeem the invitation
+	s.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.CodeCreateUserRegisterInvitation,
+		TargetType: auditLogEnum.TargetCode,
+		TargetId:   code.Id,
+		After: map[string]any{
+			"type":       code.Type,
+			"meta":       code.Meta,
+			"expired_at": code.ExpiredAt,
+		},
+	})
 
 	return code, nil
 }
 
 func (s *codeUsecase) CreateUserEmailVerification(ctx context.Context, _dto *dto.CreateUserEmailVerification) (*entity.Code, error) {
+	ctx, span := tracer.Start(ctx, "CodeUsecase.CreateUserEmailVerification")
+	defer span.End()
+
 	var err error
 
 	code := &entity.Code{}
@@ -110,29 +154,23 @@ func (s *codeUsecase) CreateUserEmailVerification(ctx context.Context, _dto *dto
 	code.CodeableId = null.StringFrom(_dto.Email)
 	code.CodeableType = null.StringFrom("email")
 	code.SetMeta(nil)
-	code.ExpiredAt = time.Now().Add(time.Minute * 30)
+	code.ExpiredAt = time.Now().Add(codeLifetime)
 
-	err = s.codeRepository.Save(code)
+	err = s.codeRepository.Save(ctx, code)
 	if err != nil {
 		return nil, err
 	}
+	s.metricsService.IncCodeIssued(code.Type)
 
-	// TODO: move this to a job queue, and monitor the job queue
-	go func(email, subject, body string) {
-		err := s.mailService.Send([]string{email}, subject, body)
-		if err != nil {
-			s.logger.Error("Failed to send email verification code", zap.Error(err))
-		}
-	}(
-		code.CodeableId.String,
-		"Verifikasi Email", fmt.Sprintf("Kode verifikasi email Anda adalah %s, berlaku selama 30 menit.",
-			code.Value),
-	)
+	s.sendCode(ctx, code, "mail.user_email_verification.subject", "mail.user_email_verification.body")
 
 	return code, nil
 }
 
 func (s *codeUsecase) CreateUserResetPassword(ctx context.Context, _dto *dto.CreateUserResetPassword) (*entity.Code, error) {
+	ctx, span := tracer.Start(ctx, "CodeUsecase.CreateUserResetPassword")
+	defer span.End()
+
 	var err error
 
 	code := &entity.Code{}
@@ -142,24 +180,47 @@ func (s *codeUsecase) CreateUserResetPassword(ctx context.Context, _dto *dto.Cre
 	code.CodeableId = null.StringFrom(_dto.Email)
 	code.CodeableType = null.StringFrom("email")
 	code.SetMeta(nil)
-	code.ExpiredAt = time.Now().Add(time.Minute * 30)
+	code.ExpiredAt = time.Now().Add(codeLifetime)
 
-	err = s.codeRepository.Save(code)
+	err = s.codeRepository.Save(ctx, code)
 	if err != nil {
 		return nil, err
 	}
+	s.metricsService.IncCodeIssued(code.Type)
+
+	s.sendCode(ctx, code, "mail.user_reset_password.subject", "mail.user_reset_password.body")
+
+	return code, nil
+}
+
+// sendCode mails code to its email address the messages of subjectKey and bodyKey, in the preferred locale of
+// the user of the address, the default locale when there is none yet
+func (s *codeUsecase) sendCode(ctx context.Context, code *entity.Code, subjectKey string, bodyKey string) {
+	email := code.CodeableId.String
+
+	var locale string
+	user, err := s.userRepository.FindByEmail(ctx, email)
+	switch {
+	case err == nil:
+		locale = user.Locale.String
+	case !errors.Is(err, errorx.ErrUserNotFound):
+		s.logger.FromContext(ctx).Warn("Failed to find the locale of the code recipient", zap.Error(err))
+	}
+	params := map[string]any{
+		"code":    code.Value,
+		"minutes": int(codeLifetime.Minutes()),
+	}
 
 	// TODO: move this to a job queue, and monitor the job queue
-	go func(email, subject, body string) {
-		err := s.mailService.Send([]string{email}, subject, body)
+	go func(ctx context.Context, email, subject, body string) {
+		err := s.mailService.Send(ctx, []string{email}, subject, body)
 		if err != nil {
-			s.logger.Error("Failed to send reset password code", zap.Error(err))
+			s.logger.FromContext(ctx).Error("Failed to send code", zap.String("type", string(code.Type)), zap.Error(err))
 		}
 	}(
-		code.CodeableId.String,
-		"Reset Password", fmt.Sprintf("Kode reset password Anda adalah %s, berlaku selama 30 menit.",
-			code.Value),
+		context.WithoutCancel(ctx),
+		email,
+		s.translator.Translate(locale, subjectKey, params),
+		s.translator.Translate(locale, bodyKey, params),
 	)
-
-	return code, nil
 }
@@ -1,9 +1,13 @@
 package repository
 
-import "github.com/arfanxn/welding/internal/module/shared/domain/entity"
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+)
 
 type EmployeeRepository interface {
-	Save(employee *entity.Employee) error
-	SaveMany(employees []*entity.Employee) error
-	DestroyByUserId(userId string) error
+	Save(ctx context.Context, employee *entity.Employee) error
+	SaveMany(ctx context.Context, employees []*entity.Employee) error
+	DestroyByUserId(ctx context.Context, userId string) error
 }
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 
 	"github.com/arfanxn/welding/internal/module/employee/domain/repository"
@@ -21,9 +22,9 @@ func NewGormEmployeeRepository(db *gorm.DB) repository.EmployeeRepository {
 	}
 }
 
-func (r *GormEmployeeRepository) FindByUserId(userId string) (*entity.Employee, error) {
+func (r *GormEmployeeRepository) FindByUserId(ctx context.Context, userId string) (*entity.Employee, error) {
 	var employee entity.Employee
-	if err := r.db.Where("user_id = ?", userId).First(&employee).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).First(&employee).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrEmployeeNotFound
 		}
@@ -32,14 +33,14 @@ func (r *GormEmployeeRepository) FindByUserId(userId string) (*entity.Employee,
 	return &employee, nil
 }
 
-func (r *GormEmployeeRepository) Save(employee *entity.Employee) error {
-	return r.db.Save(employee).Error
+func (r *GormEmployeeRepository) Save(ctx context.Context, employee *entity.Employee) error {
+	return r.db.WithContext(ctx).Save(employee).Error
 }
 
-func (r *GormEmployeeRepository) SaveMany(employees []*entity.Employee) error {
-	return r.db.CreateInBatches(employees, 100).Error
+func (r *GormEmployeeRepository) SaveMany(ctx context.Context, employees []*entity.Employee) error {
+	return r.db.WithContext(ctx).CreateInBatches(employees, 100).Error
 }
 
-func (r *GormEmployeeRepository) DestroyByUserId(userId string) error {
-	return r.db.Where("user_id = ?", userId).Delete(&entity.Employee{}).Error
+func (r *GormEmployeeRepository) DestroyByUserId(ctx context.Context, userId string) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userId).Delete(&entity.Employee{}).Error
 }
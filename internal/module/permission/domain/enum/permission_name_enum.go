@@ -1,41 +1,195 @@
 package enum
 
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// PermissionName names a permission <resource>.<action>. A wildcard grants several permissions:
+// <resource>.* every action of the resource and * every permission.
 type PermissionName string
 
 const (
-	UsersIndex   PermissionName = "users.index"
-	UsersShow    PermissionName = "users.show"
-	UsersStore   PermissionName = "users.store"
-	UsersUpdate  PermissionName = "users.update"
-	UsersDestroy PermissionName = "users.destroy"
+	// All grants every permission, it is what the super_admin role holds
+	All PermissionName = "*"
 
+	UsersAll           PermissionName = "users.*"
+	UsersIndex         PermissionName = "users.index"
+	UsersShow          PermissionName = "users.show"
+	UsersStore         PermissionName = "users.store"
+	UsersUpdate        PermissionName = "users.update"
+	UsersDestroy       PermissionName = "users.destroy"
+	UsersAnyDepartment PermissionName = "users.any_department"
+
+	RolesAll     PermissionName = "roles.*"
 	RolesIndex   PermissionName = "roles.index"
 	RolesShow    PermissionName = "roles.show"
 	RolesStore   PermissionName = "roles.store"
 	RolesUpdate  PermissionName = "roles.update"
 	RolesDestroy PermissionName = "roles.destroy"
 
+	PermissionsAll   PermissionName = "permissions.*"
 	PermissionsIndex PermissionName = "permissions.index"
 	PermissionsShow  PermissionName = "permissions.show"
+
+	LogsAll    PermissionName = "logs.*"
+	LogsShow   PermissionName = "logs.show"
+	LogsUpdate PermissionName = "logs.update"
+
+	AuditLogsAll   PermissionName = "audit_logs.*"
+	AuditLogsIndex PermissionName = "audit_logs.index"
+
+	InvitationsAll   PermissionName = "invitations.*"
+	InvitationsStore PermissionName = "invitations.store"
 )
 
 func (p PermissionName) String() string {
 	return string(p)
 }
 
+// IsWildcard reports whether p is * or <resource>.*
+func (p PermissionName) IsWildcard() bool {
+	return p == All || strings.HasSuffix(string(p), ".*")
+}
+
+// Matches reports whether p is name or a wildcard covering it, e.g. users.* matches users.update
+func (p PermissionName) Matches(name PermissionName) bool {
+	if p == name || p == All {
+		return true
+	}
+	if resource, ok := strings.CutSuffix(string(p), "*"); ok && strings.HasSuffix(resource, ".") {
+		return strings.HasPrefix(string(name), resource)
+	}
+	return false
+}
+
+// Implied returns p and the permissions it implies transitively, those implied by the permissions
+// a wildcard matches included
+func (p PermissionName) Implied() []PermissionName {
+	implied := []PermissionName{p}
+	for i := 0; i < len(implied); i++ {
+		for _, name := range PermissionNames {
+			if !implied[i].Matches(name) {
+				continue
+			}
+			for _, impliedName := range implications[name] {
+				if !lo.Contains(implied, impliedName) {
+					implied = append(implied, impliedName)
+				}
+			}
+		}
+	}
+	return implied
+}
+
+// Grants reports whether holding p grants name, as p itself, by wildcard or by implication
+func (p PermissionName) Grants(name PermissionName) bool {
+	return lo.SomeBy(p.Implied(), func(implied PermissionName) bool {
+		return implied.Matches(name)
+	})
+}
+
+// Granted reports whether the held permissions grant every one of names
+func Granted(held []PermissionName, names ...PermissionName) bool {
+	return lo.EveryBy(names, func(name PermissionName) bool {
+		return lo.SomeBy(held, func(heldName PermissionName) bool { return heldName.Grants(name) })
+	})
+}
+
+// Resolve returns the declared permissions, wildcards aside, the held permissions grant
+func Resolve(held []PermissionName) []PermissionName {
+	return lo.Filter(PermissionNames, func(name PermissionName, _ int) bool {
+		return !name.IsWildcard() && Granted(held, name)
+	})
+}
+
+// PermissionMetadata describes a permission to the people granting it, it is synchronized
+// to the permissions table
+type PermissionMetadata struct {
+	Group       string
+	Description string
+}
+
+// Metadata returns the group and description of the permission, empty for an unknown permission
+func (p PermissionName) Metadata() PermissionMetadata {
+	return permissionMetadata[p]
+}
+
 var PermissionNames = []PermissionName{
+	All,
+
+	UsersAll,
 	UsersIndex,
 	UsersShow,
 	UsersStore,
 	UsersUpdate,
 	UsersDestroy,
+	UsersAnyDepartment,
 
+	RolesAll,
 	RolesIndex,
 	RolesShow,
 	RolesStore,
 	RolesUpdate,
 	RolesDestroy,
 
+	PermissionsAll,
 	PermissionsIndex,
 	PermissionsShow,
+
+	LogsAll,
+	LogsShow,
+	LogsUpdate,
+
+	AuditLogsAll,
+	AuditLogsIndex,
+
+	InvitationsAll,
+	InvitationsStore,
+}
+
+// implications lists the permissions a permission implies, an action on a resource implies viewing it
+var implications = map[PermissionName][]PermissionName{
+	UsersStore:   {InvitationsStore},
+	UsersUpdate:  {UsersShow},
+	UsersDestroy: {UsersShow},
+
+	RolesUpdate:  {RolesShow},
+	RolesDestroy: {RolesShow},
+
+	LogsUpdate: {LogsShow},
+}
+
+var permissionMetadata = map[PermissionName]PermissionMetadata{
+	All: {Group: "*", Description: "Every permission, including those added later"},
+
+	UsersAll:           {Group: "users", Description: "Every users permission"},
+	UsersIndex:         {Group: "users", Description: "List and search users"},
+	UsersShow:          {Group: "users", Description: "View a user"},
+	UsersStore:         {Group: "users", Description: "Create users, implies invitations.store"},
+	UsersUpdate:        {Group: "users", Description: "Update users and toggle their activation, implies users.show"},
+	UsersDestroy:       {Group: "users", Description: "Delete users, implies users.show"},
+	UsersAnyDepartment: {Group: "users", Description: "Create, update and delete the users of every department, not only of their own"},
+
+	RolesAll:     {Group: "roles", Description: "Every roles permission"},
+	RolesIndex:   {Group: "roles", Description: "List and search roles"},
+	RolesShow:    {Group: "roles", Description: "View a role and its permissions"},
+	RolesStore:   {Group: "roles", Description: "Create roles"},
+	RolesUpdate:  {Group: "roles", Description: "Update roles, their permissions and the default role, implies roles.show"},
+	RolesDestroy: {Group: "roles", Description: "Delete roles, implies roles.show"},
+
+	PermissionsAll:   {Group: "permissions", Description: "Every permissions permission"},
+	PermissionsIndex: {Group: "permissions", Description: "List and search permissions"},
+	PermissionsShow:  {Group: "permissions", Description: "View a permission"},
+
+	LogsAll:    {Group: "logs", Description: "Every logs permission"},
+	LogsShow:   {Group: "logs", Description: "View the log level"},
+	LogsUpdate: {Group: "logs", Description: "Change the log level at runtime, implies logs.show"},
+
+	AuditLogsAll:   {Group: "audit_logs", Description: "Every audit_logs permission"},
+	AuditLogsIndex: {Group: "audit_logs", Description: "List and filter the audit logs of administrative actions"},
+
+	InvitationsAll:   {Group: "invitations", Description: "Every invitations permission"},
+	InvitationsStore: {Group: "invitations", Description: "Invite users to register with a role"},
 }
@@ -1,18 +1,33 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
 )
 
 type PermissionRepository interface {
-	All() ([]*entity.Permission, error)
-	Get(q *query.Query) ([]*entity.Permission, error)
-	Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error)
-	Find(id string) (*entity.Permission, error)
-	FindByName(name string) (*entity.Permission, error)
-	FindByIds(ids []string) ([]*entity.Permission, error)
-	Save(permission *entity.Permission) error
-	SaveMany(permissions []*entity.Permission) error
+	All(ctx context.Context) ([]*entity.Permission, error)
+	Get(ctx context.Context, q *query.Query) ([]*entity.Permission, error)
+	Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error)
+	Find(ctx context.Context, id string) (*entity.Permission, error)
+	FindByName(ctx context.Context, name string) (*entity.Permission, error)
+	FindByIds(ctx context.Context, ids []string) ([]*entity.Permission, error)
+	Save(ctx context.Context, permission *entity.Permission) error
+	// Sync applies sync in one transaction
+	Sync(ctx context.Context, sync *PermissionSync) error
+}
+
+// PermissionSync is a synchronization of the permissions table to the permissions of the code
+type PermissionSync struct {
+	// Created are inserted, skipping those whose name is stored already
+	Created []*entity.Permission
+	// Updated are saved
+	Updated []*entity.Permission
+	// Pruned are deleted, which revokes them from every role
+	Pruned []*entity.Permission
+	// AllPermissionsRoleId is the role granted the * wildcard, none when empty
+	AllPermissionsRoleId string
 }
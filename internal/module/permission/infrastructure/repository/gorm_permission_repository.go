@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
+	"github.com/arfanxn/welding/internal/infrastructure/database"
 	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
+	"github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	"github.com/arfanxn/welding/internal/module/permission/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
@@ -16,17 +20,20 @@ var _ repository.PermissionRepository = (*GormPermissionRepository)(nil)
 
 type GormPermissionRepository struct {
 	db *gorm.DB
+	// readDB serves Get and Paginate, it may lag behind db when it is a read replica
+	readDB *gorm.DB
 }
 
-func NewGormPermissionRepository(db *gorm.DB) repository.PermissionRepository {
+func NewGormPermissionRepository(db *gorm.DB, readDB database.ReadDB) repository.PermissionRepository {
 	return &GormPermissionRepository{
-		db: db,
+		db:     db,
+		readDB: readDB.DB,
 	}
 }
 
-func (r *GormPermissionRepository) All() ([]*entity.Permission, error) {
+func (r *GormPermissionRepository) All(ctx context.Context) ([]*entity.Permission, error) {
 	var permissions []*entity.Permission
-	if err := r.db.Find(&permissions).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(&permissions).Error; err != nil {
 		return nil, err
 	}
 	return permissions, nil
@@ -50,10 +57,15 @@ func (r *GormPermissionRepository) query(db *gorm.DB, q *query.Query) *gorm.DB {
 // Get retrieves a list of permissions based on the provided query DTO.
 // It applies the scope function to the database query to filter and sort the results.
 // The modified *gorm.DB is returned with the applied scopes.
-func (r *GormPermissionRepository) Get(q *query.Query) ([]*entity.Permission, error) {
+func (r *GormPermissionRepository) Get(ctx context.Context, q *query.Query) ([]*entity.Permission, error) {
+	return r.get(r.readDB.WithContext(ctx), q)
+}
+
+// get retrieves the permissions matching q from db
+func (r *GormPermissionRepository) get(db *gorm.DB, q *query.Query) ([]*entity.Permission, error) {
 	var permissions []*entity.Permission
 
-	db := r.query(r.db, q)
+	db = r.query(db, q)
 
 	if err := db.Find(&permissions).Error; err != nil {
 		return nil, err
@@ -62,8 +74,8 @@ func (r *GormPermissionRepository) Get(q *query.Query) ([]*entity.Permission, er
 	return permissions, nil
 }
 
-func (r *GormPermissionRepository) Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error) {
-	db := r.db.Model(&entity.Permission{})
+func (r *GormPermissionRepository) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error) {
+	db := r.readDB.WithContext(ctx).Model(&entity.Permission{})
 
 	db = r.query(db, q)
 	pagination, err := helper.GormDBPaginateWithQuery[*entity.Permission](db, q)
@@ -73,9 +85,9 @@ func (r *GormPermissionRepository) Paginate(q *query.Query) (*pagination.OffsetP
 	return pagination, nil
 }
 
-func (r *GormPermissionRepository) Find(id string) (*entity.Permission, error) {
+func (r *GormPermissionRepository) Find(ctx context.Context, id string) (*entity.Permission, error) {
 	var permission entity.Permission
-	if err := r.db.Where("id = ?", id).First(&permission).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&permission).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrPermissionNotFound
 		}
@@ -84,9 +96,9 @@ func (r *GormPermissionRepository) Find(id string) (*entity.Permission, error) {
 	return &permission, nil
 }
 
-func (r *GormPermissionRepository) FindByName(name string) (*entity.Permission, error) {
+func (r *GormPermissionRepository) FindByName(ctx context.Context, name string) (*entity.Permission, error) {
 	var permission entity.Permission
-	if err := r.db.Where("name = ?", name).First(&permission).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&permission).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrPermissionNotFound
 		}
@@ -95,9 +107,9 @@ func (r *GormPermissionRepository) FindByName(name string) (*entity.Permission,
 	return &permission, nil
 }
 
-func (r *GormPermissionRepository) FindByIds(ids []string) ([]*entity.Permission, error) {
+func (r *GormPermissionRepository) FindByIds(ctx context.Context, ids []string) ([]*entity.Permission, error) {
 	var permissions []*entity.Permission
-	if err := r.db.Where("id IN (?)", ids).Find(&permissions).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id IN (?)", ids).Find(&permissions).Error; err != nil {
 		return nil, err
 	}
 	if len(permissions) != len(ids) {
@@ -106,8 +118,8 @@ func (r *GormPermissionRepository) FindByIds(ids []string) ([]*entity.Permission
 	return permissions, nil
 }
 
-func (r *GormPermissionRepository) Save(permission *entity.Permission) error {
-	err := r.db.Save(permission).Error
+func (r *GormPermissionRepository) Save(ctx context.Context, permission *entity.Permission) error {
+	err := r.db.WithContext(ctx).Save(permission).Error
 	if err != nil {
 		if helper.IsPostgresDuplicateKeyError(err) {
 			return errorx.ErrPermissionAlreadyExists
@@ -117,6 +129,60 @@ func (r *GormPermissionRepository) Save(permission *entity.Permission) error {
 	return nil
 }
 
-func (r *GormPermissionRepository) SaveMany(permissions []*entity.Permission) error {
-	return r.db.CreateInBatches(permissions, 100).Error
+func (r *GormPermissionRepository) Sync(ctx context.Context, sync *repository.PermissionSync) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Another instance may have inserted some of them in between
+		if len(sync.Created) > 0 {
+			if _, err := helper.GormDBCreateManyIgnoringConflicts(tx, sync.Created, "name"); err != nil {
+				return err
+			}
+		}
+
+		for _, permission := range sync.Updated {
+			if err := tx.Save(permission).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(sync.Pruned) > 0 {
+			if err := r.destroyMany(tx, sync.Pruned); err != nil {
+				return err
+			}
+		}
+
+		if sync.AllPermissionsRoleId != "" {
+			if err := r.grantAllPermissions(tx, sync.AllPermissionsRoleId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// destroyMany deletes permissions and increments the permission version of the users of the roles holding them
+func (r *GormPermissionRepository) destroyMany(tx *gorm.DB, permissions []*entity.Permission) error {
+	// Deleting the permissions revokes them from their roles and the roles inheriting from them, whose
+	// users are looked up while they still hold them
+	roleIds := tx.Session(&gorm.Session{NewDB: true}).
+		Table("permission_role").
+		Select("role_id").
+		Where("permission_id IN (?)", lo.Map(permissions, func(permission *entity.Permission, _ int) string {
+			return permission.Id
+		}))
+	if err := helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, roleIds))); err != nil {
+		return err
+	}
+
+	return tx.Delete(permissions).Error
+}
+
+// grantAllPermissions grants the * wildcard, found by name as another instance may have inserted it, to roleId
+func (r *GormPermissionRepository) grantAllPermissions(tx *gorm.DB, roleId string) error {
+	result := tx.Exec(`INSERT INTO permission_role (permission_id, role_id, created_at)
+		SELECT id, ?, CURRENT_TIMESTAMP FROM permissions WHERE name = ?
+		ON CONFLICT (permission_id, role_id) DO NOTHING`, roleId, enum.All)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, roleId)))
 }
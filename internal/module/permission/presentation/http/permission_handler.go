@@ -29,14 +29,14 @@ func (h *permissionHandler) Paginate(c *gin.Context) {
 	q := query.NewQuery()
 	c.ShouldBind(q)
 
-	op, err := h.permissionUsecase.Paginate(q)
+	op, err := h.permissionUsecase.Paginate(c.Request.Context(), q)
 	if err != nil {
 		panic(err)
 	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Permissions berhasil diambil",
+		helper.T(c, "message.permission.paginated", nil),
 		pagination.PPFromOP(op, helper.URLFromC(c)),
 	))
 }
@@ -1 +1,21 @@
 package dto
+
+import "github.com/arfanxn/welding/internal/module/shared/domain/entity"
+
+type SyncPermissions struct {
+	// Prune deletes the stored permissions missing from the code, they are only reported otherwise
+	Prune bool
+}
+
+type SyncPermissionsResult struct {
+	// Created lists the permissions of the code that were missing from the database
+	Created []*entity.Permission
+	// Updated lists the permissions whose group or description changed in the code
+	Updated []*entity.Permission
+	// Orphaned lists the stored permissions that no longer exist in the code
+	Orphaned []*entity.Permission
+	// Pruned tells whether the orphaned permissions were deleted
+	Pruned bool
+	// GrantedRole is the role the * wildcard was granted to, nil when the role held it already or is not seeded yet
+	GrantedRole *entity.Role
+}
@@ -1,26 +1,140 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/event"
+	"github.com/arfanxn/welding/internal/infrastructure/id"
+	"github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	"github.com/arfanxn/welding/internal/module/permission/domain/repository"
+	"github.com/arfanxn/welding/internal/module/permission/usecase/dto"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	sharedEvent "github.com/arfanxn/welding/internal/module/shared/domain/event"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
+	"github.com/samber/lo"
+	"go.uber.org/fx"
 )
 
+var _ PermissionUsecase = (*permissionUsecase)(nil)
+
 type PermissionUsecase interface {
-	Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error)
+	Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error)
+	// Sync makes the permissions table match enum.PermissionNames and grants the * wildcard to the
+	// super admin role when it misses it, all at once. Permissions inserted meanwhile, e.g. by another
+	// instance synchronizing on start, are left as they are.
+	Sync(ctx context.Context, _dto *dto.SyncPermissions) (*dto.SyncPermissionsResult, error)
 }
 
 type permissionUsecase struct {
+	idService            id.IdService
 	permissionRepository repository.PermissionRepository
+	roleRepository       roleRepository.RoleRepository
+	eventBus             event.EventBus
 }
 
-func NewPermissionUsecase(permissionRepository repository.PermissionRepository) PermissionUsecase {
+type NewPermissionUsecaseParams struct {
+	fx.In
+
+	IdService            id.IdService
+	PermissionRepository repository.PermissionRepository
+	RoleRepository       roleRepository.RoleRepository
+	EventBus             event.EventBus
+}
+
+func NewPermissionUsecase(params NewPermissionUsecaseParams) PermissionUsecase {
 	return &permissionUsecase{
-		permissionRepository: permissionRepository,
+		idService:            params.IdService,
+		permissionRepository: params.PermissionRepository,
+		roleRepository:       params.RoleRepository,
+		eventBus:             params.EventBus,
+	}
+}
+
+func (u *permissionUsecase) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error) {
+	return u.permissionRepository.Paginate(ctx, q)
+}
+
+func (u *permissionUsecase) Sync(ctx context.Context, _dto *dto.SyncPermissions) (*dto.SyncPermissionsResult, error) {
+	storedPermissions, err := u.permissionRepository.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storedPermissionMap := lo.KeyBy(storedPermissions, func(permission *entity.Permission) enum.PermissionName {
+		return permission.Name
+	})
+
+	result := &dto.SyncPermissionsResult{}
+	for _, name := range enum.PermissionNames {
+		metadata := name.Metadata()
+
+		permission, ok := storedPermissionMap[name]
+		if !ok {
+			result.Created = append(result.Created, &entity.Permission{
+				Id:          u.idService.Generate(),
+				Name:        name,
+				Group:       metadata.Group,
+				Description: metadata.Description,
+				CreatedAt:   time.Now(),
+			})
+			continue
+		}
+
+		if permission.Group == metadata.Group && permission.Description == metadata.Description {
+			continue
+		}
+		permission.Group = metadata.Group
+		permission.Description = metadata.Description
+		result.Updated = append(result.Updated, permission)
 	}
+
+	result.Orphaned = lo.Filter(storedPermissions, func(permission *entity.Permission, _ int) bool {
+		return !lo.Contains(enum.PermissionNames, permission.Name)
+	})
+
+	sync := &repository.PermissionSync{Created: result.Created, Updated: result.Updated}
+	if _dto.Prune {
+		sync.Pruned = result.Orphaned
+	}
+
+	// The super admin holds the * wildcard, which grants the permissions added since it was seeded as
+	// well. It is granted again when the role was seeded before the wildcard existed or had it revoked.
+	superAdminRole, err := u.findSuperAdminRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if superAdminRole != nil && !superAdminRole.GrantsAllPermissions() {
+		sync.AllPermissionsRoleId = superAdminRole.Id
+	}
+
+	if err := u.permissionRepository.Sync(ctx, sync); err != nil {
+		return nil, err
+	}
+
+	result.Pruned = len(sync.Pruned) > 0
+	if sync.AllPermissionsRoleId != "" {
+		result.GrantedRole = superAdminRole
+	}
+	if result.Pruned || result.GrantedRole != nil {
+		u.eventBus.Publish(ctx, sharedEvent.PermissionsChanged{})
+	}
+
+	return result, nil
 }
 
-func (u *permissionUsecase) Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.Permission], error) {
-	return u.permissionRepository.Paginate(q)
+// findSuperAdminRole returns the super admin role with its permissions, nil when it is not seeded yet
+func (u *permissionUsecase) findSuperAdminRole(ctx context.Context) (*entity.Role, error) {
+	role, err := u.roleRepository.FindByName(ctx, roleEnum.SuperAdmin.String())
+	if errors.Is(err, errorx.ErrRoleNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.roleRepository.First(ctx, query.NewQuery().FilterById(role.Id).Include("Permissions"))
 }
@@ -1,10 +1,14 @@
 package repository
 
-import "github.com/arfanxn/welding/internal/module/shared/domain/entity"
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+)
 
 type PermissionRoleRepository interface {
-	Save(permissionRole *entity.PermissionRole) error
-	SaveMany(permissionRoles []*entity.PermissionRole) error
-	DestroyByRoleId(roleId string) error
-	Destroy(permissionRole *entity.PermissionRole) error
+	Save(ctx context.Context, permissionRole *entity.PermissionRole) error
+	SaveMany(ctx context.Context, permissionRoles []*entity.PermissionRole) error
+	DestroyByRoleId(ctx context.Context, roleId string) error
+	Destroy(ctx context.Context, permissionRole *entity.PermissionRole) error
 }
@@ -1,15 +1,20 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	"github.com/arfanxn/welding/internal/module/permission_role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
 var _ repository.PermissionRoleRepository = (*GormPermissionRoleRepository)(nil)
 
+// GormPermissionRoleRepository increments the permission version of the users of the roles whose
+// permissions it changes, and of the roles inheriting from them
 type GormPermissionRoleRepository struct {
 	db *gorm.DB
 }
@@ -18,26 +23,44 @@ func NewGormPermissionRoleRepository(db *gorm.DB) repository.PermissionRoleRepos
 	return &GormPermissionRoleRepository{db: db}
 }
 
-func (r *GormPermissionRoleRepository) Save(permissionRole *entity.PermissionRole) error {
-	err := r.db.Save(permissionRole).Error
-	if err != nil {
-		if helper.IsPostgresDuplicateKeyError(err) {
-			return errorx.ErrPermissionRoleAlreadyExists
+func (r *GormPermissionRoleRepository) Save(ctx context.Context, permissionRole *entity.PermissionRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(permissionRole).Error; err != nil {
+			if helper.IsPostgresDuplicateKeyError(err) {
+				return errorx.ErrPermissionRoleAlreadyExists
+			}
+			return err
 		}
-		return err
-	}
-
-	return nil
+		return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, permissionRole.RoleId)))
+	})
 }
 
-func (r *GormPermissionRoleRepository) SaveMany(permissionRoles []*entity.PermissionRole) error {
-	return r.db.CreateInBatches(permissionRoles, 100).Error
+func (r *GormPermissionRoleRepository) SaveMany(ctx context.Context, permissionRoles []*entity.PermissionRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(permissionRoles, 100).Error; err != nil {
+			return err
+		}
+		roleIds := lo.Uniq(lo.Map(permissionRoles, func(permissionRole *entity.PermissionRole, _ int) string {
+			return permissionRole.RoleId
+		}))
+		return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, roleIds)))
+	})
 }
 
-func (r *GormPermissionRoleRepository) DestroyByRoleId(roleId string) error {
-	return r.db.Where("role_id = ?", roleId).Delete(&entity.PermissionRole{}).Error
+func (r *GormPermissionRoleRepository) DestroyByRoleId(ctx context.Context, roleId string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", roleId).Delete(&entity.PermissionRole{}).Error; err != nil {
+			return err
+		}
+		return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, roleId)))
+	})
 }
 
-func (r *GormPermissionRoleRepository) Destroy(permissionRole *entity.PermissionRole) error {
-	return r.db.Delete(permissionRole).Error
+func (r *GormPermissionRoleRepository) Destroy(ctx context.Context, permissionRole *entity.PermissionRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(permissionRole).Error; err != nil {
+			return err
+		}
+		return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, permissionRole.RoleId)))
+	})
 }
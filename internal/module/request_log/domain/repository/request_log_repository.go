@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+)
+
+type RequestLogRepository interface {
+	Save(ctx context.Context, requestLog *entity.RequestLog) error
+}
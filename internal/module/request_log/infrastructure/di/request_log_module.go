@@ -0,0 +1,12 @@
+package di
+
+import (
+	"github.com/arfanxn/welding/internal/module/request_log/infrastructure/repository"
+	"go.uber.org/fx"
+)
+
+var Module = fx.Module("request_log",
+	fx.Provide(
+		repository.NewRequestLogRepository,
+	),
+)
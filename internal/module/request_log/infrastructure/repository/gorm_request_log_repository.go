@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arfanxn/welding/internal/module/request_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"gorm.io/gorm"
+)
+
+var _ repository.RequestLogRepository = (*GormRequestLogRepository)(nil)
+
+type GormRequestLogRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRequestLogRepository(db *gorm.DB) repository.RequestLogRepository {
+	return &GormRequestLogRepository{
+		db: db,
+	}
+}
+
+func (r *GormRequestLogRepository) Save(ctx context.Context, requestLog *entity.RequestLog) error {
+	return r.db.WithContext(ctx).Create(requestLog).Error
+}
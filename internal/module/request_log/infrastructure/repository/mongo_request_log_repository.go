@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/module/request_log/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/fx"
+)
+
+var _ repository.RequestLogRepository = (*MongoRequestLogRepository)(nil)
+
+// mongoRequestLog is the document of a request log
+type mongoRequestLog struct {
+	Id           string    `bson:"_id"`
+	RequestId    string    `bson:"request_id"`
+	UserId       *string   `bson:"user_id"`
+	Method       string    `bson:"method"`
+	Route        string    `bson:"route"`
+	Path         string    `bson:"path"`
+	Status       int       `bson:"status"`
+	LatencyMs    int64     `bson:"latency_ms"`
+	ClientIp     string    `bson:"client_ip"`
+	UserAgent    string    `bson:"user_agent"`
+	ResponseSize int       `bson:"response_size"`
+	CreatedAt    time.Time `bson:"created_at"`
+}
+
+type MongoRequestLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMongoRequestLogRepository(lc fx.Lifecycle, mongoDB database.MongoDB) repository.RequestLogRepository {
+	r := &MongoRequestLogRepository{
+		collection: mongoDB.Collection("request_logs"),
+	}
+	lc.Append(fx.Hook{
+		OnStart: r.createIndexes,
+	})
+	return r
+}
+
+// createIndexes creates the indexes of the request logs queries, existing indexes are left untouched
+func (r *MongoRequestLogRepository) createIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "request_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+func (r *MongoRequestLogRepository) Save(ctx context.Context, requestLog *entity.RequestLog) error {
+	_, err := r.collection.InsertOne(ctx, &mongoRequestLog{
+		Id:           requestLog.Id,
+		RequestId:    requestLog.RequestId,
+		UserId:       requestLog.UserId.Ptr(),
+		Method:       requestLog.Method,
+		Route:        requestLog.Route,
+		Path:         requestLog.Path,
+		Status:       requestLog.Status,
+		LatencyMs:    requestLog.LatencyMs,
+		ClientIp:     requestLog.ClientIp,
+		UserAgent:    requestLog.UserAgent,
+		ResponseSize: requestLog.ResponseSize,
+		CreatedAt:    requestLog.CreatedAt,
+	})
+	return err
+}
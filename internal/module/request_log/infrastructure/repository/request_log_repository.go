@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"github.com/arfanxn/welding/internal/infrastructure/database"
+	"github.com/arfanxn/welding/internal/module/request_log/domain/repository"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// NewRequestLogRepository stores the request logs in Mongo when MONGO_DSN is configured, in Postgres otherwise
+func NewRequestLogRepository(lc fx.Lifecycle, db *gorm.DB, mongoDB database.MongoDB) repository.RequestLogRepository {
+	if mongoDB.IsConfigured() {
+		return NewMongoRequestLogRepository(lc, mongoDB)
+	}
+	return NewGormRequestLogRepository(db)
+}
@@ -1,22 +1,25 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
 )
 
 type RoleRepository interface {
-	All() ([]*entity.Role, error)
-	Get(*query.Query) ([]*entity.Role, error)
-	Paginate(*query.Query) (*pagination.OffsetPagination[*entity.Role], error)
-	First(*query.Query) (*entity.Role, error)
-	Find(id string) (*entity.Role, error)
-	FindDefault() (*entity.Role, error)
-	FindByIds(ids []string) ([]*entity.Role, error)
-	FindByName(name string) (*entity.Role, error)
-	Save(role *entity.Role) error
-	SetDefault(role *entity.Role) error
-	SaveMany(roles []*entity.Role) error
-	Destroy(role *entity.Role) error
+	All(ctx context.Context) ([]*entity.Role, error)
+	Get(ctx context.Context, q *query.Query) ([]*entity.Role, error)
+	Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Role], error)
+	First(ctx context.Context, q *query.Query) (*entity.Role, error)
+	Find(ctx context.Context, id string) (*entity.Role, error)
+	FindDefault(ctx context.Context) (*entity.Role, error)
+	FindByIds(ctx context.Context, ids []string) ([]*entity.Role, error)
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+	FindAncestors(ctx context.Context, ids []string) ([]*entity.Role, error)
+	Save(ctx context.Context, role *entity.Role) error
+	SetDefault(ctx context.Context, role *entity.Role) error
+	SaveMany(ctx context.Context, roles []*entity.Role) error
+	Destroy(ctx context.Context, role *entity.Role) error
 }
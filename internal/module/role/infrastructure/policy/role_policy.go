@@ -4,13 +4,18 @@ package policy
 
 import (
 	"context"
+	"errors"
 
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	permissionRepository "github.com/arfanxn/welding/internal/module/permission/domain/repository"
-	"github.com/arfanxn/welding/internal/module/role/domain/enum"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	roleDto "github.com/arfanxn/welding/internal/module/role/usecase/dto"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gookit/goutil"
+	"github.com/samber/lo"
 	"go.uber.org/fx"
 )
 
@@ -23,9 +28,28 @@ type RolePolicy interface {
 	Destroy(ctx context.Context, _dto *roleDto.DestroyRole) error
 }
 
+// RoleChange is the input of the roles.store and roles.update rules when a role is saved: its attributes,
+// the permissions it is granted and the roles it is given to inherit from
+type RoleChange struct {
+	Save        *roleDto.SaveRole
+	Permissions []*entity.Permission
+	// Lineage is the parent the role is given followed by the ancestors of the parent, with their
+	// permissions, empty when the parent is left as is or removed
+	Lineage []*entity.Role
+}
+
+// GrantsAllPermissions reports whether the change grants every permission (*) to the role, directly
+// or by inheritance
+func (c *RoleChange) GrantsAllPermissions() bool {
+	return grantsAllPermissions(c.Permissions) || lo.ContainsBy(c.Lineage, (*entity.Role).GrantsAllPermissions)
+}
+
 // rolePolicy implements the RolePolicy interface with concrete business rule validation logic.
-// It uses repositories to access role and permission data for validation purposes.
+// It delegates the authorization of role operations to the policy engine, with the rules it
+// registers for the role permissions, and uses repositories to access role and permission data.
 type rolePolicy struct {
+	// policyEngine evaluates the role operations against the role rules
+	policyEngine authz.PolicyEngine
 	// roleRepository provides access to role data for validation operations
 	roleRepository roleRepository.RoleRepository
 	// permissionRepository provides access to permission data for validation operations
@@ -37,62 +61,76 @@ type rolePolicy struct {
 type NewRolePolicyParams struct {
 	fx.In
 
+	// PolicyEngine dependency the role rules are registered to
+	PolicyEngine authz.PolicyEngine
 	// RoleRepository dependency for role data access
 	RoleRepository roleRepository.RoleRepository
 	// PermissionRepository dependency for permission data access
 	PermissionRepository permissionRepository.PermissionRepository
 }
 
-// NewRolePolicy creates a new instance of rolePolicy with the provided dependencies.
+// NewRolePolicy creates a new instance of rolePolicy with the provided dependencies and registers
+// the role rules to the policy engine.
 // It implements the RolePolicy interface and is typically used with dependency injection.
 func NewRolePolicy(params NewRolePolicyParams) RolePolicy {
+	params.PolicyEngine.Register(permissionEnum.RolesStore, protectSuperAdminRole)
+	params.PolicyEngine.Register(permissionEnum.RolesUpdate, protectDefaultRole, protectSuperAdminRole, forbidParentCycle)
+	params.PolicyEngine.Register(permissionEnum.RolesDestroy, protectDefaultRole, protectSuperAdminRole)
+
 	return &rolePolicy{
+		policyEngine:         params.PolicyEngine,
 		roleRepository:       params.RoleRepository,
 		permissionRepository: params.PermissionRepository,
 	}
 }
 
 // Store validates the business rules for creating a new role.
-// It prevents creation of restricted roles and validates permission assignments.
+// It prevents creation of restricted roles and validates permission and parent assignments.
 // Returns an error if any validation rule is violated.
 func (p *rolePolicy) Store(ctx context.Context, _dto *roleDto.SaveRole) error {
-	// Prevent creation of SuperAdmin role as it's a system-managed role
-	if *_dto.Name == enum.SuperAdmin {
-		return errorx.ErrRoleSuperAdminStoreForbidden
+	// Validate that all specified permission IDs exist and are assignable
+	permissions, err := p.validatePermissionAssignments(ctx, _dto.PermissionIds)
+	if err != nil {
+		return err
 	}
 
-	// Validate that all specified permission IDs exist and are assignable
-	if err := p.validatePermissionAssignments(_dto.PermissionIds); err != nil {
+	// Validate that the parent exists and resolve the roles the new role inherits from
+	lineage, err := p.findLineage(ctx, _dto.ParentId)
+	if err != nil {
 		return err
 	}
 
-	// All validations passed, role can be created
-	return nil
+	// Authorize the creation against the role rules
+	change := &RoleChange{Save: _dto, Permissions: permissions, Lineage: lineage}
+	return p.policyEngine.Authorize(ctx, permissionEnum.RolesStore, nil, change)
 }
 
 // Update validates the business rules for updating an existing role.
 // It verifies the role exists, prevents modification of restricted roles,
-// and validates permission assignments.
+// and validates permission and parent assignments, a parent cannot make the role inherit from itself.
 // Returns an error if any validation rule is violated.
 func (p *rolePolicy) Update(ctx context.Context, _dto *roleDto.SaveRole) error {
 	// Verify that the role exists before attempting to update it
-	role, err := p.roleRepository.Find(*_dto.Id)
+	role, err := p.findRole(ctx, *_dto.Id)
 	if err != nil {
 		return err
 	}
 
-	// Prevent modification of SuperAdmin role as it's a system-managed role
-	if role.IsSuperAdmin() {
-		return errorx.ErrRoleSuperAdminUpdateForbidden
+	// Validate that all specified permission IDs exist and are assignable
+	permissions, err := p.validatePermissionAssignments(ctx, _dto.PermissionIds)
+	if err != nil {
+		return err
 	}
 
-	// Validate that all specified permission IDs exist and are assignable
-	if err := p.validatePermissionAssignments(_dto.PermissionIds); err != nil {
+	// Validate that the parent exists and resolve the roles the role would inherit from
+	lineage, err := p.findLineage(ctx, _dto.ParentId)
+	if err != nil {
 		return err
 	}
 
-	// All validations passed, role can be updated
-	return nil
+	// Authorize the modification against the role rules
+	change := &RoleChange{Save: _dto, Permissions: permissions, Lineage: lineage}
+	return p.policyEngine.Authorize(ctx, permissionEnum.RolesUpdate, role, change)
 }
 
 // SetDefault validates the business rules for setting a role as the default role.
@@ -101,22 +139,13 @@ func (p *rolePolicy) Update(ctx context.Context, _dto *roleDto.SaveRole) error {
 // Returns an error if any validation rule is violated.
 func (p *rolePolicy) SetDefault(ctx context.Context, _dto *roleDto.SetDefaultRole) error {
 	// Verify that the role exists before attempting to set it as default
-	role, err := p.roleRepository.Find(_dto.Id)
+	role, err := p.findRole(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
 
-	// Prevent setting a role that's already the default (idempotent operation check)
-	if role.IsDefault {
-		return errorx.ErrRoleAlreadyDefault
-	}
-	// Prevent setting SuperAdmin role as default
-	if role.IsSuperAdmin() {
-		return errorx.ErrRoleSuperAdminSetDefaultForbidden
-	}
-
-	// All validations passed, role can be set as default
-	return nil
+	// Setting the default role modifies roles, it is authorized against the role rules of roles.update
+	return p.policyEngine.Authorize(ctx, permissionEnum.RolesUpdate, role, _dto)
 }
 
 // Destroy validates the business rules for deleting a role.
@@ -125,48 +154,133 @@ func (p *rolePolicy) SetDefault(ctx context.Context, _dto *roleDto.SetDefaultRol
 // Returns an error if any validation rule is violated.
 func (p *rolePolicy) Destroy(ctx context.Context, _dto *roleDto.DestroyRole) error {
 	// Verify that the role exists before attempting to delete it
-	role, err := p.roleRepository.Find(_dto.Id)
+	role, err := p.findRole(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
 
-	// Prevent deletion of default roles to maintain system integrity
-	if role.IsDefault {
-		return errorx.ErrRoleDefaultDestroyForbidden
+	// Authorize the deletion against the role rules
+	return p.policyEngine.Authorize(ctx, permissionEnum.RolesDestroy, role, _dto)
+}
+
+// ==================================================
+// Rules
+// ==================================================
+
+// protectDefaultRole prevents setting the default role as default again (idempotent operation check)
+// and deleting it, to maintain system integrity
+func protectDefaultRole(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	role, ok := request.Resource.(*entity.Role)
+	if !ok || !role.IsDefault {
+		return authz.Abstain, nil
+	}
+
+	switch request.Input.(type) {
+	case *roleDto.SetDefaultRole:
+		return authz.Deny, errorx.ErrRoleAlreadyDefault
+	case *roleDto.DestroyRole:
+		return authz.Deny, errorx.ErrRoleDefaultDestroyForbidden
+	}
+	return authz.Abstain, nil
+}
+
+// protectSuperAdminRole keeps every permission (*) to the system-managed SuperAdmin role: the roles
+// granting it cannot be modified, set as default or deleted, and it cannot be granted to another role,
+// directly or by making the role inherit from one granting it
+func protectSuperAdminRole(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	role, ok := request.Resource.(*entity.Role)
+	if !ok {
+		// Creation, the role is described by its input only
+		if change, ok := request.Input.(*RoleChange); ok && change.GrantsAllPermissions() {
+			return authz.Deny, errorx.ErrRoleSuperAdminStoreForbidden
+		}
+		return authz.Abstain, nil
 	}
 
-	// Prevent deletion of system-managed role (like SuperAdmin)
-	if role.IsSuperAdmin() {
-		return errorx.ErrRoleSuperAdminDestroyForbidden
+	if !role.GrantsAllPermissions() {
+		if change, ok := request.Input.(*RoleChange); ok && change.GrantsAllPermissions() {
+			return authz.Deny, errorx.ErrRoleSuperAdminUpdateForbidden
+		}
+		return authz.Abstain, nil
 	}
 
-	// All validations passed, role can be deleted
-	return nil
+	switch request.Input.(type) {
+	case *roleDto.SetDefaultRole:
+		return authz.Deny, errorx.ErrRoleSuperAdminSetDefaultForbidden
+	case *roleDto.DestroyRole:
+		return authz.Deny, errorx.ErrRoleSuperAdminDestroyForbidden
+	default:
+		return authz.Deny, errorx.ErrRoleSuperAdminUpdateForbidden
+	}
+}
+
+// forbidParentCycle prevents giving a role a parent that is the role itself or inherits from it, which
+// would make the role inherit from itself
+func forbidParentCycle(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	role, ok := request.Resource.(*entity.Role)
+	change, isChange := request.Input.(*RoleChange)
+	if !ok || !isChange {
+		return authz.Abstain, nil
+	}
+
+	if lo.ContainsBy(change.Lineage, func(ancestor *entity.Role) bool { return ancestor.Id == role.Id }) {
+		return authz.Deny, errorx.ErrRoleParentCycle
+	}
+	return authz.Abstain, nil
+}
+
+func grantsAllPermissions(permissions []*entity.Permission) bool {
+	return lo.ContainsBy(permissions, func(permission *entity.Permission) bool {
+		return permission.Name == permissionEnum.All
+	})
 }
 
 // ==================================================
 // Private helper methods
 // ==================================================
 
+// findRole returns the role with its permissions, which tell the roles granting every permission.
+func (p *rolePolicy) findRole(ctx context.Context, roleId string) (*entity.Role, error) {
+	return p.roleRepository.First(ctx, query.NewQuery().FilterById(roleId).Include("Permissions"))
+}
+
+// findLineage returns the parent of parentId with its permissions followed by its ancestors, none when
+// the parent is left as is or removed.
+// Returns ErrRoleParentNotFound if the parent does not exist.
+func (p *rolePolicy) findLineage(ctx context.Context, parentId *string) ([]*entity.Role, error) {
+	// Skip the lookup if no parent is specified (optional assignment)
+	if goutil.IsEmptyReal(parentId) {
+		return nil, nil
+	}
+
+	parent, err := p.findRole(ctx, *parentId)
+	if errors.Is(err, errorx.ErrRoleNotFound) {
+		return nil, errorx.ErrRoleParentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := p.roleRepository.FindAncestors(ctx, []string{parent.Id})
+	if err != nil {
+		return nil, err
+	}
+	return append([]*entity.Role{parent}, ancestors...), nil
+}
+
 // validatePermissionAssignments validates that all specified permission IDs exist in the system.
 // This ensures that permission assignments are valid and prevents orphaned references.
-// Returns an error if any permission ID is not found, or nil if all are valid.
-func (p *rolePolicy) validatePermissionAssignments(permissionIds []string) error {
+// Returns the permissions, or an error if any permission ID is not found.
+func (p *rolePolicy) validatePermissionAssignments(ctx context.Context, permissionIds []string) ([]*entity.Permission, error) {
 	// Skip validation if no permissions are specified (optional assignment)
 	if goutil.IsEmpty(permissionIds) {
-		return nil
+		return nil, nil
 	}
 
 	if goutil.IsEmpty(permissionIds[0]) {
-		return nil
+		return nil, nil
 	}
 
 	// Attempt to fetch all specified permissions to verify they exist
-	_, err := p.permissionRepository.FindByIds(permissionIds)
-	if err != nil {
-		return err
-	}
-
-	// All permission IDs are valid
-	return nil
+	return p.permissionRepository.FindByIds(ctx, permissionIds)
 }
@@ -0,0 +1,275 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleDto "github.com/arfanxn/welding/internal/module/role/usecase/dto"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	userService "github.com/arfanxn/welding/internal/module/user/usecase/service"
+	"github.com/samber/lo"
+)
+
+var (
+	allPermission       = &entity.Permission{Id: "permission-all", Name: permissionEnum.All}
+	usersShowPermission = &entity.Permission{Id: "permission-users-show", Name: permissionEnum.UsersShow}
+)
+
+// actor holds every role permission but the super admin one
+var actor = &authz.Actor{
+	User:        &entity.User{Id: "actor"},
+	Permissions: &userService.EffectivePermissions{PermissionNames: []permissionEnum.PermissionName{permissionEnum.RolesAll}},
+}
+
+// newRole returns the role id granted permissions
+func newRole(id string, isDefault bool, permissions ...*entity.Permission) *entity.Role {
+	return &entity.Role{Id: id, IsDefault: isDefault, Permissions: permissions}
+}
+
+// newChange saves the role id with permissions, inheriting from lineage
+func newChange(id *string, lineage []*entity.Role, permissions ...*entity.Permission) *RoleChange {
+	return &RoleChange{Save: &roleDto.SaveRole{Id: id}, Permissions: permissions, Lineage: lineage}
+}
+
+func TestRoleRules(t *testing.T) {
+	var (
+		superAdmin  = newRole("super-admin", false, allPermission)
+		customer    = newRole("customer", true, usersShowPermission)
+		staff       = newRole("staff", false, usersShowPermission)
+		engineer    = newRole("engineer", false)
+		staffId     = lo.ToPtr(staff.Id)
+		staffParent = []*entity.Role{engineer}
+	)
+
+	tests := []struct {
+		name    string
+		rule    authz.Rule
+		request *authz.Request
+		want    authz.Effect
+		wantErr error
+	}{
+		// protectDefaultRole
+		{
+			name:    "protectDefaultRole denies setting the default role as default again",
+			rule:    protectDefaultRole,
+			request: &authz.Request{Actor: actor, Resource: customer, Input: &roleDto.SetDefaultRole{Id: customer.Id}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleAlreadyDefault,
+		},
+		{
+			name:    "protectDefaultRole denies deleting the default role",
+			rule:    protectDefaultRole,
+			request: &authz.Request{Actor: actor, Resource: customer, Input: &roleDto.DestroyRole{Id: customer.Id}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleDefaultDestroyForbidden,
+		},
+		{
+			name:    "protectDefaultRole abstains on updating the default role",
+			rule:    protectDefaultRole,
+			request: &authz.Request{Actor: actor, Resource: customer, Input: newChange(lo.ToPtr(customer.Id), nil)},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectDefaultRole abstains on deleting another role",
+			rule:    protectDefaultRole,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: &roleDto.DestroyRole{Id: staff.Id}},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectDefaultRole abstains on creation",
+			rule:    protectDefaultRole,
+			request: &authz.Request{Actor: actor, Input: newChange(nil, nil)},
+			want:    authz.Abstain,
+		},
+
+		// protectSuperAdminRole
+		{
+			name:    "protectSuperAdminRole abstains on creating a role",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Input: newChange(nil, staffParent, usersShowPermission)},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectSuperAdminRole denies creating a role granted every permission",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Input: newChange(nil, nil, usersShowPermission, allPermission)},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminStoreForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole denies creating a role inheriting every permission",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Input: newChange(nil, []*entity.Role{engineer, superAdmin})},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminStoreForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole abstains on updating a role",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, staffParent, usersShowPermission)},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectSuperAdminRole denies granting a role every permission",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, nil, allPermission)},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminUpdateForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole denies making a role inherit every permission",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, []*entity.Role{superAdmin})},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminUpdateForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole abstains on deleting a role",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: &roleDto.DestroyRole{Id: staff.Id}},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectSuperAdminRole denies updating the super admin role",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: superAdmin, Input: newChange(lo.ToPtr(superAdmin.Id), nil)},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminUpdateForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole denies setting the super admin role as default",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: superAdmin, Input: &roleDto.SetDefaultRole{Id: superAdmin.Id}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminSetDefaultForbidden,
+		},
+		{
+			name:    "protectSuperAdminRole denies deleting the super admin role",
+			rule:    protectSuperAdminRole,
+			request: &authz.Request{Actor: actor, Resource: superAdmin, Input: &roleDto.DestroyRole{Id: superAdmin.Id}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleSuperAdminDestroyForbidden,
+		},
+
+		// forbidParentCycle
+		{
+			name:    "forbidParentCycle abstains on a parent outside the descendants",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, []*entity.Role{engineer, customer})},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "forbidParentCycle abstains when the parent is left as is",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, nil, usersShowPermission)},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "forbidParentCycle denies the role as its own parent",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, []*entity.Role{staff})},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleParentCycle,
+		},
+		{
+			name:    "forbidParentCycle denies a parent inheriting from the role",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: newChange(staffId, []*entity.Role{engineer, customer, staff})},
+			want:    authz.Deny,
+			wantErr: errorx.ErrRoleParentCycle,
+		},
+		{
+			name:    "forbidParentCycle abstains on creation",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Input: newChange(nil, []*entity.Role{engineer})},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "forbidParentCycle abstains on setting the default",
+			rule:    forbidParentCycle,
+			request: &authz.Request{Actor: actor, Resource: staff, Input: &roleDto.SetDefaultRole{Id: staff.Id}},
+			want:    authz.Abstain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule(context.Background(), tt.request)
+			if got != tt.want {
+				t.Errorf("effect = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRolePolicyRules evaluates the rules the role policy registers for each permission together,
+// down to the permission required when every rule abstains
+func TestRolePolicyRules(t *testing.T) {
+	engine := authz.NewPolicyEngine(authz.NewPolicyEngineParams{})
+	NewRolePolicy(NewRolePolicyParams{PolicyEngine: engine})
+
+	var (
+		superAdmin = newRole("super-admin", false, allPermission)
+		customer   = newRole("customer", true)
+		staff      = newRole("staff", false)
+	)
+
+	tests := []struct {
+		name    string
+		request *authz.Request
+		wantErr error
+	}{
+		{
+			name:    "creating a role",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesStore, Input: newChange(nil, []*entity.Role{staff}, usersShowPermission)},
+		},
+		{
+			name: "creating a role without roles.store",
+			request: &authz.Request{
+				Actor:      &authz.Actor{User: actor.User, Permissions: &userService.EffectivePermissions{}},
+				Permission: permissionEnum.RolesStore,
+				Input:      newChange(nil, nil),
+			},
+			wantErr: errorx.ErrAuthForbidden,
+		},
+		{
+			name:    "updating a role into a cycle",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesUpdate, Resource: staff, Input: newChange(lo.ToPtr(staff.Id), []*entity.Role{customer, staff})},
+			wantErr: errorx.ErrRoleParentCycle,
+		},
+		{
+			name:    "setting a role as default",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesUpdate, Resource: staff, Input: &roleDto.SetDefaultRole{Id: staff.Id}},
+		},
+		{
+			name:    "setting the default role as default",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesUpdate, Resource: customer, Input: &roleDto.SetDefaultRole{Id: customer.Id}},
+			wantErr: errorx.ErrRoleAlreadyDefault,
+		},
+		{
+			name:    "deleting the super admin role",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesDestroy, Resource: superAdmin, Input: &roleDto.DestroyRole{Id: superAdmin.Id}},
+			wantErr: errorx.ErrRoleSuperAdminDestroyForbidden,
+		},
+		{
+			name:    "deleting a role",
+			request: &authz.Request{Actor: actor, Permission: permissionEnum.RolesDestroy, Resource: staff, Input: &roleDto.DestroyRole{Id: staff.Id}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Evaluate(context.Background(), tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Evaluate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"errors"
 
+	"github.com/arfanxn/welding/internal/infrastructure/database"
 	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	"github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
@@ -16,17 +18,20 @@ var _ repository.RoleRepository = (*GormRoleRepository)(nil)
 
 type GormRoleRepository struct {
 	db *gorm.DB
+	// readDB serves Get and Paginate, it may lag behind db when it is a read replica
+	readDB *gorm.DB
 }
 
-func NewGormRoleRepository(db *gorm.DB) repository.RoleRepository {
+func NewGormRoleRepository(db *gorm.DB, readDB database.ReadDB) repository.RoleRepository {
 	return &GormRoleRepository{
-		db: db,
+		db:     db,
+		readDB: readDB.DB,
 	}
 }
 
-func (r *GormRoleRepository) All() ([]*entity.Role, error) {
+func (r *GormRoleRepository) All(ctx context.Context) ([]*entity.Role, error) {
 	var roles []*entity.Role
-	if err := r.db.Find(&roles).Error; err != nil {
+	if err := r.db.WithContext(ctx).Find(&roles).Error; err != nil {
 		return nil, err
 	}
 	return roles, nil
@@ -54,6 +59,10 @@ func (r *GormRoleRepository) query(db *gorm.DB, q *query.Query) *gorm.DB {
 		db = db.Preload("Users")
 	}
 
+	if q.GetInclude("parent") != nil {
+		db = db.Preload("Parent")
+	}
+
 	if sort := q.GetSort("name"); sort != nil {
 		db = db.Order(roleTableName + ".name " + sort.Order)
 	}
@@ -65,10 +74,15 @@ func (r *GormRoleRepository) query(db *gorm.DB, q *query.Query) *gorm.DB {
 	return db
 }
 
-func (r *GormRoleRepository) Get(q *query.Query) ([]*entity.Role, error) {
+func (r *GormRoleRepository) Get(ctx context.Context, q *query.Query) ([]*entity.Role, error) {
+	return r.get(r.readDB.WithContext(ctx), q)
+}
+
+// get retrieves the roles matching q from db
+func (r *GormRoleRepository) get(db *gorm.DB, q *query.Query) ([]*entity.Role, error) {
 	var roles []*entity.Role
 
-	db := r.query(r.db, q)
+	db = r.query(db, q)
 
 	if err := db.Find(&roles).Error; err != nil {
 		return nil, err
@@ -77,8 +91,8 @@ func (r *GormRoleRepository) Get(q *query.Query) ([]*entity.Role, error) {
 	return roles, nil
 }
 
-func (r *GormRoleRepository) Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.Role], error) {
-	db := r.db.Model(&entity.Role{})
+func (r *GormRoleRepository) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Role], error) {
+	db := r.readDB.WithContext(ctx).Model(&entity.Role{})
 
 	db = r.query(db, q)
 
@@ -89,8 +103,9 @@ func (r *GormRoleRepository) Paginate(q *query.Query) (*pagination.OffsetPaginat
 	return pagination, nil
 }
 
-func (r *GormRoleRepository) First(q *query.Query) (*entity.Role, error) {
-	roles, err := r.Get(q)
+func (r *GormRoleRepository) First(ctx context.Context, q *query.Query) (*entity.Role, error) {
+	// The primary is read, the result is often updated next and must not lag behind
+	roles, err := r.get(r.db.WithContext(ctx), q)
 	if err != nil {
 		return nil, err
 	}
@@ -101,9 +116,9 @@ func (r *GormRoleRepository) First(q *query.Query) (*entity.Role, error) {
 	return role, nil
 }
 
-func (r *GormRoleRepository) Find(id string) (*entity.Role, error) {
+func (r *GormRoleRepository) Find(ctx context.Context, id string) (*entity.Role, error) {
 	var role entity.Role
-	if err := r.db.Where("id = ?", id).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrRoleNotFound
 		}
@@ -112,9 +127,9 @@ func (r *GormRoleRepository) Find(id string) (*entity.Role, error) {
 	return &role, nil
 }
 
-func (r *GormRoleRepository) FindDefault() (*entity.Role, error) {
+func (r *GormRoleRepository) FindDefault(ctx context.Context) (*entity.Role, error) {
 	var role entity.Role
-	if err := r.db.Where("is_default = ?", true).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("is_default = ?", true).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrRoleDefaultNotConfigured
 		}
@@ -123,9 +138,10 @@ func (r *GormRoleRepository) FindDefault() (*entity.Role, error) {
 	return &role, nil
 }
 
-func (r *GormRoleRepository) FindByIds(ids []string) ([]*entity.Role, error) {
+// FindByIds returns the roles of ids with their permissions, ErrRolesNotFound when one of them is missing
+func (r *GormRoleRepository) FindByIds(ctx context.Context, ids []string) ([]*entity.Role, error) {
 	var roles []*entity.Role
-	if err := r.db.Where("id IN (?)", ids).Find(&roles).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Permissions").Where("id IN (?)", ids).Find(&roles).Error; err != nil {
 		return nil, err
 	}
 	if len(roles) != len(ids) {
@@ -134,9 +150,24 @@ func (r *GormRoleRepository) FindByIds(ids []string) ([]*entity.Role, error) {
 	return roles, nil
 }
 
-func (r *GormRoleRepository) FindByName(name string) (*entity.Role, error) {
+// FindAncestors returns the roles ids inherit from, their parents and the parents of those up to the roots,
+// with their permissions
+func (r *GormRoleRepository) FindAncestors(ctx context.Context, ids []string) ([]*entity.Role, error) {
+	var roles []*entity.Role
+	if len(ids) == 0 {
+		return roles, nil
+	}
+
+	db := r.db.WithContext(ctx)
+	if err := db.Preload("Permissions").Where("id IN (?)", helper.GormDBRoleAncestorIds(db, ids)).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *GormRoleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
 	var role entity.Role
-	if err := r.db.Where("name = ?", name).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrRoleNotFound
 		}
@@ -145,36 +176,77 @@ func (r *GormRoleRepository) FindByName(name string) (*entity.Role, error) {
 	return &role, nil
 }
 
-func (r *GormRoleRepository) Save(role *entity.Role) error {
-	err := r.db.Omit("Permissions").Save(role).Error
-	if err != nil {
-		if helper.IsPostgresDuplicateKeyError(err) {
-			return errorx.ErrRoleAlreadyExists
+func (r *GormRoleRepository) Save(ctx context.Context, role *entity.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		saved, err := helper.GormDBSaveVersioned(tx.Omit("Permissions", "Parent"), role, &role.Version)
+		if err != nil {
+			if helper.IsPostgresDuplicateKeyError(err) {
+				return errorx.ErrRoleAlreadyExists
+			}
+			return err
 		}
-		return err
-	}
-	return nil
+		if !saved {
+			return errorx.ErrRoleModified
+		}
+
+		// The users of the role are also authorized by its name, and those of the roles inheriting from it
+		// by its permissions
+		return helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, role.Id)))
+	})
 }
 
-func (r *GormRoleRepository) SetDefault(role *entity.Role) error {
-	// Set all roles to not default
-	if err := r.db.Model(&entity.Role{}).Where("id != ?", role.Id).Update("is_default", false).Error; err != nil {
-		return err
-	}
+func (r *GormRoleRepository) SetDefault(ctx context.Context, role *entity.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Set the previous default roles to not default
+		if err := tx.Model(&entity.Role{}).
+			Where("id != ? AND is_default", role.Id).
+			Updates(map[string]any{"is_default": false, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
 
-	// Set the specified role as default
-	role.IsDefault = true
-	if err := r.db.Save(role).Error; err != nil {
-		return err
-	}
+		// Set the specified role as default
+		role.IsDefault = true
+		saved, err := helper.GormDBSaveVersioned(tx.Omit("Permissions"), role, &role.Version)
+		if err != nil {
+			return err
+		}
+		if !saved {
+			return errorx.ErrRoleModified
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (r *GormRoleRepository) SaveMany(roles []*entity.Role) error {
-	return r.db.CreateInBatches(roles, 100).Error
+func (r *GormRoleRepository) SaveMany(ctx context.Context, roles []*entity.Role) error {
+	for _, role := range roles {
+		role.Version = max(role.Version, 1)
+	}
+	return r.db.WithContext(ctx).CreateInBatches(roles, 100).Error
 }
 
-func (r *GormRoleRepository) Destroy(role *entity.Role) error {
-	return r.db.Delete(role).Error
+func (r *GormRoleRepository) Destroy(ctx context.Context, role *entity.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleting the role revokes it from its users and its permissions from the users of the roles
+		// inheriting from it, who are looked up while they still do
+		if err := helper.GormDBIncrementPermissionVersion(tx, helper.GormDBRoleUserIds(tx, helper.GormDBRoleDescendantIds(tx, role.Id))); err != nil {
+			return err
+		}
+
+		// The children of the role become roots
+		if err := tx.Model(&entity.Role{}).
+			Where("parent_id = ?", role.Id).
+			Updates(map[string]any{"parent_id": nil, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+
+		destroyed, err := helper.GormDBDestroyVersioned(tx, role, role.Version)
+		if err != nil {
+			return err
+		}
+		if !destroyed {
+			return errorx.ErrRoleModified
+		}
+		return nil
+	})
 }
@@ -18,8 +18,8 @@ func NewSetDefaultRole() *SetDefaultRole {
 func (s *SetDefaultRole) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Id,
-			validation.Required.Error("Id tidak boleh kosong"),
-			validation.Length(26, 26).Error("Id harus 26 karakter"),
+			validation.Required,
+			validation.Length(26, 26),
 		),
 	)
 }
@@ -12,6 +12,7 @@ var _ request.Request = (*StoreRole)(nil)
 type StoreRole struct {
 	Name          string   `form:"name" json:"name"`
 	PermissionIds []string `form:"permission_id" json:"permissions" default:"[]"`
+	ParentId      *string  `form:"parent_id" json:"parent_id"`
 }
 
 func NewStoreRole() *StoreRole {
@@ -22,14 +23,18 @@ func NewStoreRole() *StoreRole {
 func (s *StoreRole) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Name,
-			validation.Required.Error("Nama tidak boleh kosong"),
-			validation.Length(3, 50).Error("Nama harus di antara 3 dan 50 karakter"),
+			validation.Required,
+			validation.Length(3, 50),
 		),
 		validation.Field(&s.PermissionIds,
 			validation.Each(
-				is.Alphanumeric.Error("Permission id hanya huruf dan angka yang diperbolehkan"),
-				validation.Length(26, 26).Error("Permission id harus 26 karakter"),
+				is.Alphanumeric,
+				validation.Length(26, 26),
 			),
 		),
+		validation.Field(&s.ParentId,
+			is.Alphanumeric,
+			validation.Length(26, 26),
+		),
 	)
 }
@@ -13,6 +13,7 @@ type UpdateRole struct {
 	Id            string   `form:"id" json:"id"`
 	Name          string   `form:"name" json:"name"`
 	PermissionIds []string `form:"permission_id" json:"permissions" default:"[]"`
+	ParentId      *string  `form:"parent_id" json:"parent_id"`
 }
 
 func NewUpdateRole() *UpdateRole {
@@ -23,17 +24,21 @@ func NewUpdateRole() *UpdateRole {
 func (s *UpdateRole) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Id,
-			validation.Required.Error("Id tidak boleh kosong"),
-			validation.Length(26, 26).Error("Id harus 26 karakter"),
+			validation.Required,
+			validation.Length(26, 26),
 		),
 		validation.Field(&s.Name,
-			validation.Length(3, 50).Error("Nama harus di antara 3 dan 50 karakter"),
+			validation.Length(3, 50),
 		),
 		validation.Field(&s.PermissionIds,
 			validation.Each(
-				is.Alphanumeric.Error("Permission id hanya huruf dan angka yang diperbolehkan"),
-				validation.Length(26, 26).Error("Permission id harus 26 karakter"),
+				is.Alphanumeric,
+				validation.Length(26, 26),
 			),
 		),
+		validation.Field(&s.ParentId,
+			is.Alphanumeric,
+			validation.Length(26, 26),
+		),
 	)
 }
@@ -1,7 +1,6 @@
 package http
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
@@ -10,8 +9,6 @@ import (
 	roleRequest "github.com/arfanxn/welding/internal/module/role/presentation/http/request"
 	"github.com/arfanxn/welding/internal/module/role/usecase"
 	roleDto "github.com/arfanxn/welding/internal/module/role/usecase/dto"
-	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
-	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/davecgh/go-spew/spew"
@@ -50,7 +47,7 @@ func (h *roleHandler) Paginate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Roles berhasil diambil",
+		helper.T(c, "message.role.paginated", nil),
 		pagination.PPFromOP(paginationDto, helper.URLFromC(c)),
 	))
 }
@@ -62,16 +59,15 @@ func (h *roleHandler) Show(c *gin.Context) {
 
 	role, err := h.roleUsecase.Show(c.Request.Context(), q)
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleNotFound) {
-			c.JSON(http.StatusNotFound, response.NewBody(http.StatusNotFound, "Role tidak ditemukan"))
-			return
-		}
 		panic(err)
 	}
+	if helper.NotModified(c, role.Version, role) {
+		return
+	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Role berhasil diambil",
+		helper.T(c, "message.role.shown", nil),
 		gin.H{"role": role},
 	))
 }
@@ -85,23 +81,15 @@ func (h *roleHandler) Store(c *gin.Context) {
 	role, err := h.roleUsecase.Store(c.Request.Context(), &roleDto.SaveRole{
 		Name:          &roleName,
 		PermissionIds: req.PermissionIds,
+		ParentId:      req.ParentId,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "Role sudah ada", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleSuperAdminStoreForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role super admin tidak dapat dibuat", nil)
-		}
-		if errors.Is(err, errorx.ErrPermissionsNotFound) {
-			httperror.Panic(http.StatusNotFound, "Satu atau lebih permission tidak ditemukan", nil)
-		}
 		panic(err)
 	}
 
 	c.JSON(http.StatusCreated, response.NewBodyWithData(
 		http.StatusCreated,
-		"Role berhasil disimpan",
+		helper.T(c, "message.role.stored", nil),
 		gin.H{"role": role},
 	))
 }
@@ -117,26 +105,17 @@ func (h *roleHandler) Update(c *gin.Context) {
 		Id:            &req.Id,
 		Name:          &roleName,
 		PermissionIds: req.PermissionIds,
+		ParentId:      req.ParentId,
+		Versions:      helper.IfMatchVersionsFromC(c),
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleNotFound) {
-			httperror.Panic(http.StatusNotFound, "Role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "Role sudah ada", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleSuperAdminUpdateForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role super admin tidak dapat diubah", nil)
-		}
-		if errors.Is(err, errorx.ErrPermissionsNotFound) {
-			httperror.Panic(http.StatusNotFound, "Satu atau lebih permission tidak ditemukan", nil)
-		}
 		panic(err)
 	}
 
+	c.Header("ETag", helper.ETag(role.Version, role))
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Role berhasil diperbarui",
+		helper.T(c, "message.role.updated", nil),
 		gin.H{"role": role},
 	))
 }
@@ -146,23 +125,18 @@ func (h *roleHandler) SetDefault(c *gin.Context) {
 	req.Id = c.Param("id")
 	helper.MustBindValidate(c, req)
 
-	role, err := h.roleUsecase.SetDefault(c.Request.Context(), &roleDto.SetDefaultRole{Id: req.Id})
+	role, err := h.roleUsecase.SetDefault(c.Request.Context(), &roleDto.SetDefaultRole{
+		Id:       req.Id,
+		Versions: helper.IfMatchVersionsFromC(c),
+	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleNotFound) {
-			httperror.Panic(http.StatusNotFound, "Role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleAlreadyDefault) {
-			httperror.Panic(http.StatusConflict, "Role sudah default", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleSuperAdminSetDefaultForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role super admin tidak dapat diset default", nil)
-		}
 		panic(err)
 	}
 
+	c.Header("ETag", helper.ETag(role.Version, role))
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Role berhasil diset default",
+		helper.T(c, "message.role.set_default", nil),
 		gin.H{"role": role},
 	))
 }
@@ -172,19 +146,13 @@ func (h *roleHandler) Destroy(c *gin.Context) {
 	req.Id = c.Param("id")
 	helper.MustBindValidate(c, req)
 
-	err := h.roleUsecase.Destroy(c.Request.Context(), &roleDto.DestroyRole{Id: req.Id})
+	err := h.roleUsecase.Destroy(c.Request.Context(), &roleDto.DestroyRole{
+		Id:       req.Id,
+		Versions: helper.IfMatchVersionsFromC(c),
+	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRoleNotFound) {
-			httperror.Panic(http.StatusNotFound, "Role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleDefaultDestroyForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role default tidak dapat dihapus", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleSuperAdminDestroyForbidden) {
-			httperror.Panic(http.StatusForbidden, "Role super admin tidak dapat dihapus", nil)
-		}
 		panic(err)
 	}
 
-	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, "Role berhasil dihapus"))
+	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, helper.T(c, "message.role.destroyed", nil)))
 }
@@ -6,12 +6,18 @@ type SaveRole struct {
 	Id            *string        `json:"id"`
 	Name          *enum.RoleName `json:"name"`
 	PermissionIds []string       `json:"permission_ids"` // permission id
+	// ParentId is the role to inherit the permissions of, an empty one removes the parent
+	ParentId *string `json:"parent_id"`
+	// Versions lists the versions the role is expected to be at (If-Match), any version when empty
+	Versions []int64 `json:"versions"`
 }
 
 type SetDefaultRole struct {
-	Id string `json:"id"`
+	Id       string  `json:"id"`
+	Versions []int64 `json:"versions"`
 }
 
 type DestroyRole struct {
-	Id string `json:"id"`
+	Id       string  `json:"id"`
+	Versions []int64 `json:"versions"`
 }
@@ -3,6 +3,10 @@ package usecase
 import (
 	"context"
 
+	"github.com/arfanxn/welding/internal/infrastructure/event"
+	auditLogEnum "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	auditLogDto "github.com/arfanxn/welding/internal/module/audit_log/usecase/dto"
+	auditLogService "github.com/arfanxn/welding/internal/module/audit_log/usecase/service"
 	permissionRepository "github.com/arfanxn/welding/internal/module/permission/domain/repository"
 	"github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/role/infrastructure/policy"
@@ -10,11 +14,17 @@ import (
 	roleDto "github.com/arfanxn/welding/internal/module/role/usecase/dto"
 	"github.com/arfanxn/welding/internal/module/role/usecase/step"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	sharedEvent "github.com/arfanxn/welding/internal/module/shared/domain/event"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/fx"
 )
 
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/role/usecase")
+
 type RoleUsecase interface {
 	Paginate(context.Context, *query.Query) (*pagination.OffsetPagination[*entity.Role], error)
 	Show(context.Context, *query.Query) (*entity.Role, error)
@@ -32,6 +42,8 @@ type roleUsecase struct {
 	rolePolicy           policy.RolePolicy
 	roleRepository       repository.RoleRepository
 	permissionRepository permissionRepository.PermissionRepository
+	auditLogService      auditLogService.AuditLogService
+	eventBus             event.EventBus
 }
 
 type NewRoleUsecaseParams struct {
@@ -42,6 +54,8 @@ type NewRoleUsecaseParams struct {
 	RolePolicy           policy.RolePolicy
 	RoleRepository       repository.RoleRepository
 	PermissionRepository permissionRepository.PermissionRepository
+	AuditLogService      auditLogService.AuditLogService
+	EventBus             event.EventBus
 }
 
 func NewRoleUsecase(params NewRoleUsecaseParams) RoleUsecase {
@@ -51,47 +65,147 @@ func NewRoleUsecase(params NewRoleUsecaseParams) RoleUsecase {
 		rolePolicy:           params.RolePolicy,
 		roleRepository:       params.RoleRepository,
 		permissionRepository: params.PermissionRepository,
+		auditLogService:      params.AuditLogService,
+		eventBus:             params.EventBus,
 	}
 }
 
 func (u *roleUsecase) Show(ctx context.Context, q *query.Query) (*entity.Role, error) {
-	return u.roleRepository.First(q)
+	ctx, span := tracer.Start(ctx, "RoleUsecase.Show")
+	defer span.End()
+
+	role, err := u.roleRepository.First(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.inheritPermissions(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
 }
 
 func (u *roleUsecase) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.Role], error) {
-	return u.roleRepository.Paginate(q)
+	ctx, span := tracer.Start(ctx, "RoleUsecase.Paginate")
+	defer span.End()
+
+	roles, err := u.roleRepository.Paginate(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.inheritPermissions(ctx, roles.Items...); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// inheritPermissions sets the inherited and granted permissions of the roles whose permissions are loaded,
+// looking up the ancestors of all of them at once
+func (u *roleUsecase) inheritPermissions(ctx context.Context, roles ...*entity.Role) error {
+	roles = lo.Filter(roles, func(role *entity.Role, _ int) bool { return role.Permissions != nil })
+	if len(roles) == 0 {
+		return nil
+	}
+
+	ancestors, err := u.roleRepository.FindAncestors(ctx, lo.Map(roles, func(role *entity.Role, _ int) string { return role.Id }))
+	if err != nil {
+		return err
+	}
+	ancestorMap := lo.KeyBy(ancestors, func(role *entity.Role) string { return role.Id })
+
+	for _, role := range roles {
+		role.InheritPermissions(role.Ancestors(ancestorMap))
+		role.ResolveGrantedPermissionNames()
+	}
+	return nil
 }
 
 func (u *roleUsecase) Store(ctx context.Context, _dto *dto.SaveRole) (*entity.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleUsecase.Store")
+	defer span.End()
+
 	if err := u.rolePolicy.Store(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	return u.storeRoleStep.Handle(ctx, _dto)
+	role, err := u.storeRoleStep.Handle(ctx, _dto)
+	if err != nil {
+		return nil, err
+	}
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.RoleStore,
+		TargetType: auditLogEnum.TargetRole,
+		TargetId:   role.Id,
+		After:      role,
+	})
+
+	return role, nil
 }
 
 func (u *roleUsecase) Update(ctx context.Context, _dto *roleDto.SaveRole) (*entity.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleUsecase.Update")
+	defer span.End()
+
 	if err := u.rolePolicy.Update(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	return u.updateRoleStep.Handle(ctx, _dto)
+	// The state before the update, with the permissions when the update may change them
+	q := query.NewQuery().FilterById(*_dto.Id)
+	if _dto.PermissionIds != nil {
+		q.Include("Permissions")
+	}
+	before, err := u.roleRepository.First(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := u.updateRoleStep.Handle(ctx, _dto)
+	if err != nil {
+		return nil, err
+	}
+	u.eventBus.Publish(ctx, sharedEvent.RoleChanged{RoleId: role.Id})
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.RoleUpdate,
+		TargetType: auditLogEnum.TargetRole,
+		TargetId:   role.Id,
+		Before:     before,
+		After:      role,
+	})
+
+	return role, nil
 }
 
 func (u *roleUsecase) SetDefault(ctx context.Context, _dto *roleDto.SetDefaultRole) (*entity.Role, error) {
+	ctx, span := tracer.Start(ctx, "RoleUsecase.SetDefault")
+	defer span.End()
+
 	if err := u.rolePolicy.SetDefault(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	role, err := u.roleRepository.Find(_dto.Id)
+	role, err := u.roleRepository.Find(ctx, _dto.Id)
 	if err != nil {
 		return nil, err
 	}
+	if !entity.MatchesVersion(role.Version, _dto.Versions) {
+		return nil, errorx.ErrRoleModified
+	}
+	before := *role
 
-	if err := u.roleRepository.SetDefault(role); err != nil {
+	if err := u.roleRepository.SetDefault(ctx, role); err != nil {
 		return nil, err
 	}
 
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.RoleSetDefault,
+		TargetType: auditLogEnum.TargetRole,
+		TargetId:   role.Id,
+		Before:     &before,
+		After:      role,
+	})
+
 	return role, nil
 }
 
@@ -100,10 +214,25 @@ func (u *roleUsecase) Destroy(ctx context.Context, _dto *roleDto.DestroyRole) er
 		return err
 	}
 
-	role, err := u.roleRepository.Find(_dto.Id)
+	role, err := u.roleRepository.Find(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
+	if !entity.MatchesVersion(role.Version, _dto.Versions) {
+		return errorx.ErrRoleModified
+	}
+
+	if err := u.roleRepository.Destroy(ctx, role); err != nil {
+		return err
+	}
+	u.eventBus.Publish(ctx, sharedEvent.RoleChanged{RoleId: role.Id})
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.RoleDestroy,
+		TargetType: auditLogEnum.TargetRole,
+		TargetId:   role.Id,
+		Before:     role,
+	})
 
-	return u.roleRepository.Destroy(role)
+	return nil
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gookit/goutil"
+	"github.com/guregu/null/v6"
 	"github.com/samber/lo"
 )
 
@@ -36,14 +37,20 @@ func NewStoreRoleStep(
 }
 
 func (s *storeRoleStep) Handle(ctx context.Context, _dto *dto.SaveRole) (*entity.Role, error) {
+	ctx, span := tracer.Start(ctx, "StoreRoleStep.Handle")
+	defer span.End()
+
 	q := query.NewQuery()
 	role := &entity.Role{}
 	role.Id = s.idService.Generate()
 	role.Name = *_dto.Name
+	if !goutil.IsEmptyReal(_dto.ParentId) {
+		role.ParentId = null.StringFrom(*_dto.ParentId)
+	}
 
 	q.FilterById(role.Id)
 
-	if err := s.roleRepository.Save(role); err != nil {
+	if err := s.roleRepository.Save(ctx, role); err != nil {
 		return nil, err
 	}
 
@@ -55,13 +62,13 @@ func (s *storeRoleStep) Handle(ctx context.Context, _dto *dto.SaveRole) (*entity
 				return &entity.PermissionRole{RoleId: role.Id, PermissionId: permId}
 			})
 
-			if err := s.permissionRoleRepository.SaveMany(prs); err != nil {
+			if err := s.permissionRoleRepository.SaveMany(ctx, prs); err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	role, err := s.roleRepository.First(q)
+	role, err := s.roleRepository.First(ctx, q)
 	if err != nil {
 		return nil, err
 	}
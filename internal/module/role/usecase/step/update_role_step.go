@@ -8,8 +8,10 @@ import (
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/role/usecase/dto"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gookit/goutil"
+	"github.com/guregu/null/v6"
 	"github.com/samber/lo"
 )
 
@@ -36,28 +38,39 @@ func NewUpdateRoleStep(
 }
 
 func (s *updateRoleStep) Handle(ctx context.Context, _dto *dto.SaveRole) (*entity.Role, error) {
+	ctx, span := tracer.Start(ctx, "UpdateRoleStep.Handle")
+	defer span.End()
+
 	q := query.NewQuery().FilterById(*_dto.Id)
 
 	if _dto.PermissionIds != nil {
 		q.Include("Permissions")
 	}
 
-	role, err := s.roleRepository.First(q)
+	role, err := s.roleRepository.First(ctx, q)
 	if err != nil {
 		return nil, err
 	}
+	if !entity.MatchesVersion(role.Version, _dto.Versions) {
+		return nil, errorx.ErrRoleModified
+	}
 
 	if !goutil.IsEmptyReal(_dto.Name) {
 		role.Name = *_dto.Name
 	}
 
-	if err := s.roleRepository.Save(role); err != nil {
+	// An empty parent removes it, the role stops inheriting
+	if _dto.ParentId != nil {
+		role.ParentId = null.NewString(*_dto.ParentId, *_dto.ParentId != "")
+	}
+
+	if err := s.roleRepository.Save(ctx, role); err != nil {
 		return nil, err
 	}
 
 	if _dto.PermissionIds != nil {
 		// Remove all existing role associations for this user
-		if err := s.permissionRoleRepository.DestroyByRoleId(role.Id); err != nil {
+		if err := s.permissionRoleRepository.DestroyByRoleId(ctx, role.Id); err != nil {
 			return nil, err
 		}
 
@@ -66,13 +79,13 @@ func (s *updateRoleStep) Handle(ctx context.Context, _dto *dto.SaveRole) (*entit
 				return &entity.PermissionRole{RoleId: role.Id, PermissionId: permId}
 			})
 
-			if err := s.permissionRoleRepository.SaveMany(prs); err != nil {
+			if err := s.permissionRoleRepository.SaveMany(ctx, prs); err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	role, err = s.roleRepository.First(q)
+	role, err = s.roleRepository.First(ctx, q)
 	if err != nil {
 		return nil, err
 	}
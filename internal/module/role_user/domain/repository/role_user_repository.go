@@ -1,11 +1,31 @@
 package repository
 
-import "github.com/arfanxn/welding/internal/module/shared/domain/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/guregu/null/v6"
+)
 
 type RoleUserRepository interface {
-	Save(role *entity.RoleUser) error
-	SaveMany(roles []*entity.RoleUser) error
-	DestroyByUserId(userId string) error
-	Destroy(roleUser *entity.RoleUser) error
-	DestroyMany(roleUsers []*entity.RoleUser) error
+	// Find returns the grant of the role to the user, in effect or not, ErrRoleUserNotFound when there is none
+	Find(ctx context.Context, roleId string, userId string) (*entity.RoleUser, error)
+	// GetBeginning returns the temporary grants in effect at now whose beginning was not announced yet,
+	// with their role and user
+	GetBeginning(ctx context.Context, now time.Time) ([]*entity.RoleUser, error)
+	// GetExpired returns the temporary grants expired at now, with their role and user
+	GetExpired(ctx context.Context, now time.Time) ([]*entity.RoleUser, error)
+	// NextChangeAt returns when the next grant of the user begins or expires after now, null when none will
+	NextChangeAt(ctx context.Context, userId string, now time.Time) (null.Time, error)
+	// MarkBegan records that the beginning of the grant was announced. It returns false when it already was.
+	MarkBegan(ctx context.Context, roleUser *entity.RoleUser) (bool, error)
+	// DestroyExpired deletes the grant while it is still the expired one. It returns false when it changed
+	// or was already deleted.
+	DestroyExpired(ctx context.Context, roleUser *entity.RoleUser) (bool, error)
+	Save(ctx context.Context, role *entity.RoleUser) error
+	SaveMany(ctx context.Context, roles []*entity.RoleUser) error
+	DestroyByUserId(ctx context.Context, userId string) error
+	Destroy(ctx context.Context, roleUser *entity.RoleUser) error
+	DestroyMany(ctx context.Context, roleUsers []*entity.RoleUser) error
 }
@@ -2,11 +2,15 @@ package di
 
 import (
 	"github.com/arfanxn/welding/internal/module/role_user/infrastructure/repository"
+	"github.com/arfanxn/welding/internal/module/role_user/infrastructure/scheduler"
+	"github.com/arfanxn/welding/internal/module/role_user/usecase/service"
 	"go.uber.org/fx"
 )
 
 var Module = fx.Module("role_user",
 	fx.Provide(
 		repository.NewGormRoleUserRepository,
+		service.NewRoleGrantService,
 	),
+	fx.Invoke(scheduler.RegisterRoleGrantScheduler),
 )
@@ -1,13 +1,22 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	"github.com/arfanxn/welding/internal/module/role_user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/guregu/null/v6"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
 var _ repository.RoleUserRepository = (*GormRoleUserRepository)(nil)
 
+// GormRoleUserRepository increments the permission version of the users whose roles it changes
 type GormRoleUserRepository struct {
 	db *gorm.DB
 }
@@ -18,22 +27,141 @@ func NewGormRoleUserRepository(db *gorm.DB) repository.RoleUserRepository {
 	}
 }
 
-func (r *GormRoleUserRepository) Save(roleUser *entity.RoleUser) error {
-	return r.db.Save(roleUser).Error
+func (r *GormRoleUserRepository) Find(ctx context.Context, roleId string, userId string) (*entity.RoleUser, error) {
+	var roleUser entity.RoleUser
+	err := helper.GormDBAllRoleGrants(r.db.WithContext(ctx)).
+		Where("role_id = ? AND user_id = ?", roleId, userId).
+		First(&roleUser).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorx.ErrRoleUserNotFound
+		}
+		return nil, err
+	}
+	return &roleUser, nil
+}
+
+func (r *GormRoleUserRepository) GetBeginning(ctx context.Context, now time.Time) ([]*entity.RoleUser, error) {
+	var roleUsers []*entity.RoleUser
+	err := helper.GormDBAllRoleGrants(r.db.WithContext(ctx)).
+		Preload("Role").
+		Preload("User").
+		Where("expires_at > ? AND starts_at <= ? AND began_at IS NULL", now, now).
+		Find(&roleUsers).Error
+	if err != nil {
+		return nil, err
+	}
+	return roleUsers, nil
+}
+
+func (r *GormRoleUserRepository) GetExpired(ctx context.Context, now time.Time) ([]*entity.RoleUser, error) {
+	var roleUsers []*entity.RoleUser
+	err := helper.GormDBAllRoleGrants(r.db.WithContext(ctx)).
+		Preload("Role").
+		Preload("User").
+		Where("expires_at <= ?", now).
+		Find(&roleUsers).Error
+	if err != nil {
+		return nil, err
+	}
+	return roleUsers, nil
+}
+
+func (r *GormRoleUserRepository) NextChangeAt(ctx context.Context, userId string, now time.Time) (null.Time, error) {
+	var nextChangeAt null.Time
+	err := helper.GormDBAllRoleGrants(r.db.WithContext(ctx)).
+		Model(&entity.RoleUser{}).
+		Select("MIN(CASE WHEN starts_at > ? THEN starts_at ELSE expires_at END)", now).
+		Where("user_id = ? AND (starts_at > ? OR expires_at > ?)", userId, now, now).
+		Scan(&nextChangeAt).Error
+	return nextChangeAt, err
 }
 
-func (r *GormRoleUserRepository) SaveMany(roleUsers []*entity.RoleUser) error {
-	return r.db.CreateInBatches(roleUsers, 100).Error
+func (r *GormRoleUserRepository) MarkBegan(ctx context.Context, roleUser *entity.RoleUser) (bool, error) {
+	began := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		beganAt := time.Now()
+		result := tx.Model(roleUser).Where("began_at IS NULL").Update("began_at", beganAt)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		roleUser.BeganAt = null.TimeFrom(beganAt)
+		began = true
+
+		// The role is now held, the permissions resolved before are stale
+		return helper.GormDBIncrementPermissionVersion(tx, []string{roleUser.UserId})
+	})
+	return began, err
+}
+
+func (r *GormRoleUserRepository) DestroyExpired(ctx context.Context, roleUser *entity.RoleUser) (bool, error) {
+	destroyed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at = ?", roleUser.ExpiresAt).Delete(roleUser)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		destroyed = true
+		return helper.GormDBIncrementPermissionVersion(tx, []string{roleUser.UserId})
+	})
+	return destroyed, err
+}
+
+func (r *GormRoleUserRepository) Save(ctx context.Context, roleUser *entity.RoleUser) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(roleUser).Error; err != nil {
+			return err
+		}
+		return helper.GormDBIncrementPermissionVersion(tx, []string{roleUser.UserId})
+	})
 }
 
-func (r *GormRoleUserRepository) DestroyByUserId(userId string) error {
-	return r.db.Delete(&entity.RoleUser{}, "user_id = ?", userId).Error
+func (r *GormRoleUserRepository) SaveMany(ctx context.Context, roleUsers []*entity.RoleUser) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(roleUsers, 100).Error; err != nil {
+			return err
+		}
+		return r.incrementPermissionVersion(tx, roleUsers)
+	})
 }
 
-func (r *GormRoleUserRepository) Destroy(roleUser *entity.RoleUser) error {
-	return r.db.Delete(roleUser).Error
+func (r *GormRoleUserRepository) DestroyByUserId(ctx context.Context, userId string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.RoleUser{}, "user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		return helper.GormDBIncrementPermissionVersion(tx, []string{userId})
+	})
 }
 
-func (r *GormRoleUserRepository) DestroyMany(roleUsers []*entity.RoleUser) error {
-	return r.db.Delete(roleUsers).Error
+func (r *GormRoleUserRepository) Destroy(ctx context.Context, roleUser *entity.RoleUser) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(roleUser).Error; err != nil {
+			return err
+		}
+		return helper.GormDBIncrementPermissionVersion(tx, []string{roleUser.UserId})
+	})
+}
+
+func (r *GormRoleUserRepository) DestroyMany(ctx context.Context, roleUsers []*entity.RoleUser) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(roleUsers).Error; err != nil {
+			return err
+		}
+		return r.incrementPermissionVersion(tx, roleUsers)
+	})
+}
+
+// incrementPermissionVersion increments the permission version of the users of roleUsers, in batches
+// bounding the number of bound ids
+func (r *GormRoleUserRepository) incrementPermissionVersion(tx *gorm.DB, roleUsers []*entity.RoleUser) error {
+	userIds := lo.Uniq(lo.Map(roleUsers, func(roleUser *entity.RoleUser, _ int) string {
+		return roleUser.UserId
+	}))
+	for _, chunk := range lo.Chunk(userIds, 1000) {
+		if err := helper.GormDBIncrementPermissionVersion(tx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
 }
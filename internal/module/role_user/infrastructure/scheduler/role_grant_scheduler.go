@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/module/role_user/usecase/service"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// RegisterRoleGrantScheduler periodically begins and ends the due temporary role grants while the
+// application is running
+func RegisterRoleGrantScheduler(lc fx.Lifecycle, cfg *config.Config, roleGrantService service.RoleGrantService, logger *logger.Logger) {
+	if cfg.RoleGrantCheckInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.RoleGrantCheckInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case now := <-ticker.C:
+						if err := roleGrantService.ProcessDue(ctx, now); err != nil {
+							logger.Warn("Failed to process due role grants", zap.Error(err))
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/event"
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
+	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/infrastructure/mail"
+	auditLogEnum "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	auditLogDto "github.com/arfanxn/welding/internal/module/audit_log/usecase/dto"
+	auditLogService "github.com/arfanxn/welding/internal/module/audit_log/usecase/service"
+	"github.com/arfanxn/welding/internal/module/role_user/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	sharedEvent "github.com/arfanxn/welding/internal/module/shared/domain/event"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/role_user/usecase/service")
+
+// roleGrantTimeLayout formats the expiry of a grant in the mails to its user
+const roleGrantTimeLayout = "2006-01-02 15:04 MST"
+
+// RoleGrantService announces the beginning and the end of the temporary role grants: the user is
+// notified by mail and an audit log is recorded. A grant holds its role only while it is in effect
+// whether it was announced or not, the announcements follow.
+type RoleGrantService interface {
+	// Begin announces the beginning of grant, once only
+	Begin(ctx context.Context, grant *entity.RoleUser) error
+	// End revokes the expired grant and announces it, once only
+	End(ctx context.Context, grant *entity.RoleUser) error
+	// ProcessDue begins the grants in effect at now not announced yet and ends the grants expired at now
+	ProcessDue(ctx context.Context, now time.Time) error
+}
+
+var _ RoleGrantService = (*roleGrantService)(nil)
+
+type roleGrantService struct {
+	roleUserRepository repository.RoleUserRepository
+	auditLogService    auditLogService.AuditLogService
+	mailService        mail.MailService
+	translator         i18n.Translator
+	eventBus           event.EventBus
+	logger             *logger.Logger
+}
+
+type NewRoleGrantServiceParams struct {
+	fx.In
+
+	RoleUserRepository repository.RoleUserRepository
+	AuditLogService    auditLogService.AuditLogService
+	MailService        mail.MailService
+	Translator         i18n.Translator
+	EventBus           event.EventBus
+	Logger             *logger.Logger
+}
+
+func NewRoleGrantService(params NewRoleGrantServiceParams) RoleGrantService {
+	return &roleGrantService{
+		roleUserRepository: params.RoleUserRepository,
+		auditLogService:    params.AuditLogService,
+		mailService:        params.MailService,
+		translator:         params.Translator,
+		eventBus:           params.EventBus,
+		logger:             params.Logger,
+	}
+}
+
+func (s *roleGrantService) Begin(ctx context.Context, grant *entity.RoleUser) error {
+	ctx, span := tracer.Start(ctx, "RoleGrantService.Begin")
+	defer span.End()
+
+	// Another instance may have announced it in between
+	began, err := s.roleUserRepository.MarkBegan(ctx, grant)
+	if err != nil || !began {
+		return err
+	}
+	s.eventBus.Publish(ctx, sharedEvent.UserRolesChanged{UserId: grant.UserId})
+
+	s.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserRoleGrantBegin,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   grant.UserId,
+		After:      grant,
+	})
+	s.notify(ctx, grant, "mail.role_grant_began.subject", "mail.role_grant_began.body")
+
+	return nil
+}
+
+func (s *roleGrantService) End(ctx context.Context, grant *entity.RoleUser) error {
+	ctx, span := tracer.Start(ctx, "RoleGrantService.End")
+	defer span.End()
+
+	// Another instance may have revoked it, or the grant may have been renewed, in between
+	destroyed, err := s.roleUserRepository.DestroyExpired(ctx, grant)
+	if err != nil || !destroyed {
+		return err
+	}
+	s.eventBus.Publish(ctx, sharedEvent.UserRolesChanged{UserId: grant.UserId})
+
+	s.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserRoleGrantEnd,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   grant.UserId,
+		Before:     grant,
+	})
+	s.notify(ctx, grant, "mail.role_grant_ended.subject", "mail.role_grant_ended.body")
+
+	return nil
+}
+
+func (s *roleGrantService) ProcessDue(ctx context.Context, now time.Time) error {
+	ctx, span := tracer.Start(ctx, "RoleGrantService.ProcessDue")
+	defer span.End()
+
+	beginning, err := s.roleUserRepository.GetBeginning(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, grant := range beginning {
+		if err := s.Begin(ctx, grant); err != nil {
+			return err
+		}
+	}
+
+	expired, err := s.roleUserRepository.GetExpired(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, grant := range expired {
+		if err := s.End(ctx, grant); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notify mails the user of grant the messages of subjectKey and bodyKey, in their preferred locale. The
+// role and the user of grant must be loaded.
+func (s *roleGrantService) notify(ctx context.Context, grant *entity.RoleUser, subjectKey string, bodyKey string) {
+	if grant.User == nil || grant.Role == nil {
+		return
+	}
+
+	locale := grant.User.Locale.String
+	params := map[string]any{
+		"role":       grant.Role.Name,
+		"expires_at": grant.ExpiresAt.Time.Format(roleGrantTimeLayout),
+		"reason":     grant.Reason.String,
+	}
+
+	// TODO: move this to a job queue, and monitor the job queue
+	go func(ctx context.Context, email, subject, body string) {
+		err := s.mailService.Send(ctx, []string{email}, subject, body)
+		if err != nil {
+			s.logger.FromContext(ctx).Error("Failed to send role grant notification", zap.Error(err))
+		}
+	}(
+		context.WithoutCancel(ctx),
+		grant.User.Email,
+		s.translator.Translate(locale, subjectKey, params),
+		s.translator.Translate(locale, bodyKey, params),
+	)
+}
@@ -10,4 +10,20 @@ const (
 	ClaimsKey ContextKey = "claims"
 	// UserKey is the context key for user object
 	UserKey ContextKey = "user"
+	// RequestIdKey is the context key for the request ID
+	RequestIdKey ContextKey = "request_id"
+	// LoggerKey is the context key for the request-scoped logger
+	LoggerKey ContextKey = "logger"
+	// LocaleKey is the context key for the locale the response is translated to
+	LocaleKey ContextKey = "locale"
+	// TranslatorKey is the context key for the i18n translator
+	TranslatorKey ContextKey = "translator"
+	// SchemeKey is the context key for the request scheme (http or https) as seen by the client
+	SchemeKey ContextKey = "scheme"
+	// ClientIpKey is the context key for the IP address of the client, resolved through the trusted proxies
+	ClientIpKey ContextKey = "client_ip"
+	// UserAgentKey is the context key for the User-Agent header of the request
+	UserAgentKey ContextKey = "user_agent"
+	// IfMatchKey is the context key for the resource versions listed in the If-Match header
+	IfMatchKey ContextKey = "if_match"
 )
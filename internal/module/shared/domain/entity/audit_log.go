@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	"github.com/guregu/null/v6"
+	"gorm.io/datatypes"
+)
+
+// AuditLog records an administrative action, with the state of its target before and after it.
+// Audit logs are append-only, they are never updated nor deleted.
+type AuditLog struct {
+	Id         string                  `json:"id" gorm:"primaryKey"`
+	ActorId    null.String             `json:"actor_id"`
+	Action     enum.AuditLogAction     `json:"action"`
+	TargetType enum.AuditLogTargetType `json:"target_type"`
+	TargetId   string                  `json:"target_id"`
+	Before     datatypes.JSON          `json:"before" gorm:"type:jsonb"`
+	After      datatypes.JSON          `json:"after" gorm:"type:jsonb"`
+	IpAddress  string                  `json:"ip_address"`
+	UserAgent  string                  `json:"user_agent"`
+	RequestId  string                  `json:"request_id"`
+	CreatedAt  time.Time               `json:"created_at"`
+}
+
+// TableName specifies the table name for the AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
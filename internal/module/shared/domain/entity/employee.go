@@ -6,11 +6,14 @@ import (
 	"github.com/guregu/null/v6"
 )
 
+// Employee is the employment of a user. Its department limits who may manage the user to the same
+// department, unless they hold users.any_department.
 type Employee struct {
-	UserId                   string    `json:"user_id" gorm:"primaryKey"`
-	EmploymentIdentityNumber string    `json:"employment_identity_number"`
-	CreatedAt                time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt                null.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	UserId                   string      `json:"user_id" gorm:"primaryKey"`
+	EmploymentIdentityNumber string      `json:"employment_identity_number"`
+	Department               null.String `json:"department"`
+	CreatedAt                time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt                null.Time   `json:"updated_at" gorm:"autoUpdateTime"`
 
 	User *User `json:"user,omitempty" gorm:"foreignKey:UserId;references:Id"`
 }
@@ -7,9 +7,11 @@ import (
 )
 
 type Permission struct {
-	Id        string              `json:"id" gorm:"primarykey"`
-	Name      enum.PermissionName `json:"name"`
-	CreatedAt time.Time           `json:"created_at" gorm:"autoCreateTime"`
+	Id          string              `json:"id" gorm:"primarykey"`
+	Name        enum.PermissionName `json:"name"`
+	Group       string              `json:"group" gorm:"column:group_name"`
+	Description string              `json:"description"`
+	CreatedAt   time.Time           `json:"created_at" gorm:"autoCreateTime"`
 
 	Roles []*Role `json:"roles,omitempty" gorm:"many2many:permission_role"`
 }
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/guregu/null/v6"
+)
+
+// RequestLog records a served HTTP request. Request logs are append-only.
+type RequestLog struct {
+	Id           string      `json:"id" gorm:"primaryKey"`
+	RequestId    string      `json:"request_id"`
+	UserId       null.String `json:"user_id"`
+	Method       string      `json:"method"`
+	Route        string      `json:"route"`
+	Path         string      `json:"path"`
+	Status       int         `json:"status"`
+	LatencyMs    int64       `json:"latency_ms"`
+	ClientIp     string      `json:"client_ip"`
+	UserAgent    string      `json:"user_agent"`
+	ResponseSize int         `json:"response_size"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// TableName specifies the table name for the RequestLog model
+func (RequestLog) TableName() string {
+	return "request_logs"
+}
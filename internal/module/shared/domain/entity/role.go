@@ -1,21 +1,35 @@
 package entity
 
 import (
+	"slices"
 	"time"
 
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	"github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/guregu/null/v6"
+	"github.com/samber/lo"
 )
 
+// Role grants its permissions to its users. A role may have a parent, whose permissions it inherits
+// along with those the parent inherits itself.
 type Role struct {
 	Id        string        `json:"id" gorm:"primarykey;not null;unique;type:varchar(26);index"`
 	Name      enum.RoleName `json:"name" gorm:"unique;not null;type:varchar(50);index"`
 	IsDefault bool          `json:"is_default" gorm:"default:false"`
+	ParentId  null.String   `json:"parent_id" gorm:"type:char(26)"`
+	Version   int64         `json:"version"`
 	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt null.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 
 	Users       []*User       `json:"users,omitempty" gorm:"many2many:role_user"`
+	Parent      *Role         `json:"parent,omitempty" gorm:"foreignKey:ParentId"`
 	Permissions []*Permission `json:"permissions,omitempty" gorm:"many2many:permission_role"`
+
+	// InheritedPermissions are the permissions of the ancestors of the role it is not granted directly
+	InheritedPermissions []*Permission `json:"inherited_permissions,omitempty" gorm:"-"`
+	// GrantedPermissionNames are the permissions the role grants directly or by inheritance, through
+	// their wildcards and implications
+	GrantedPermissionNames []permissionEnum.PermissionName `json:"granted_permission_names,omitempty" gorm:"-"`
 }
 
 func NewRole() *Role {
@@ -26,12 +40,58 @@ func (u *Role) TableName() string {
 	return "roles"
 }
 
-func (r *Role) IsSuperAdmin() bool {
-	return r.Name == enum.SuperAdmin
+// GrantsAllPermissions reports whether the role is granted *, like super_admin. Its permissions must be loaded.
+func (r *Role) GrantsAllPermissions() bool {
+	return lo.ContainsBy(r.Permissions, func(permission *Permission) bool {
+		return permission.Name == permissionEnum.All
+	})
+}
+
+// Ancestors returns the parent of the role, its parent and so on, looked up in roles by id. The walk
+// stops at a role missing from roles or already walked, so a cycle cannot loop.
+func (r *Role) Ancestors(roles map[string]*Role) []*Role {
+	var ancestors []*Role
+	visited := map[string]bool{r.Id: true}
+	for parentId := r.ParentId; parentId.Valid && !visited[parentId.String]; {
+		parent, ok := roles[parentId.String]
+		if !ok {
+			break
+		}
+		visited[parent.Id] = true
+		ancestors = append(ancestors, parent)
+		parentId = parent.ParentId
+	}
+	return ancestors
+}
+
+// InheritPermissions sets InheritedPermissions from the permissions of ancestors, which must be loaded
+func (r *Role) InheritPermissions(ancestors []*Role) {
+	r.InheritedPermissions = []*Permission{}
+	for _, ancestor := range ancestors {
+		for _, permission := range ancestor.Permissions {
+			inherited := lo.ContainsBy(r.Permissions, func(p *Permission) bool { return p.Id == permission.Id }) ||
+				lo.ContainsBy(r.InheritedPermissions, func(p *Permission) bool { return p.Id == permission.Id })
+			if !inherited {
+				r.InheritedPermissions = append(r.InheritedPermissions, permission)
+			}
+		}
+	}
+}
+
+// ResolveGrantedPermissionNames sets GrantedPermissionNames from the direct and inherited permissions,
+// when they are loaded
+func (r *Role) ResolveGrantedPermissionNames() {
+	if r.Permissions == nil {
+		return
+	}
+	permissions := append(slices.Clone(r.Permissions), r.InheritedPermissions...)
+	r.GrantedPermissionNames = permissionEnum.Resolve(lo.Map(permissions, func(permission *Permission, _ int) permissionEnum.PermissionName {
+		return permission.Name
+	}))
 }
 
 func (r *Role) IsUpdateable() bool {
-	return !r.IsSuperAdmin()
+	return !r.GrantsAllPermissions()
 }
 
 func (r *Role) IsSaveable() bool {
@@ -39,5 +99,5 @@ func (r *Role) IsSaveable() bool {
 }
 
 func (r *Role) IsDestroyable() bool {
-	return !r.IsSuperAdmin() && !r.IsDefault
+	return !r.GrantsAllPermissions() && !r.IsDefault
 }
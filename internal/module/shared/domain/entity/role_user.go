@@ -6,17 +6,34 @@ import (
 	"github.com/guregu/null/v6"
 )
 
+// RoleUser grants a role to a user. A grant given an expiry is temporary: it is in effect from StartsAt
+// until ExpiresAt, and only while it is in effect the user holds the role.
 type RoleUser struct {
-	RoleId    string    `json:"role_id" gorm:"primaryKey"`
-	UserId    string    `json:"user_id"`
+	RoleId    string      `json:"role_id" gorm:"primaryKey"`
+	UserId    string      `json:"user_id" gorm:"primaryKey"`
+	StartsAt  time.Time   `json:"starts_at" gorm:"default:CURRENT_TIMESTAMP"`
+	ExpiresAt null.Time   `json:"expires_at"`
+	Reason    null.String `json:"reason"`
+	// BeganAt is when the beginning of a temporary grant was announced to its user
+	BeganAt   null.Time `json:"-"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt null.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
-	Role *Role `gorm:"foreignKey:RoleId;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	User *User `gorm:"foreignKey:UserId;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Role *Role `json:"role,omitempty" gorm:"foreignKey:RoleId;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserId;references:id;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 }
 
 // TableName specifies the table name for the RoleUser model
 func (RoleUser) TableName() string {
 	return "role_user"
 }
+
+// IsTemporary reports whether the grant expires
+func (ru *RoleUser) IsTemporary() bool {
+	return ru.ExpiresAt.Valid
+}
+
+// IsInEffect reports whether the user holds the role at t
+func (ru *RoleUser) IsInEffect(t time.Time) bool {
+	return !ru.StartsAt.After(t) && (!ru.ExpiresAt.Valid || ru.ExpiresAt.Time.After(t))
+}
@@ -15,8 +15,15 @@ type User struct {
 	Password        string    `json:"-"`
 	ActivatedAt     null.Time `json:"activated_at"`
 	DeactivatedAt   null.Time `json:"deactivated_at"`
-	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt       null.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// Locale is the preferred language of API messages, it takes precedence over Accept-Language
+	Locale null.String `json:"locale"`
+	// Version is incremented on every update, it guards against lost updates and derives the ETag
+	Version int64 `json:"version"`
+	// PermissionVersion is incremented whenever the roles of the user or their permissions change, it is
+	// written by those changes only and tells the permissions resolved before them are stale
+	PermissionVersion int64     `json:"-" gorm:"->"`
+	CreatedAt         time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         null.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Relations
 	Roles    []*Role   `json:"roles,omitempty" gorm:"many2many:role_user"`
@@ -24,6 +31,7 @@ type User struct {
 
 	// Joins
 	EmploymentIdentityNumber *null.String `json:"employment_identity_number,omitempty" gorm:"->"`
+	Department               *null.String `json:"department,omitempty" gorm:"->"`
 }
 
 func NewUser() *User {
@@ -55,3 +63,12 @@ func (u User) IsEmailVerified() bool {
 func (u User) IsActive() bool {
 	return u.ActivatedAt.Valid && !u.DeactivatedAt.Valid
 }
+
+// DepartmentName returns the department of the employee, null when the user is not an employee or has none.
+// It is read from the Department join.
+func (u User) DepartmentName() null.String {
+	if u.Department == nil {
+		return null.String{}
+	}
+	return *u.Department
+}
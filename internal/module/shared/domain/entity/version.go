@@ -0,0 +1,9 @@
+package entity
+
+import "slices"
+
+// MatchesVersion reports whether version is one of the versions a client expects the resource to be at.
+// Any version matches when none is expected.
+func MatchesVersion(version int64, expected []int64) bool {
+	return len(expected) == 0 || slices.Contains(expected, version)
+}
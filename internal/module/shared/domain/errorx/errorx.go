@@ -1,124 +1,225 @@
 package errorx
 
+// Errorx is a domain error with a stable, machine readable code such as code.already_used.
+// Unlike the message, the code never changes, so clients can branch on it safely.
 type Errorx interface {
 	Error() string
+	Code() string
 }
 
 type errorx struct {
-	s string
+	code string
+	s    string
 }
 
-func New(s string) Errorx {
-	return &errorx{s: s}
+// registry holds every error created with New, in declaration order
+var registry []Errorx
+
+// New creates an error with the given stable code and message and registers it
+func New(code string, s string) Errorx {
+	e := &errorx{code: code, s: s}
+	registry = append(registry, e)
+	return e
+}
+
+// All returns every registered error, e.g. to verify that each one is mapped or translated
+func All() []Errorx {
+	return registry
 }
 
 func (e *errorx) Error() string {
 	return e.s
 }
 
+func (e *errorx) Code() string {
+	return e.code
+}
+
 var (
+	// ========================================
+	// Auth Errors
+	// ========================================
+
+	// ErrAuthTokenMissing is returned when a protected route is requested without an Authorization header
+	ErrAuthTokenMissing Errorx = New("auth.token_missing", "auth token missing")
+
+	// ErrAuthTokenMalformed is returned when the Authorization header is not of the form "Bearer <token>"
+	ErrAuthTokenMalformed Errorx = New("auth.token_malformed", "auth token malformed")
+
+	// ErrAuthTokenInvalid is returned when the bearer token cannot be verified or has expired
+	ErrAuthTokenInvalid Errorx = New("auth.token_invalid", "auth token invalid")
+
+	// ErrAuthTokenStale is returned when the bearer token was issued before the roles or permissions of its user changed
+	ErrAuthTokenStale Errorx = New("auth.token_stale", "auth token stale")
+
+	// ErrAuthForbidden is returned when the policy of an action refuses it to the authenticated user
+	ErrAuthForbidden Errorx = New("auth.forbidden", "auth forbidden")
+
+	// ========================================
+	// Request Errors
+	// ========================================
+
+	// ErrRequestPreconditionRequired is returned when a conditional route is requested without If-Match while it is required
+	ErrRequestPreconditionRequired Errorx = New("request.precondition_required", "request precondition required")
+
+	// ErrRequestPreconditionFailed is returned when the If-Match header lists no valid entity tag
+	ErrRequestPreconditionFailed Errorx = New("request.precondition_failed", "request precondition failed")
+
+	// ErrRequestFilterInvalid is returned when a filter of the query string has a value of the wrong format
+	ErrRequestFilterInvalid Errorx = New("request.filter_invalid", "request filter invalid")
+
 	// ========================================
 	// User Errors
 	// ========================================
 
 	// ErrUserNotFound is returned when a user is not found in the system
-	ErrUserNotFound Errorx = New("user not found")
+	ErrUserNotFound Errorx = New("user.not_found", "user not found")
 
 	// ErrUserAlreadyExists is returned when attempting to create a user that already exists
-	ErrUserAlreadyExists Errorx = New("user already exists")
+	ErrUserAlreadyExists Errorx = New("user.already_exists", "user already exists")
 
 	// ErrUserEmailAlreadyVerified is returned when trying to verify an already verified email
-	ErrUserEmailAlreadyVerified Errorx = New("user email already verified")
+	ErrUserEmailAlreadyVerified Errorx = New("user.email_already_verified", "user email already verified")
 
 	// ErrUserPasswordIncorrect is returned when the provided password is incorrect
-	ErrUserPasswordIncorrect Errorx = New("user password incorrect")
+	ErrUserPasswordIncorrect Errorx = New("user.password_incorrect", "user password incorrect")
+
+	// ErrUserCredentialsInvalid is returned when the email and password of a login do not match a user
+	ErrUserCredentialsInvalid Errorx = New("user.credentials_invalid", "user credentials invalid")
+
+	// ErrUserInactive is returned when a deactivated user attempts to access the system
+	ErrUserInactive Errorx = New("user.inactive", "user inactive")
+
+	// ErrUserEmailNotVerified is returned when a user with an unverified email accesses a route that requires it
+	ErrUserEmailNotVerified Errorx = New("user.email_not_verified", "user email not verified")
 
 	// ErrUserSuperAdminUpdateForbidden is returned when attempting to update a super admin user
-	ErrUserSuperAdminUpdateForbidden Errorx = New("user super admin update forbidden")
+	ErrUserSuperAdminUpdateForbidden Errorx = New("user.super_admin_update_forbidden", "user super admin update forbidden")
 
 	// ErrSuperAdminRoleChangeForbidden is returned when attempting to change a super admin's role
-	ErrUserSuperAdminRoleChangeForbidden Errorx = New("user super admin role change forbidden")
+	ErrUserSuperAdminRoleChangeForbidden Errorx = New("user.super_admin_role_change_forbidden", "user super admin role change forbidden")
 
 	// ErrUserSuperAdminAssignmentForbidden is returned when attempting to assign super admin role to a user
-	ErrUserSuperAdminAssignmentForbidden Errorx = New("user super admin assignment forbidden")
+	ErrUserSuperAdminAssignmentForbidden Errorx = New("user.super_admin_assignment_forbidden", "user super admin assignment forbidden")
+
+	// ErrUserOutsideDepartment is returned when a user limited to their department manages a user of another one
+	ErrUserOutsideDepartment Errorx = New("user.outside_department", "user outside department")
+
+	// ErrUserNotEmployee is returned when a department is given to a user who is not an employee
+	ErrUserNotEmployee Errorx = New("user.not_employee", "user not employee")
+
+	// ErrUserModified is returned when the user changed since the version the client expects (optimistic locking)
+	ErrUserModified Errorx = New("user.modified", "user modified")
 
 	// ========================================
 	// Role Errors
 	// ========================================
 
 	// ErrRoleNotFound is returned when a specific role is not found
-	ErrRoleNotFound Errorx = New("role not found")
+	ErrRoleNotFound Errorx = New("role.not_found", "role not found")
 
 	// ErrRolesNotFound is returned when one or more requested roles are not found
-	ErrRolesNotFound Errorx = New("roles not found")
+	ErrRolesNotFound Errorx = New("role.some_not_found", "roles not found")
 
 	// ErrRoleAlreadyExists is returned when attempting to create a role that already exists
-	ErrRoleAlreadyExists Errorx = New("role already exists")
+	ErrRoleAlreadyExists Errorx = New("role.already_exists", "role already exists")
 
 	// ErrRoleAlreadyDefault is returned when attempting to set a role as default that is already default
-	ErrRoleAlreadyDefault Errorx = New("role already default")
+	ErrRoleAlreadyDefault Errorx = New("role.already_default", "role already default")
 
 	// ErrRoleDefaultNotConfigured is returned when the system default role is not configured
-	ErrRoleDefaultNotConfigured Errorx = New("role default not configured")
+	ErrRoleDefaultNotConfigured Errorx = New("role.default_not_configured", "role default not configured")
 
 	// ErrRoleDefaultDestroyForbidden is returned when attempting to destroy a default role
-	ErrRoleDefaultDestroyForbidden Errorx = New("role default destroy forbidden")
+	ErrRoleDefaultDestroyForbidden Errorx = New("role.default_destroy_forbidden", "role default destroy forbidden")
 
 	// ErrRoleSuperAdminStoreForbidden is returned when attempting to store a super admin role
-	ErrRoleSuperAdminStoreForbidden Errorx = New("role super admin store forbidden")
+	ErrRoleSuperAdminStoreForbidden Errorx = New("role.super_admin_store_forbidden", "role super admin store forbidden")
 
 	// ErrRoleSuperAdminUpdateForbidden is returned when attempting to update a super admin role
-	ErrRoleSuperAdminUpdateForbidden Errorx = New("role super admin update forbidden")
+	ErrRoleSuperAdminUpdateForbidden Errorx = New("role.super_admin_update_forbidden", "role super admin update forbidden")
 
 	// ErrRoleSuperAdminSetDefaultForbidden is returned when attempting to set a super admin role as default
-	ErrRoleSuperAdminSetDefaultForbidden Errorx = New("role super admin set default forbidden")
+	ErrRoleSuperAdminSetDefaultForbidden Errorx = New("role.super_admin_set_default_forbidden", "role super admin set default forbidden")
 
 	// ErrRoleSuperAdminDestroyForbidden is returned when attempting to destroy a super admin role
-	ErrRoleSuperAdminDestroyForbidden Errorx = New("role super admin destroy forbidden")
+	ErrRoleSuperAdminDestroyForbidden Errorx = New("role.super_admin_destroy_forbidden", "role super admin destroy forbidden")
+
+	// ErrRoleParentNotFound is returned when the parent given to a role is not found
+	ErrRoleParentNotFound Errorx = New("role.parent_not_found", "role parent not found")
+
+	// ErrRoleParentCycle is returned when a role would inherit from itself through its parents
+	ErrRoleParentCycle Errorx = New("role.parent_cycle", "role parent cycle")
+
+	// ErrRoleModified is returned when the role changed since the version the client expects (optimistic locking)
+	ErrRoleModified Errorx = New("role.modified", "role modified")
+
+	// ========================================
+	// Role User Errors
+	// ========================================
+
+	// ErrRoleUserNotFound is returned when a user is not granted a role
+	ErrRoleUserNotFound Errorx = New("role_user.not_found", "role user not found")
+
+	// ErrRoleUserAlreadyGranted is returned when a role is granted temporarily to a user holding it for good
+	ErrRoleUserAlreadyGranted Errorx = New("role_user.already_granted", "role user already granted")
 
 	// ========================================
 	// Permission Errors
 	// ========================================
 
 	// ErrPermissionNotFound is returned when a specific permission is not found
-	ErrPermissionNotFound Errorx = New("permission not found")
+	ErrPermissionNotFound Errorx = New("permission.not_found", "permission not found")
 
 	// ErrPermissionsNotFound is returned when one or more requested permissions are not found
-	ErrPermissionsNotFound Errorx = New("permissions not found")
+	ErrPermissionsNotFound Errorx = New("permission.some_not_found", "permissions not found")
 
 	// ErrPermissionAlreadyExists is returned when attempting to create a permission that already exists
-	ErrPermissionAlreadyExists Errorx = New("permission already exists")
+	ErrPermissionAlreadyExists Errorx = New("permission.already_exists", "permission already exists")
 
 	// ========================================
 	// Permission Role Errors
 	// ========================================
 
 	// ErrPermissionRoleNotFound is returned when a specific permission role is not found
-	ErrPermissionRoleNotFound Errorx = New("permission role not found")
+	ErrPermissionRoleNotFound Errorx = New("permission_role.not_found", "permission role not found")
 
 	// ErrPermissionRoleAlreadyExists is returned when attempting to create a permission role that already exists
-	ErrPermissionRoleAlreadyExists Errorx = New("permission role already exists")
+	ErrPermissionRoleAlreadyExists Errorx = New("permission_role.already_exists", "permission role already exists")
 
 	// ========================================
 	// Code Errors
 	// ========================================
 
 	// ErrCodeNotFound is returned when a verification code is not found
-	ErrCodeNotFound Errorx = New("code not found")
+	ErrCodeNotFound Errorx = New("code.not_found", "code not found")
 
 	// ErrCodeAlreadyExists is returned when attempting to create a code that already exists
-	ErrCodeAlreadyExists Errorx = New("code already exists")
+	ErrCodeAlreadyExists Errorx = New("code.already_exists", "code already exists")
 
 	// ErrCodeAlreadyUsed is returned when attempting to use a code that has already been used
-	ErrCodeAlreadyUsed Errorx = New("code already used")
+	ErrCodeAlreadyUsed Errorx = New("code.already_used", "code already used")
 
 	// ErrCodeExpired is returned when attempting to use an expired verification code
-	ErrCodeExpired Errorx = New("code expired")
+	ErrCodeExpired Errorx = New("code.expired", "code expired")
 
 	// ========================================
 	// Employee Errors
 	// ========================================
 
 	// ErrEmployeeNotFound is returned when an employee record is not found
-	ErrEmployeeNotFound Errorx = New("employee not found")
+	ErrEmployeeNotFound Errorx = New("employee.not_found", "employee not found")
+
+	// ========================================
+	// Idempotency Errors
+	// ========================================
+
+	// ErrIdempotencyKeyInvalid is returned when the Idempotency-Key header is empty, too long or not printable
+	ErrIdempotencyKeyInvalid Errorx = New("idempotency.key_invalid", "idempotency key invalid")
+
+	// ErrIdempotencyKeyReused is returned when an Idempotency-Key is reused with a different request
+	ErrIdempotencyKeyReused Errorx = New("idempotency.key_reused", "idempotency key reused with a different request")
+
+	// ErrIdempotencyRequestInProgress is returned when a request with the same Idempotency-Key is still being processed
+	ErrIdempotencyRequestInProgress Errorx = New("idempotency.request_in_progress", "idempotency request in progress")
 )
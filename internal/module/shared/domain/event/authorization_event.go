@@ -0,0 +1,30 @@
+package event
+
+// The events of the changes to what users are authorized to do, the permissions resolved before
+// them are stale
+
+// UserRolesChanged is published once the roles of a user are changed, or the user is deleted
+type UserRolesChanged struct {
+	UserId string
+}
+
+func (UserRolesChanged) EventName() string {
+	return "user.roles_changed"
+}
+
+// RoleChanged is published once the name or the permissions of a role are changed, or the role is deleted
+type RoleChanged struct {
+	RoleId string
+}
+
+func (RoleChanged) EventName() string {
+	return "role.changed"
+}
+
+// PermissionsChanged is published once permissions are granted to or revoked from roles other than
+// through a role update, e.g. by the permissions synchronization
+type PermissionsChanged struct{}
+
+func (PermissionsChanged) EventName() string {
+	return "permission.changed"
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
@@ -9,15 +11,15 @@ import (
 )
 
 type UserRepository interface {
-	Get(query *query.Query) ([]*entity.User, error)
-	Paginate(query *query.Query) (*pagination.OffsetPagination[*entity.User], error)
-	First(query *query.Query) (*entity.User, error)
-	Find(id string) (*entity.User, error)
-	FindByEmail(email string) (*entity.User, error)
-	HasPermissionNames(user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error)
-	HasRoleNames(user *entity.User, roleNames []roleEnum.RoleName) (bool, error)
-	ToggleActivation(user *entity.User) (*entity.User, error)
-	Save(user *entity.User) error
-	SaveMany(users []*entity.User) error
-	Destroy(user *entity.User) error
+	Get(ctx context.Context, query *query.Query) ([]*entity.User, error)
+	Paginate(ctx context.Context, query *query.Query) (*pagination.OffsetPagination[*entity.User], error)
+	First(ctx context.Context, query *query.Query) (*entity.User, error)
+	Find(ctx context.Context, id string) (*entity.User, error)
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	HasPermissionNames(ctx context.Context, user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error)
+	HasRoleNames(ctx context.Context, user *entity.User, roleNames []roleEnum.RoleName) (bool, error)
+	ToggleActivation(ctx context.Context, user *entity.User) (*entity.User, error)
+	Save(ctx context.Context, user *entity.User) error
+	SaveMany(ctx context.Context, users []*entity.User) error
+	Destroy(ctx context.Context, user *entity.User) error
 }
@@ -6,6 +6,7 @@ import (
 	userRepositoryImpl "github.com/arfanxn/welding/internal/module/user/infrastructure/repository"
 	"github.com/arfanxn/welding/internal/module/user/presentation/http"
 	"github.com/arfanxn/welding/internal/module/user/usecase"
+	"github.com/arfanxn/welding/internal/module/user/usecase/service"
 	"github.com/arfanxn/welding/internal/module/user/usecase/step"
 	"go.uber.org/fx"
 )
@@ -17,6 +18,7 @@ var Module = fx.Module(
 		policy.NewUserPolicy,
 		step.NewRegisterUserStep,
 		step.NewSaveUserStep,
+		service.NewUserPermissionService,
 		usecase.NewUserUsecase,
 		http.NewUserHandler,
 	),
@@ -3,30 +3,45 @@ package policy
 import (
 	"context"
 
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
 	"github.com/arfanxn/welding/internal/infrastructure/security"
-	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	"github.com/arfanxn/welding/internal/module/user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/user/usecase/dto"
+	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gookit/goutil"
+	"github.com/samber/lo"
 	"go.uber.org/fx"
 )
 
 type UserPolicy interface {
+	// Show authorizes showing user, which is loaded already
+	Show(ctx context.Context, user *entity.User) error
 	Store(ctx context.Context, _dto *dto.SaveUser) error
 	Update(ctx context.Context, _dto *dto.SaveUser) error
 	UpdateMePassword(ctx context.Context, _dto *dto.UpdateUserMePassword) error
 	// ! Deprecated
 	// UpdatePassword(ctx context.Context, _dto *dto.UpdateUserPassword) (*entity.User, error)
 	ToggleActivation(ctx context.Context, _dto *dto.ToggleActivation) error
+	// GrantRole authorizes a temporary grant as an update assigning the role
+	GrantRole(ctx context.Context, _dto *dto.GrantUserRole) error
 	Destroy(ctx context.Context, _dto *dto.DestroyUser) error
 }
 
+// UserChange is the input of the users.store and users.update rules, the attributes a user is
+// saved with and the roles they assign
+type UserChange struct {
+	Save  *dto.SaveUser
+	Roles []*entity.Role
+}
+
 type userPolicy struct {
 	passwordService security.PasswordService
+	policyEngine    authz.PolicyEngine
 
 	userRepository repository.UserRepository
 	roleRepository roleRepository.RoleRepository
@@ -36,56 +51,66 @@ type NewUserPolicyParams struct {
 	fx.In
 
 	PasswordService security.PasswordService
+	PolicyEngine    authz.PolicyEngine
 
 	UserRepository repository.UserRepository
 	RoleRepository roleRepository.RoleRepository
 }
 
 func NewUserPolicy(params NewUserPolicyParams) UserPolicy {
+	params.PolicyEngine.Register(permissionEnum.UsersShow,
+		allowSelfShow,
+		limitToDepartment,
+	)
+	params.PolicyEngine.Register(permissionEnum.UsersStore,
+		forbidSuperAdminAssignment,
+		limitToDepartment,
+	)
+	params.PolicyEngine.Register(permissionEnum.UsersUpdate,
+		protectSuperAdmin,
+		allowSelfUpdate,
+		forbidSuperAdminAssignment,
+		limitToDepartment,
+	)
+	params.PolicyEngine.Register(permissionEnum.UsersDestroy,
+		protectSuperAdmin,
+		limitToDepartment,
+	)
+
 	return &userPolicy{
 		passwordService: params.PasswordService,
+		policyEngine:    params.PolicyEngine,
 
 		userRepository: params.UserRepository,
 		roleRepository: params.RoleRepository,
 	}
 }
 
+func (p *userPolicy) Show(ctx context.Context, user *entity.User) error {
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersShow, user, nil)
+}
+
 func (p *userPolicy) Store(ctx context.Context, _dto *dto.SaveUser) error {
-	if err := p.validateRoleAssignments(_dto.RoleIds); err != nil {
+	roles, err := p.findAssignedRoles(ctx, _dto.RoleIds)
+	if err != nil {
 		return err
 	}
-	return nil
+
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersStore, nil, &UserChange{Save: _dto, Roles: roles})
 }
 
 func (p *userPolicy) Update(ctx context.Context, _dto *dto.SaveUser) error {
-	authUser := ctx.Value(contextkey.UserKey).(*entity.User)
-
-	targetUser, err := p.findUser(*_dto.Id)
+	targetUser, err := p.findUser(ctx, *_dto.Id)
 	if err != nil {
 		return err
 	}
 
-	isTargetUserSuperAdmin, err := p.isSuperAdmin(targetUser)
+	roles, err := p.findAssignedRoles(ctx, _dto.RoleIds)
 	if err != nil {
 		return err
 	}
 
-	if isTargetUserSuperAdmin {
-		// Only allow self-updates for SuperAdmins
-		if authUser.Id != targetUser.Id {
-			return errorx.ErrUserSuperAdminUpdateForbidden
-		}
-
-		// Prevent role changes for SuperAdmins
-		if _dto.RoleIds != nil {
-			return errorx.ErrUserSuperAdminRoleChangeForbidden
-		}
-	}
-
-	if err := p.validateRoleAssignments(_dto.RoleIds); err != nil {
-		return err
-	}
-	return nil
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersUpdate, targetUser, &UserChange{Save: _dto, Roles: roles})
 }
 
 func (p *userPolicy) UpdateMePassword(ctx context.Context, _dto *dto.UpdateUserMePassword) error {
@@ -107,12 +132,12 @@ func (p *userPolicy) UpdatePassword(
 ) (*entity.User, error) {
 	authUser := ctx.Value(contextkey.UserKey).(*entity.User)
 
-	user, err := p.findUser(_dto.Id)
+	user, err := p.findUser(ctx, _dto.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	isSuperAdmin, err := p.isSuperAdmin(user)
+	isSuperAdmin, err := p.isSuperAdmin(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -126,73 +151,152 @@ func (p *userPolicy) UpdatePassword(
 */
 
 func (p *userPolicy) ToggleActivation(ctx context.Context, _dto *dto.ToggleActivation) error {
-	user, err := p.findUser(_dto.Id)
+	user, err := p.findUser(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
 
-	isSuperAdmin, err := p.isSuperAdmin(user)
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersUpdate, user, _dto)
+}
+
+func (p *userPolicy) GrantRole(ctx context.Context, _dto *dto.GrantUserRole) error {
+	user, err := p.findUser(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
 
-	if isSuperAdmin {
-		return errorx.ErrUserSuperAdminUpdateForbidden
+	roles, err := p.findAssignedRoles(ctx, []string{_dto.RoleId})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	change := &UserChange{Save: &dto.SaveUser{Id: &_dto.Id, RoleIds: []string{_dto.RoleId}}, Roles: roles}
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersUpdate, user, change)
 }
 
 // Destroy validates if a user can be deleted based on certain business rules
-func (p *userPolicy) Destroy(_ context.Context, _dto *dto.DestroyUser) error {
-	user, err := p.findUser(_dto.Id)
+func (p *userPolicy) Destroy(ctx context.Context, _dto *dto.DestroyUser) error {
+	user, err := p.findUser(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
 
-	isSuperAdmin, err := p.isSuperAdmin(user)
-	if err != nil {
-		return err
+	return p.policyEngine.Authorize(ctx, permissionEnum.UsersDestroy, user, _dto)
+}
+
+// ==================================================
+// Rules
+// ==================================================
+
+// protectSuperAdmin lets super admins, the users granted every permission (*), update themselves only,
+// without changing their roles, and keeps them from being deactivated or deleted
+func protectSuperAdmin(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	targetUser, ok := request.Resource.(*entity.User)
+	if !ok || !isSuperAdmin(targetUser) {
+		return authz.Abstain, nil
 	}
 
-	if isSuperAdmin {
-		return errorx.ErrUserSuperAdminUpdateForbidden
+	change, ok := request.Input.(*UserChange)
+	if !ok {
+		return authz.Deny, errorx.ErrUserSuperAdminUpdateForbidden
+	}
+	if request.Actor.User.Id != targetUser.Id {
+		return authz.Deny, errorx.ErrUserSuperAdminUpdateForbidden
+	}
+	if change.Save.RoleIds != nil {
+		return authz.Deny, errorx.ErrUserSuperAdminRoleChangeForbidden
 	}
+	return authz.Abstain, nil
+}
 
-	return nil
+// allowSelfShow lets users see themselves
+func allowSelfShow(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	targetUser, ok := request.Resource.(*entity.User)
+	if ok && targetUser.Id == request.Actor.User.Id {
+		return authz.Allow, nil
+	}
+	return authz.Abstain, nil
 }
 
-// ==================================================
-// Private helper methods
-// ==================================================
+// allowSelfUpdate lets users update their own profile, but not their roles, activation or department
+func allowSelfUpdate(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	targetUser, ok := request.Resource.(*entity.User)
+	if !ok || targetUser.Id != request.Actor.User.Id {
+		return authz.Abstain, nil
+	}
 
-func (p *userPolicy) findUser(userId string) (*entity.User, error) {
-	return p.userRepository.Find(userId)
+	change, ok := request.Input.(*UserChange)
+	if !ok {
+		return authz.Abstain, nil
+	}
+	if change.Save.RoleIds != nil ||
+		change.Save.ActivatedAt != nil ||
+		change.Save.DeactivatedAt != nil ||
+		change.Save.Department != nil {
+		return authz.Abstain, nil
+	}
+	return authz.Allow, nil
 }
 
-func (p *userPolicy) isSuperAdmin(user *entity.User) (bool, error) {
-	return p.userRepository.HasRoleNames(user, []roleEnum.RoleName{roleEnum.SuperAdmin})
+// forbidSuperAdminAssignment keeps the roles granting every permission, like super_admin, from being assigned
+func forbidSuperAdminAssignment(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	change, ok := request.Input.(*UserChange)
+	if ok && lo.ContainsBy(change.Roles, (*entity.Role).GrantsAllPermissions) {
+		return authz.Deny, errorx.ErrUserSuperAdminAssignmentForbidden
+	}
+	return authz.Abstain, nil
 }
 
-func (p *userPolicy) validateRoleAssignments(roleIDs []string) error {
-	if goutil.IsEmpty(roleIDs) {
-		return nil
+// limitToDepartment limits the actors who do not hold users.any_department to the users of their own
+// department, which the users they create or move must belong to as well
+func limitToDepartment(_ context.Context, request *authz.Request) (authz.Effect, error) {
+	if request.Actor.HasPermissionName(permissionEnum.UsersAnyDepartment) {
+		return authz.Abstain, nil
 	}
 
-	if goutil.IsEmpty(roleIDs[0]) {
-		return nil
+	department := request.Actor.Department()
+	if !department.Valid {
+		return authz.Deny, errorx.ErrUserOutsideDepartment
 	}
 
-	roles, err := p.roleRepository.FindByIds(roleIDs)
-	if err != nil {
-		return err
+	if targetUser, ok := request.Resource.(*entity.User); ok && targetUser.DepartmentName() != department {
+		return authz.Deny, errorx.ErrUserOutsideDepartment
 	}
 
-	for _, role := range roles {
-		if role.Name == roleEnum.SuperAdmin {
-			return errorx.ErrUserSuperAdminAssignmentForbidden
+	if change, ok := request.Input.(*UserChange); ok {
+		// A created user is placed in the department, a moved one must stay in it
+		if request.Resource == nil && change.Save.Department == nil {
+			return authz.Deny, errorx.ErrUserOutsideDepartment
+		}
+		if change.Save.Department != nil && *change.Save.Department != department.String {
+			return authz.Deny, errorx.ErrUserOutsideDepartment
 		}
 	}
+	return authz.Abstain, nil
+}
 
-	return nil
+// ==================================================
+// Private helper methods
+// ==================================================
+
+// findUser returns the user with its roles and their permissions, which the rules tell super admins by
+func (p *userPolicy) findUser(ctx context.Context, userId string) (*entity.User, error) {
+	return p.userRepository.First(ctx, query.NewQuery().FilterById(userId).Include("Roles.Permissions"))
+}
+
+// findAssignedRoles returns the roles of roleIDs with their permissions, none when the roles are left or cleared
+func (p *userPolicy) findAssignedRoles(ctx context.Context, roleIDs []string) ([]*entity.Role, error) {
+	if goutil.IsEmpty(roleIDs) {
+		return nil, nil
+	}
+
+	if goutil.IsEmpty(roleIDs[0]) {
+		return nil, nil
+	}
+
+	return p.roleRepository.FindByIds(ctx, roleIDs)
+}
+
+func isSuperAdmin(user *entity.User) bool {
+	return lo.ContainsBy(user.Roles, (*entity.Role).GrantsAllPermissions)
 }
@@ -0,0 +1,446 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/authz"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	"github.com/arfanxn/welding/internal/module/user/usecase/dto"
+	userService "github.com/arfanxn/welding/internal/module/user/usecase/service"
+	"github.com/guregu/null/v6"
+	"github.com/samber/lo"
+)
+
+var (
+	superAdminRole = &entity.Role{Id: "role-super-admin", Permissions: []*entity.Permission{{Name: permissionEnum.All}}}
+	staffRole      = &entity.Role{Id: "role-staff", Permissions: []*entity.Permission{{Name: permissionEnum.UsersShow}}}
+)
+
+// newActor returns the actor id of department, none when empty, holding permissions
+func newActor(id string, department string, permissions ...permissionEnum.PermissionName) *authz.Actor {
+	return &authz.Actor{
+		User:        newUser(id, department),
+		Permissions: &userService.EffectivePermissions{PermissionNames: permissions},
+	}
+}
+
+// newUser returns the user id of department, none when empty, holding roles
+func newUser(id string, department string, roles ...*entity.Role) *entity.User {
+	user := &entity.User{Id: id, Roles: roles}
+	if department != "" {
+		user.Department = lo.ToPtr(null.StringFrom(department))
+	}
+	return user
+}
+
+// profileChange changes the name of the user id only
+func profileChange(id string) *UserChange {
+	return &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr(id), Name: lo.ToPtr("Name")}}
+}
+
+func TestUserRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    authz.Rule
+		request *authz.Request
+		want    authz.Effect
+		wantErr error
+	}{
+		// protectSuperAdmin
+		{
+			name:    "protectSuperAdmin abstains on creation",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Input: profileChange("")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectSuperAdmin abstains on another user",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "welding", staffRole), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "protectSuperAdmin denies updating another super admin",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("actor", ""), Resource: newUser("target", "", superAdminRole), Input: profileChange("target")},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserSuperAdminUpdateForbidden,
+		},
+		{
+			name:    "protectSuperAdmin denies deleting a super admin",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("target", ""), Resource: newUser("target", "", superAdminRole), Input: &dto.DestroyUser{Id: "target"}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserSuperAdminUpdateForbidden,
+		},
+		{
+			name:    "protectSuperAdmin denies deactivating a super admin",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("target", ""), Resource: newUser("target", "", superAdminRole), Input: &dto.ToggleActivation{Id: "target"}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserSuperAdminUpdateForbidden,
+		},
+		{
+			name: "protectSuperAdmin denies a super admin changing their roles",
+			rule: protectSuperAdmin,
+			request: &authz.Request{
+				Actor:    newActor("target", ""),
+				Resource: newUser("target", "", superAdminRole),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("target"), RoleIds: []string{staffRole.Id}}},
+			},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserSuperAdminRoleChangeForbidden,
+		},
+		{
+			name:    "protectSuperAdmin abstains on a super admin updating their profile",
+			rule:    protectSuperAdmin,
+			request: &authz.Request{Actor: newActor("target", ""), Resource: newUser("target", "", superAdminRole), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+
+		// allowSelfUpdate
+		{
+			name:    "allowSelfUpdate allows updating one's profile",
+			rule:    allowSelfUpdate,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("actor", "welding"), Input: profileChange("actor")},
+			want:    authz.Allow,
+		},
+		{
+			name:    "allowSelfUpdate abstains on another user",
+			rule:    allowSelfUpdate,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "welding"), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "allowSelfUpdate abstains on creation",
+			rule:    allowSelfUpdate,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Input: profileChange("")},
+			want:    authz.Abstain,
+		},
+		{
+			name: "allowSelfUpdate abstains on one's roles",
+			rule: allowSelfUpdate,
+			request: &authz.Request{
+				Actor:    newActor("actor", "welding"),
+				Resource: newUser("actor", "welding"),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("actor"), RoleIds: []string{staffRole.Id}}},
+			},
+			want: authz.Abstain,
+		},
+		{
+			name: "allowSelfUpdate abstains on one's department",
+			rule: allowSelfUpdate,
+			request: &authz.Request{
+				Actor:    newActor("actor", "welding"),
+				Resource: newUser("actor", "welding"),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("actor"), Department: lo.ToPtr("welding")}},
+			},
+			want: authz.Abstain,
+		},
+		{
+			name: "allowSelfUpdate abstains on one's activation",
+			rule: allowSelfUpdate,
+			request: &authz.Request{
+				Actor:    newActor("actor", "welding"),
+				Resource: newUser("actor", "welding"),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("actor"), DeactivatedAt: lo.ToPtr(time.Now())}},
+			},
+			want: authz.Abstain,
+		},
+		{
+			name:    "allowSelfUpdate abstains on toggling one's activation",
+			rule:    allowSelfUpdate,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("actor", "welding"), Input: &dto.ToggleActivation{Id: "actor"}},
+			want:    authz.Abstain,
+		},
+
+		// allowSelfShow
+		{
+			name:    "allowSelfShow allows showing oneself",
+			rule:    allowSelfShow,
+			request: &authz.Request{Actor: newActor("actor", ""), Resource: newUser("actor", "")},
+			want:    authz.Allow,
+		},
+		{
+			name:    "allowSelfShow abstains on another user",
+			rule:    allowSelfShow,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "welding")},
+			want:    authz.Abstain,
+		},
+
+		// forbidSuperAdminAssignment
+		{
+			name: "forbidSuperAdminAssignment denies assigning a role granting every permission",
+			rule: forbidSuperAdminAssignment,
+			request: &authz.Request{
+				Actor:    newActor("actor", "", permissionEnum.UsersAnyDepartment),
+				Resource: newUser("target", ""),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("target"), RoleIds: []string{superAdminRole.Id}}, Roles: []*entity.Role{staffRole, superAdminRole}},
+			},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserSuperAdminAssignmentForbidden,
+		},
+		{
+			name: "forbidSuperAdminAssignment abstains on assigning other roles",
+			rule: forbidSuperAdminAssignment,
+			request: &authz.Request{
+				Actor: newActor("actor", "welding"),
+				Input: &UserChange{Save: &dto.SaveUser{RoleIds: []string{staffRole.Id}}, Roles: []*entity.Role{staffRole}},
+			},
+			want: authz.Abstain,
+		},
+		{
+			name:    "forbidSuperAdminAssignment abstains without a change",
+			rule:    forbidSuperAdminAssignment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "", superAdminRole), Input: &dto.DestroyUser{Id: "target"}},
+			want:    authz.Abstain,
+		},
+
+		// limitToDepartment
+		{
+			name:    "limitToDepartment abstains for an actor managing every department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "", permissionEnum.UsersAnyDepartment), Resource: newUser("target", "painting"), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "limitToDepartment abstains for an actor granted every permission",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "", permissionEnum.All), Resource: newUser("target", "painting"), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "limitToDepartment denies an actor without department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", ""), Resource: newUser("target", ""), Input: profileChange("target")},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name:    "limitToDepartment abstains on a user of the department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "welding"), Input: profileChange("target")},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "limitToDepartment denies a user of another department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "painting"), Input: &dto.DestroyUser{Id: "target"}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name:    "limitToDepartment denies showing a user of another department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", "painting")},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name:    "limitToDepartment denies a user without department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Resource: newUser("target", ""), Input: profileChange("target")},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name:    "limitToDepartment denies creating without department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Input: &UserChange{Save: &dto.SaveUser{Name: lo.ToPtr("Name")}}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name:    "limitToDepartment abstains on creating in the department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Input: &UserChange{Save: &dto.SaveUser{Department: lo.ToPtr("welding")}}},
+			want:    authz.Abstain,
+		},
+		{
+			name:    "limitToDepartment denies creating in another department",
+			rule:    limitToDepartment,
+			request: &authz.Request{Actor: newActor("actor", "welding"), Input: &UserChange{Save: &dto.SaveUser{Department: lo.ToPtr("painting")}}},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name: "limitToDepartment denies moving a user to another department",
+			rule: limitToDepartment,
+			request: &authz.Request{
+				Actor:    newActor("actor", "welding"),
+				Resource: newUser("target", "welding"),
+				Input:    &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("target"), Department: lo.ToPtr("painting")}},
+			},
+			want:    authz.Deny,
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rule(context.Background(), tt.request)
+			if got != tt.want {
+				t.Errorf("effect = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestUserPolicyRules evaluates the rules the user policy registers for each permission together,
+// down to the permission required when every rule abstains
+func TestUserPolicyRules(t *testing.T) {
+	engine := authz.NewPolicyEngine(authz.NewPolicyEngineParams{})
+	NewUserPolicy(NewUserPolicyParams{PolicyEngine: engine})
+
+	tests := []struct {
+		name    string
+		request *authz.Request
+		wantErr error
+	}{
+		{
+			name: "showing oneself without users.show",
+			request: &authz.Request{
+				Actor:      newActor("actor", ""),
+				Permission: permissionEnum.UsersShow,
+				Resource:   newUser("actor", ""),
+			},
+		},
+		{
+			name: "showing a user of the department",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersShow),
+				Permission: permissionEnum.UsersShow,
+				Resource:   newUser("target", "welding"),
+			},
+		},
+		{
+			name: "showing a user of another department",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersShow),
+				Permission: permissionEnum.UsersShow,
+				Resource:   newUser("target", "painting"),
+			},
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name: "showing a user of the department without users.show",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding"),
+				Permission: permissionEnum.UsersShow,
+				Resource:   newUser("target", "welding"),
+			},
+			wantErr: errorx.ErrAuthForbidden,
+		},
+		{
+			name: "updating one's profile without users.update nor department",
+			request: &authz.Request{
+				Actor:      newActor("actor", ""),
+				Permission: permissionEnum.UsersUpdate,
+				Resource:   newUser("actor", ""),
+				Input:      profileChange("actor"),
+			},
+		},
+		{
+			name: "updating one's roles without users.update",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding"),
+				Permission: permissionEnum.UsersUpdate,
+				Resource:   newUser("actor", "welding"),
+				Input:      &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("actor"), RoleIds: []string{staffRole.Id}}, Roles: []*entity.Role{staffRole}},
+			},
+			wantErr: errorx.ErrAuthForbidden,
+		},
+		{
+			name: "moving oneself to another department with users.update",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersUpdate),
+				Permission: permissionEnum.UsersUpdate,
+				Resource:   newUser("actor", "welding"),
+				Input:      &UserChange{Save: &dto.SaveUser{Id: lo.ToPtr("actor"), Department: lo.ToPtr("painting")}},
+			},
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name: "a super admin updating their profile",
+			request: &authz.Request{
+				Actor:      newActor("actor", "", permissionEnum.All),
+				Permission: permissionEnum.UsersUpdate,
+				Resource:   newUser("actor", "", superAdminRole),
+				Input:      profileChange("actor"),
+			},
+		},
+		{
+			name: "updating a super admin with every permission",
+			request: &authz.Request{
+				Actor:      newActor("actor", "", permissionEnum.All),
+				Permission: permissionEnum.UsersUpdate,
+				Resource:   newUser("target", "", superAdminRole),
+				Input:      profileChange("target"),
+			},
+			wantErr: errorx.ErrUserSuperAdminUpdateForbidden,
+		},
+		{
+			name: "assigning super admin with every permission",
+			request: &authz.Request{
+				Actor:      newActor("actor", "", permissionEnum.All),
+				Permission: permissionEnum.UsersStore,
+				Input:      &UserChange{Save: &dto.SaveUser{RoleIds: []string{superAdminRole.Id}}, Roles: []*entity.Role{superAdminRole}},
+			},
+			wantErr: errorx.ErrUserSuperAdminAssignmentForbidden,
+		},
+		{
+			name: "creating in the department",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersStore),
+				Permission: permissionEnum.UsersStore,
+				Input:      &UserChange{Save: &dto.SaveUser{Department: lo.ToPtr("welding")}},
+			},
+		},
+		{
+			name: "creating without department",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersStore),
+				Permission: permissionEnum.UsersStore,
+				Input:      &UserChange{Save: &dto.SaveUser{}},
+			},
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name: "deleting a user of another department",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding", permissionEnum.UsersDestroy),
+				Permission: permissionEnum.UsersDestroy,
+				Resource:   newUser("target", "painting"),
+				Input:      &dto.DestroyUser{Id: "target"},
+			},
+			wantErr: errorx.ErrUserOutsideDepartment,
+		},
+		{
+			name: "deleting oneself without users.destroy",
+			request: &authz.Request{
+				Actor:      newActor("actor", "welding"),
+				Permission: permissionEnum.UsersDestroy,
+				Resource:   newUser("actor", "welding"),
+				Input:      &dto.DestroyUser{Id: "actor"},
+			},
+			wantErr: errorx.ErrAuthForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.Evaluate(context.Background(), tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Evaluate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"strings"
 
+	"github.com/arfanxn/welding/internal/infrastructure/database"
 	"github.com/arfanxn/welding/internal/infrastructure/database/helper"
 	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
 	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
@@ -19,11 +21,14 @@ var _ repository.UserRepository = (*GormUserRepository)(nil)
 
 type GormUserRepository struct {
 	db *gorm.DB
+	// readDB serves Get and Paginate, it may lag behind db when it is a read replica
+	readDB *gorm.DB
 }
 
-func NewGormUserRepository(db *gorm.DB) repository.UserRepository {
+func NewGormUserRepository(db *gorm.DB, readDB database.ReadDB) repository.UserRepository {
 	return &GormUserRepository{
-		db: db,
+		db:     db,
+		readDB: readDB.DB,
 	}
 }
 
@@ -35,6 +40,8 @@ func (r *GormUserRepository) query(db *gorm.DB, q *query.Query) *gorm.DB {
 	sb.WriteString(userTableName + ".*")
 	sb.WriteString(", ")
 	sb.WriteString(employeeTableName + ".employment_identity_number")
+	sb.WriteString(", ")
+	sb.WriteString(employeeTableName + ".department")
 
 	db = db.Select(sb.String())
 
@@ -71,10 +78,15 @@ func (r *GormUserRepository) query(db *gorm.DB, q *query.Query) *gorm.DB {
 	return db
 }
 
-func (r *GormUserRepository) Get(q *query.Query) ([]*entity.User, error) {
+func (r *GormUserRepository) Get(ctx context.Context, q *query.Query) ([]*entity.User, error) {
+	return r.get(r.readDB.WithContext(ctx), q)
+}
+
+// get retrieves the users matching q from db
+func (r *GormUserRepository) get(db *gorm.DB, q *query.Query) ([]*entity.User, error) {
 	var users []*entity.User
 
-	db := r.query(r.db, q)
+	db = r.query(db, q)
 
 	if err := db.Find(&users).Error; err != nil {
 		return nil, err
@@ -83,8 +95,8 @@ func (r *GormUserRepository) Get(q *query.Query) ([]*entity.User, error) {
 	return users, nil
 }
 
-func (r *GormUserRepository) Paginate(q *query.Query) (*pagination.OffsetPagination[*entity.User], error) {
-	db := r.db.Model(&entity.User{})
+func (r *GormUserRepository) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.User], error) {
+	db := r.readDB.WithContext(ctx).Model(&entity.User{})
 
 	db = r.query(db, q)
 
@@ -95,8 +107,9 @@ func (r *GormUserRepository) Paginate(q *query.Query) (*pagination.OffsetPaginat
 	return paginator, nil
 }
 
-func (r *GormUserRepository) First(q *query.Query) (*entity.User, error) {
-	users, err := r.Get(q)
+func (r *GormUserRepository) First(ctx context.Context, q *query.Query) (*entity.User, error) {
+	// The primary is read, the result is often updated next and must not lag behind
+	users, err := r.get(r.db.WithContext(ctx), q)
 	if err != nil {
 		return nil, err
 	}
@@ -106,10 +119,10 @@ func (r *GormUserRepository) First(q *query.Query) (*entity.User, error) {
 	return users[0], nil
 }
 
-func (r *GormUserRepository) Find(id string) (*entity.User, error) {
+func (r *GormUserRepository) Find(ctx context.Context, id string) (*entity.User, error) {
 	var user entity.User
 
-	if err := r.db.Where("id = ?", id).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrUserNotFound
 		}
@@ -118,9 +131,9 @@ func (r *GormUserRepository) Find(id string) (*entity.User, error) {
 	return &user, nil
 }
 
-func (r *GormUserRepository) FindByEmail(email string) (*entity.User, error) {
+func (r *GormUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errorx.ErrUserNotFound
 		}
@@ -131,13 +144,13 @@ func (r *GormUserRepository) FindByEmail(email string) (*entity.User, error) {
 
 // HasPermissionNames checks if a user has all the specified permissions.
 // The permissions parameter is an array of permission names.
-func (r *GormUserRepository) HasPermissionNames(user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error) {
+func (r *GormUserRepository) HasPermissionNames(ctx context.Context, user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error) {
 	if len(permissionNames) == 0 {
 		return true, nil
 	}
 
-	db := r.db.Model(&entity.User{}).
-		Joins("JOIN role_user ON role_user.user_id = users.id").
+	db := r.db.WithContext(ctx).Model(&entity.User{}).
+		Joins("JOIN role_user ON role_user.user_id = users.id AND "+helper.RoleUserInEffectCondition).
 		Joins("JOIN roles ON roles.id = role_user.role_id").
 		Joins("JOIN permission_role ON permission_role.role_id = roles.id").
 		Joins("JOIN permissions ON permissions.id = permission_role.permission_id").
@@ -158,13 +171,13 @@ func (r *GormUserRepository) HasPermissionNames(user *entity.User, permissionNam
 
 // HasRoleNames checks if a user has all the specified roles.
 // The roleNames parameter is an array of role names.
-func (r *GormUserRepository) HasRoleNames(user *entity.User, roleNames []roleEnum.RoleName) (bool, error) {
+func (r *GormUserRepository) HasRoleNames(ctx context.Context, user *entity.User, roleNames []roleEnum.RoleName) (bool, error) {
 	if len(roleNames) == 0 {
 		return true, nil
 	}
 
-	db := r.db.Model(&entity.User{}).
-		Joins("JOIN role_user ON role_user.user_id = users.id").
+	db := r.db.WithContext(ctx).Model(&entity.User{}).
+		Joins("JOIN role_user ON role_user.user_id = users.id AND "+helper.RoleUserInEffectCondition).
 		Joins("JOIN roles ON roles.id = role_user.role_id").
 		Where("users.id = ?", user.Id).
 		Where("roles.name IN (?)", roleNames)
@@ -181,37 +194,50 @@ func (r *GormUserRepository) HasRoleNames(user *entity.User, roleNames []roleEnu
 	return count == int64(len(roleNames)), nil
 }
 
-func (r *GormUserRepository) ToggleActivation(user *entity.User) (*entity.User, error) {
+func (r *GormUserRepository) ToggleActivation(ctx context.Context, user *entity.User) (*entity.User, error) {
 	if user.ActivatedAt.Valid {
 		user.MarkDeactivated()
 	} else {
 		user.MarkActivated()
 	}
 
-	if err := r.db.Save(user).Error; err != nil {
+	if err := r.Save(ctx, user); err != nil {
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (r *GormUserRepository) Save(user *entity.User) error {
+func (r *GormUserRepository) Save(ctx context.Context, user *entity.User) error {
 	// Save user record (without roles and employee to prevent M2M race conditions)
-	err := r.db.Omit("Roles", "Employee").Save(user).Error
+	saved, err := helper.GormDBSaveVersioned(r.db.WithContext(ctx).Omit("Roles", "Employee"), user, &user.Version)
 	if err != nil {
 		if helper.IsPostgresDuplicateKeyError(err) {
 			return errorx.ErrUserAlreadyExists
 		}
 		return err
 	}
+	if !saved {
+		return errorx.ErrUserModified
+	}
 
 	return nil
 }
 
-func (r *GormUserRepository) SaveMany(users []*entity.User) error {
-	return r.db.CreateInBatches(users, 100).Error
+func (r *GormUserRepository) SaveMany(ctx context.Context, users []*entity.User) error {
+	for _, user := range users {
+		user.Version = max(user.Version, 1)
+	}
+	return r.db.WithContext(ctx).CreateInBatches(users, 100).Error
 }
 
-func (r *GormUserRepository) Destroy(user *entity.User) error {
-	return r.db.Delete(user).Error
+func (r *GormUserRepository) Destroy(ctx context.Context, user *entity.User) error {
+	destroyed, err := helper.GormDBDestroyVersioned(r.db.WithContext(ctx), user, user.Version)
+	if err != nil {
+		return err
+	}
+	if !destroyed {
+		return errorx.ErrUserModified
+	}
+	return nil
 }
@@ -14,7 +14,7 @@ func (s *DestroyUser) Validate() error {
 	return validation.ValidateStruct(s,
 		validation.Field(&s.Id,
 			validation.Required,
-			validation.Length(26, 26).Error("Id harus 26 karakter"),
+			validation.Length(26, 26),
 		),
 	)
 }
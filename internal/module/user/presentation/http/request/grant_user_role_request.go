@@ -0,0 +1,50 @@
+package request
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+type GrantUserRole struct {
+	Id        string  `form:"id" json:"id"`
+	RoleId    string  `form:"role_id" json:"role_id"`
+	StartsAt  *string `form:"starts_at" json:"starts_at"`
+	ExpiresAt string  `form:"expires_at" json:"expires_at"`
+	Reason    string  `form:"reason" json:"reason"`
+}
+
+func (r *GrantUserRole) Validate() error {
+	// The grant must expire in the future and after it starts
+	minExpiresAt := time.Now()
+	if r.StartsAt != nil {
+		if startsAt, err := time.Parse(time.DateTime, *r.StartsAt); err == nil && startsAt.After(minExpiresAt) {
+			minExpiresAt = startsAt
+		}
+	}
+
+	return validation.ValidateStruct(r,
+		validation.Field(&r.Id,
+			validation.Required,
+			validation.Length(26, 26),
+		),
+		validation.Field(&r.RoleId,
+			validation.Required,
+			is.Alphanumeric,
+			validation.Length(26, 26),
+		),
+		validation.Field(&r.StartsAt,
+			validation.NilOrNotEmpty,
+			validation.Date(time.DateTime),
+		),
+		validation.Field(&r.ExpiresAt,
+			validation.Required,
+			validation.Date(time.DateTime).Min(minExpiresAt.Add(time.Second)),
+		),
+		validation.Field(&r.Reason,
+			validation.Required,
+			validation.Length(3, 255),
+		),
+	)
+}
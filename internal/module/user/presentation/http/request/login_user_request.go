@@ -13,13 +13,13 @@ type LoginUser struct {
 func (r *LoginUser) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Email,
-			validation.Required.Error("Email wajib diisi"),
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.EmailFormat.Error("Format email tidak valid"),
+			validation.Required,
+			validation.Length(3, 50),
+			is.EmailFormat,
 		),
 		validation.Field(&r.Password,
-			validation.Required.Error("Kata sandi wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 	)
 }
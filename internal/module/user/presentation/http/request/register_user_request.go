@@ -19,24 +19,24 @@ type RegisterUser struct {
 func (r *RegisterUser) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Name,
-			validation.Required.Error("Nama wajib diisi"),
-			validation.Length(2, 64).Error("Panjang nama harus antara 2-64 karakter"),
+			validation.Required,
+			validation.Length(2, 64),
 		),
 		validation.Field(&r.PhoneNumber,
-			validation.Required.Error("Nomor telepon wajib diisi"),
-			validation.Length(10, 15).Error("Panjang nomor telepon harus antara 10-15 karakter"),
+			validation.Required,
+			validation.Length(10, 15),
 		),
 		validation.Field(&r.Email,
-			validation.Required.Error("Email wajib diisi"),
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.EmailFormat.Error("Format email tidak valid"),
+			validation.Required,
+			validation.Length(3, 50),
+			is.EmailFormat,
 		),
 		validation.Field(&r.Password,
-			validation.Required.Error("Kata sandi wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 		validation.Field(&r.PasswordConfirmation,
-			validation.Required.Error("Konfirmasi kata sandi wajib diisi"),
+			validation.Required,
 			validation.By(func(value any) error {
 				if value.(string) != r.Password {
 					return validation.NewError("password_mismatch", "Kata sandi tidak cocok")
@@ -45,14 +45,14 @@ func (r *RegisterUser) Validate() error {
 			}),
 		),
 		validation.Field(&r.InvitationCode,
-			validation.Length(6, 6).Error("Panjang kode undangan harus 6 karakter"),
+			validation.Length(6, 6),
 		),
 		validation.Field(&r.EmploymentIdentityNumber,
 			validation.When(
 				!goutil.IsZero(r.InvitationCode),
-				validation.Required.Error("NIP wajib diisi jika menggunakan kode undangan"),
+				validation.Required,
 			),
-			validation.Length(10, 50).Error("Panjang NIP harus antara 10-50 karakter"),
+			validation.Length(10, 50),
 		),
 	)
 }
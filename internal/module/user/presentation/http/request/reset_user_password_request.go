@@ -15,20 +15,20 @@ type ResetPassword struct {
 func (r *ResetPassword) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Email,
-			validation.Required.Error("Email wajib diisi"),
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.Email.Error("Format email tidak valid"),
+			validation.Required,
+			validation.Length(3, 50),
+			is.Email,
 		),
 		validation.Field(&r.Code,
-			validation.Required.Error("Kode reset wajib diisi"),
-			validation.Length(6, 6).Error("Panjang kode reset harus 6 karakter"),
+			validation.Required,
+			validation.Length(6, 6),
 		),
 		validation.Field(&r.Password,
-			validation.Required.Error("Kata sandi wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 		validation.Field(&r.PasswordConfirmation,
-			validation.Required.Error("Konfirmasi kata sandi wajib diisi"),
+			validation.Required,
 			validation.By(func(value any) error {
 				if value.(string) != r.Password {
 					return validation.NewError("password_mismatch", "Kata sandi tidak cocok")
@@ -9,8 +9,8 @@ type ToggleActivationUser struct {
 func (r *ToggleActivationUser) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Id,
-			validation.Required.Error("Id tidak boleh kosong"),
-			validation.Length(26, 26).Error("Id harus 26 karakter"),
+			validation.Required,
+			validation.Length(26, 26),
 		),
 	)
 }
@@ -11,15 +11,15 @@ type UpdateUserMePassword struct {
 func (r *UpdateUserMePassword) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.CurrentPassword,
-			validation.Required.Error("Kata sandi saat ini wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi saat ini minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 		validation.Field(&r.Password,
-			validation.Required.Error("Kata sandi wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 		validation.Field(&r.PasswordConfirmation,
-			validation.Required.Error("Konfirmasi kata sandi wajib diisi"),
+			validation.Required,
 			validation.By(func(value any) error {
 				if value.(string) != r.Password {
 					return validation.NewError("password_mismatch", "Kata sandi tidak cocok")
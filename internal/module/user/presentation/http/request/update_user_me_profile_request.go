@@ -1,6 +1,7 @@
 package request
 
 import (
+	"github.com/arfanxn/welding/internal/infrastructure/i18n"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 )
@@ -10,22 +11,26 @@ type UpdateUserMeProfile struct {
 	PhoneNumber              *string `form:"phone_number" json:"phone_number"`
 	Email                    *string `form:"email" json:"email"`
 	EmploymentIdentityNumber *string `form:"employment_identity_number" json:"employment_identity_number"`
+	Locale                   *string `form:"locale" json:"locale"`
 }
 
 func (r *UpdateUserMeProfile) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Name,
-			validation.Length(3, 64).Error("Panjang nama harus antara 3-64 karakter"),
+			validation.Length(3, 64),
 		),
 		validation.Field(&r.PhoneNumber,
-			validation.Length(10, 15).Error("Panjang nomor telepon harus antara 10-15 karakter"),
+			validation.Length(10, 15),
 		),
 		validation.Field(&r.Email,
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.Email.Error("Format email tidak valid"),
+			validation.Length(3, 50),
+			is.Email,
 		),
 		validation.Field(&r.EmploymentIdentityNumber,
-			validation.Length(10, 50).Error("Panjang NIP harus antara 10-50 karakter"),
+			validation.Length(10, 50),
+		),
+		validation.Field(&r.Locale,
+			validation.In(i18n.LocaleId, i18n.LocaleEn),
 		),
 	)
 }
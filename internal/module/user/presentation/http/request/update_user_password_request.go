@@ -10,11 +10,11 @@ type UpdateUserPassword struct {
 func (r *UpdateUserPassword) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Id,
-			validation.Required.Error("Id wajib diisi"),
+			validation.Required,
 		),
 		validation.Field(&r.Password,
-			validation.Required.Error("Kata sandi wajib diisi"),
-			validation.Length(8, 255).Error("Panjang kata sandi minimal 8 karakter"),
+			validation.Required,
+			validation.Length(8, 255),
 		),
 	)
 }
@@ -13,13 +13,13 @@ type VerifyEmail struct {
 func (r *VerifyEmail) Validate() error {
 	return validation.ValidateStruct(r,
 		validation.Field(&r.Email,
-			validation.Required.Error("Email wajib diisi"),
-			validation.Length(3, 50).Error("Panjang email harus antara 3-50 karakter"),
-			is.Email.Error("Format email tidak valid"),
+			validation.Required,
+			validation.Length(3, 50),
+			is.Email,
 		),
 		validation.Field(&r.Code,
-			validation.Required.Error("Kode verifikasi wajib diisi"),
-			validation.Length(6, 6).Error("Panjang kode verifikasi harus 6 karakter"),
+			validation.Required,
+			validation.Length(6, 6),
 		),
 	)
 }
@@ -1,13 +1,12 @@
 package http
 
 import (
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/arfanxn/welding/internal/infrastructure/http/helper"
+	"github.com/arfanxn/welding/internal/infrastructure/http/problem"
 	"github.com/arfanxn/welding/internal/infrastructure/http/response"
-	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
@@ -15,7 +14,6 @@ import (
 	"github.com/arfanxn/welding/internal/module/user/usecase"
 	"github.com/arfanxn/welding/internal/module/user/usecase/dto"
 	"github.com/arfanxn/welding/pkg/boolutil"
-	"github.com/arfanxn/welding/pkg/httperror"
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/gin-gonic/gin"
@@ -38,6 +36,7 @@ type UserHandler interface {
 	// UpdatePassword(c *gin.Context)
 	UpdateMePassword(c *gin.Context)
 	ToggleActivation(c *gin.Context)
+	GrantRole(c *gin.Context)
 	Destroy(c *gin.Context)
 }
 
@@ -66,27 +65,16 @@ func (h *userHandler) Register(c *gin.Context) {
 		EmploymentIdentityNumber: req.EmploymentIdentityNumber,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrCodeNotFound) {
-			httperror.Panic(http.StatusBadRequest, "Kode undangan tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeAlreadyUsed) {
-			httperror.Panic(http.StatusBadRequest, "Kode undangan sudah digunakan", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeExpired) {
-			httperror.Panic(http.StatusBadRequest, "Kode undangan sudah expired", nil)
-		}
-		if errors.Is(err, errorx.ErrRoleDefaultNotConfigured) {
-			httperror.Panic(http.StatusBadRequest, "Role default belum dikonfigurasi", nil)
-		}
-		if errors.Is(err, errorx.ErrUserAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "User sudah ada", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrCodeNotFound:    "error.code.invitation_not_found",
+			errorx.ErrCodeAlreadyUsed: "error.code.invitation_already_used",
+			errorx.ErrCodeExpired:     "error.code.invitation_expired",
+		})
 	}
 
 	c.JSON(http.StatusCreated, response.NewBodyWithData(
 		http.StatusCreated,
-		"Registrasi berhasil",
+		helper.T(c, "message.user.registered", nil),
 		gin.H{"user": user},
 	))
 }
@@ -100,27 +88,16 @@ func (h *userHandler) VerifyEmail(c *gin.Context) {
 		Code:  req.Code,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrCodeNotFound) {
-			httperror.Panic(http.StatusBadRequest, "Kode verifikasi salah", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeAlreadyUsed) {
-			httperror.Panic(http.StatusBadRequest, "Kode verifikasi sudah digunakan", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeExpired) {
-			httperror.Panic(http.StatusBadRequest, "Kode verifikasi sudah kadaluarsa", nil)
-		}
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserEmailAlreadyVerified) {
-			httperror.Panic(http.StatusBadRequest, "Email sudah diverifikasi", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrCodeNotFound:    "error.code.verification_not_found",
+			errorx.ErrCodeAlreadyUsed: "error.code.verification_already_used",
+			errorx.ErrCodeExpired:     "error.code.verification_expired",
+		})
 	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Email berhasil diverifikasi",
+		helper.T(c, "message.user.email_verified", nil),
 		gin.H{"user": user}),
 	)
 }
@@ -135,24 +112,16 @@ func (h *userHandler) ResetPassword(c *gin.Context) {
 		Password: req.Password,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrCodeNotFound) {
-			httperror.Panic(http.StatusBadRequest, "Kode reset password salah", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeAlreadyUsed) {
-			httperror.Panic(http.StatusBadRequest, "Kode reset password sudah digunakan", nil)
-		}
-		if errors.Is(err, errorx.ErrCodeExpired) {
-			httperror.Panic(http.StatusBadRequest, "Kode reset password sudah kadaluarsa", nil)
-		}
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrCodeNotFound:    "error.code.reset_password_not_found",
+			errorx.ErrCodeAlreadyUsed: "error.code.reset_password_already_used",
+			errorx.ErrCodeExpired:     "error.code.reset_password_expired",
+		})
 	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Password berhasil direset",
+		helper.T(c, "message.user.password_reset", nil),
 		gin.H{"user": user}),
 	)
 }
@@ -166,21 +135,18 @@ func (h *userHandler) Login(c *gin.Context) {
 		Password: req.Password,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserPasswordIncorrect) {
-			httperror.Panic(http.StatusUnauthorized, "Email atau password salah", nil)
-		}
 		panic(err)
 	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Login berhasil",
+		helper.T(c, "message.user.logged_in", nil),
 		gin.H{"user": loginResult.User, "token": loginResult.Token},
 	))
 }
 
 func (h *userHandler) Logout(c *gin.Context) {
-	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, "Logout berhasil"))
+	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, helper.T(c, "message.user.logged_out", nil)))
 }
 
 func (h *userHandler) Me(c *gin.Context) {
@@ -194,8 +160,11 @@ func (h *userHandler) Me(c *gin.Context) {
 	if err != nil {
 		panic(err)
 	}
+	if helper.NotModified(c, user.Version, user) {
+		return
+	}
 
-	c.JSON(http.StatusOK, response.NewBodyWithData(http.StatusOK, "User berhasil diambil", gin.H{"user": user}))
+	c.JSON(http.StatusOK, response.NewBodyWithData(http.StatusOK, helper.T(c, "message.user.shown", nil), gin.H{"user": user}))
 }
 
 func (h *userHandler) Show(c *gin.Context) {
@@ -205,15 +174,15 @@ func (h *userHandler) Show(c *gin.Context) {
 
 	user, err := h.userUsecase.Show(c.Request.Context(), q)
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
 		panic(err)
 	}
+	if helper.NotModified(c, user.Version, user) {
+		return
+	}
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"User berhasil diambil",
+		helper.T(c, "message.user.shown", nil),
 		gin.H{"user": user},
 	))
 }
@@ -229,7 +198,7 @@ func (h *userHandler) Paginate(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Users berhasil diambil",
+		helper.T(c, "message.user.paginated", nil),
 		pagination.PPFromOP(op, helper.URLFromC(c)),
 	))
 }
@@ -248,23 +217,15 @@ func (h *userHandler) Store(c *gin.Context) {
 		ActivatedAt:              &activatedAt,
 		RoleIds:                  req.RoleIds,
 		EmploymentIdentityNumber: req.EmploymentIdentityNumber,
+		Department:               req.Department,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrRolesNotFound) {
-			httperror.Panic(http.StatusNotFound, "Satu atau lebih role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminAssignmentForbidden) {
-			httperror.Panic(http.StatusForbidden, "User tidak dapat diberi role "+string(roleEnum.SuperAdmin), nil)
-		}
-		if errors.Is(err, errorx.ErrUserAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "User sudah ada", nil)
-		}
 		panic(err)
 	}
 
 	c.JSON(http.StatusCreated, response.NewBodyWithData(
 		http.StatusCreated,
-		"User berhasil disimpan",
+		helper.T(c, "message.user.stored", nil),
 		gin.H{"user": user},
 	))
 }
@@ -282,32 +243,17 @@ func (h *userHandler) Update(c *gin.Context) {
 		Password:                 req.Password,
 		RoleIds:                  req.RoleIds,
 		EmploymentIdentityNumber: req.EmploymentIdentityNumber,
+		Department:               req.Department,
+		Versions:                 helper.IfMatchVersionsFromC(c),
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrRolesNotFound) {
-			httperror.Panic(http.StatusNotFound, "Satu atau lebih role tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminUpdateForbidden) {
-			httperror.Panic(http.StatusForbidden, "User super admin tidak dapat diubah", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminRoleChangeForbidden) {
-			httperror.Panic(http.StatusForbidden, "User super admin tidak dapat diubah role", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminAssignmentForbidden) {
-			httperror.Panic(http.StatusForbidden, "User tidak dapat diberi role "+string(roleEnum.SuperAdmin), nil)
-		}
-		if errors.Is(err, errorx.ErrUserAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "User sudah ada", nil)
-		}
 		panic(err)
 	}
 
+	c.Header("ETag", helper.ETag(user.Version, user))
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"User berhasil diperbarui",
+		helper.T(c, "message.user.updated", nil),
 		gin.H{"user": user},
 	))
 }
@@ -324,17 +270,16 @@ func (h *userHandler) UpdateMeProfile(c *gin.Context) {
 		PhoneNumber:              req.PhoneNumber,
 		Email:                    req.Email,
 		EmploymentIdentityNumber: req.EmploymentIdentityNumber,
+		Locale:                   req.Locale,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserAlreadyExists) {
-			httperror.Panic(http.StatusConflict, "User sudah ada", nil)
-		}
 		panic(err)
 	}
 
+	c.Header("ETag", helper.ETag(user.Version, user))
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"User berhasil diperbarui",
+		helper.T(c, "message.user.updated", nil),
 		gin.H{"user": user},
 	))
 }
@@ -356,7 +301,7 @@ func (h *userHandler) UpdatePassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Password berhasil diperbarui",
+		helper.T(c, "message.user.password_updated", nil),
 		gin.H{"user": user},
 	))
 }
@@ -373,16 +318,14 @@ func (h *userHandler) UpdateMePassword(c *gin.Context) {
 		Password:        req.Password,
 	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserPasswordIncorrect) {
-			httperror.Panic(http.StatusBadRequest, "Password saat ini tidak sesuai", nil)
-		}
 		panic(err)
 	}
 
 	// Return success response with updated user data
+	c.Header("ETag", helper.ETag(user.Version, user))
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
-		"Password berhasil diperbarui",
+		helper.T(c, "message.user.password_updated", nil),
 		gin.H{"user": user},
 	))
 }
@@ -392,18 +335,18 @@ func (h *userHandler) ToggleActivation(c *gin.Context) {
 	req.Id = c.Param("id")
 	helper.MustBindValidate(c, req)
 
-	user, err := h.userUsecase.ToggleActivation(c.Request.Context(), &dto.ToggleActivation{Id: req.Id})
+	user, err := h.userUsecase.ToggleActivation(c.Request.Context(), &dto.ToggleActivation{
+		Id:       req.Id,
+		Versions: helper.IfMatchVersionsFromC(c),
+	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminUpdateForbidden) {
-			httperror.Panic(http.StatusForbidden, "User dengan role "+string(roleEnum.SuperAdmin)+" tidak dapat dinonaktifkan", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrUserSuperAdminUpdateForbidden: "error.user.super_admin_deactivate_forbidden",
+		})
 	}
 
-	message := boolutil.Ternary(user.ActivatedAt.Valid, "User berhasil diaktifkan", "User berhasil dinonaktifkan")
+	c.Header("ETag", helper.ETag(user.Version, user))
+	message := helper.T(c, boolutil.Ternary(user.ActivatedAt.Valid, "message.user.activated", "message.user.deactivated"), nil)
 
 	c.JSON(http.StatusOK, response.NewBodyWithData(
 		http.StatusOK,
@@ -412,21 +355,53 @@ func (h *userHandler) ToggleActivation(c *gin.Context) {
 	))
 }
 
+func (h *userHandler) GrantRole(c *gin.Context) {
+	req := &request.GrantUserRole{}
+	req.Id = c.Param("id")
+	helper.MustBindValidate(c, req)
+
+	// The request validated the dates
+	var startsAt *time.Time
+	if req.StartsAt != nil {
+		t, _ := time.Parse(time.DateTime, *req.StartsAt)
+		startsAt = &t
+	}
+	expiresAt, _ := time.Parse(time.DateTime, req.ExpiresAt)
+
+	grant, err := h.userUsecase.GrantRole(c.Request.Context(), &dto.GrantUserRole{
+		Id:        req.Id,
+		RoleId:    req.RoleId,
+		StartsAt:  startsAt,
+		ExpiresAt: expiresAt,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		problem.Panic(err, problem.Details{
+			errorx.ErrRolesNotFound: "error.role.not_found",
+		})
+	}
+
+	c.JSON(http.StatusCreated, response.NewBodyWithData(
+		http.StatusCreated,
+		helper.T(c, "message.user.role_granted", nil),
+		gin.H{"role_user": grant},
+	))
+}
+
 func (h *userHandler) Destroy(c *gin.Context) {
 	req := &request.DestroyUser{}
 	req.Id = c.Param("id")
 	helper.MustBindValidate(c, req)
 
-	err := h.userUsecase.Destroy(c.Request.Context(), &dto.DestroyUser{Id: req.Id})
+	err := h.userUsecase.Destroy(c.Request.Context(), &dto.DestroyUser{
+		Id:       req.Id,
+		Versions: helper.IfMatchVersionsFromC(c),
+	})
 	if err != nil {
-		if errors.Is(err, errorx.ErrUserNotFound) {
-			httperror.Panic(http.StatusNotFound, "User tidak ditemukan", nil)
-		}
-		if errors.Is(err, errorx.ErrUserSuperAdminUpdateForbidden) {
-			httperror.Panic(http.StatusForbidden, "User dengan role "+string(roleEnum.SuperAdmin)+" tidak dapat dihapus", nil)
-		}
-		panic(err)
+		problem.Panic(err, problem.Details{
+			errorx.ErrUserSuperAdminUpdateForbidden: "error.user.super_admin_destroy_forbidden",
+		})
 	}
 
-	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, "User berhasil dihapus"))
+	c.JSON(http.StatusOK, response.NewBody(http.StatusOK, helper.T(c, "message.user.destroyed", nil)))
 }
@@ -46,6 +46,10 @@ type SaveUser struct {
 	ActivatedAt              *time.Time `json:"activated_at"`
 	DeactivatedAt            *time.Time `json:"deactivated_at"`
 	EmploymentIdentityNumber *string    `json:"employment_identity_number"`
+	Department               *string    `json:"department"`
+	Locale                   *string    `json:"locale"`
+	// Versions lists the versions the user is expected to be at (If-Match), any version when empty
+	Versions []int64 `json:"versions"`
 }
 
 type UpdateUserMePassword struct {
@@ -59,9 +63,20 @@ type UpdateUserPassword struct {
 }
 
 type ToggleActivation struct {
-	Id string `json:"id"`
+	Id       string  `json:"id"`
+	Versions []int64 `json:"versions"`
 }
 
 type DestroyUser struct {
-	Id string `json:"id"`
+	Id       string  `json:"id"`
+	Versions []int64 `json:"versions"`
+}
+
+type GrantUserRole struct {
+	Id     string `json:"id"`
+	RoleId string `json:"role_id"`
+	// StartsAt is when the grant starts, now when nil
+	StartsAt  *time.Time `json:"starts_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Reason    string     `json:"reason"`
 }
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arfanxn/welding/internal/infrastructure/config"
+	"github.com/arfanxn/welding/internal/infrastructure/event"
+	permissionEnum "github.com/arfanxn/welding/internal/module/permission/domain/enum"
+	roleEnum "github.com/arfanxn/welding/internal/module/role/domain/enum"
+	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
+	roleUserRepository "github.com/arfanxn/welding/internal/module/role_user/domain/repository"
+	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	sharedEvent "github.com/arfanxn/welding/internal/module/shared/domain/event"
+	"github.com/arfanxn/welding/internal/module/user/domain/repository"
+	"github.com/arfanxn/welding/pkg/query"
+	"github.com/guregu/null/v6"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/fx"
+)
+
+// maxCachedUserPermissions bounds the users whose permissions are cached, the expired ones are evicted
+// once it is reached and every one of them when none is expired
+const maxCachedUserPermissions = 10000
+
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/user/usecase/service")
+
+// EffectivePermissions are the roles of a user and the permissions they grant, resolved at the
+// permission version of the user. PermissionNames are the permissions granted to the roles and to
+// the roles they inherit from as is, wildcards included.
+type EffectivePermissions struct {
+	Version   int64
+	RoleIds   []string
+	RoleNames []roleEnum.RoleName
+	// AncestorRoleIds are the roles the roles inherit from, a change to them changes the permissions too
+	AncestorRoleIds []string
+	PermissionNames []permissionEnum.PermissionName
+	// ChangesAt is when a temporary role of the user begins or expires next, the permissions are stale from then
+	ChangesAt null.Time
+}
+
+// HasPermissionNames reports whether every one of permissionNames is granted, by wildcard or implication included
+func (p *EffectivePermissions) HasPermissionNames(permissionNames ...permissionEnum.PermissionName) bool {
+	return permissionEnum.Granted(p.PermissionNames, permissionNames...)
+}
+
+// HasRoleNames reports whether every one of roleNames is held
+func (p *EffectivePermissions) HasRoleNames(roleNames ...roleEnum.RoleName) bool {
+	return lo.Every(p.RoleNames, roleNames)
+}
+
+type UserPermissionService interface {
+	// Resolve returns the effective permissions of user. They are cached for PERMISSION_CACHE_TTL, until
+	// an event reports a change of the roles or permissions they come from, or until the permission
+	// version of user moves on, e.g. when they were changed by another instance. A temporary role grant
+	// beginning or expiring ends the caching as well.
+	Resolve(ctx context.Context, user *entity.User) (*EffectivePermissions, error)
+	HasPermissionNames(ctx context.Context, user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error)
+	HasRoleNames(ctx context.Context, user *entity.User, roleNames []roleEnum.RoleName) (bool, error)
+}
+
+var _ UserPermissionService = (*userPermissionService)(nil)
+
+type cachedUserPermissions struct {
+	permissions *EffectivePermissions
+	expiresAt   time.Time
+}
+
+type userPermissionService struct {
+	ttl                time.Duration
+	userRepository     repository.UserRepository
+	roleRepository     roleRepository.RoleRepository
+	roleUserRepository roleUserRepository.RoleUserRepository
+
+	mu      sync.RWMutex
+	entries map[string]*cachedUserPermissions
+}
+
+type NewUserPermissionServiceParams struct {
+	fx.In
+
+	Config             *config.Config
+	EventBus           event.EventBus
+	UserRepository     repository.UserRepository
+	RoleRepository     roleRepository.RoleRepository
+	RoleUserRepository roleUserRepository.RoleUserRepository
+}
+
+func NewUserPermissionService(params NewUserPermissionServiceParams) UserPermissionService {
+	s := &userPermissionService{
+		ttl:                params.Config.PermissionCacheTTL,
+		userRepository:     params.UserRepository,
+		roleRepository:     params.RoleRepository,
+		roleUserRepository: params.RoleUserRepository,
+		entries:            map[string]*cachedUserPermissions{},
+	}
+
+	params.EventBus.Subscribe(sharedEvent.UserRolesChanged{}.EventName(), s.onUserRolesChanged)
+	params.EventBus.Subscribe(sharedEvent.RoleChanged{}.EventName(), s.onRoleChanged)
+	params.EventBus.Subscribe(sharedEvent.PermissionsChanged{}.EventName(), s.onPermissionsChanged)
+
+	return s
+}
+
+func (s *userPermissionService) Resolve(ctx context.Context, user *entity.User) (*EffectivePermissions, error) {
+	ctx, span := tracer.Start(ctx, "UserPermissionService.Resolve")
+	defer span.End()
+
+	if permissions, ok := s.cached(user); ok {
+		span.SetAttributes(attribute.Bool("authz.cache_hit", true))
+		return permissions, nil
+	}
+	span.SetAttributes(attribute.Bool("authz.cache_hit", false))
+
+	userWithRoles, err := s.userRepository.First(ctx, query.NewQuery().FilterById(user.Id).Include("Roles.Permissions"))
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := &EffectivePermissions{Version: userWithRoles.PermissionVersion}
+	for _, role := range userWithRoles.Roles {
+		permissions.RoleIds = append(permissions.RoleIds, role.Id)
+		permissions.RoleNames = append(permissions.RoleNames, role.Name)
+		for _, permission := range role.Permissions {
+			permissions.PermissionNames = append(permissions.PermissionNames, permission.Name)
+		}
+	}
+
+	// The roles also grant the permissions of the roles they inherit from
+	ancestors, err := s.roleRepository.FindAncestors(ctx, permissions.RoleIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, ancestor := range ancestors {
+		permissions.AncestorRoleIds = append(permissions.AncestorRoleIds, ancestor.Id)
+		for _, permission := range ancestor.Permissions {
+			permissions.PermissionNames = append(permissions.PermissionNames, permission.Name)
+		}
+	}
+	permissions.PermissionNames = lo.Uniq(permissions.PermissionNames)
+
+	permissions.ChangesAt, err = s.roleUserRepository.NextChangeAt(ctx, user.Id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache(user.Id, permissions)
+
+	return permissions, nil
+}
+
+func (s *userPermissionService) HasPermissionNames(ctx context.Context, user *entity.User, permissionNames []permissionEnum.PermissionName) (bool, error) {
+	permissions, err := s.Resolve(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return permissions.HasPermissionNames(permissionNames...), nil
+}
+
+func (s *userPermissionService) HasRoleNames(ctx context.Context, user *entity.User, roleNames []roleEnum.RoleName) (bool, error) {
+	permissions, err := s.Resolve(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return permissions.HasRoleNames(roleNames...), nil
+}
+
+// cached returns the permissions cached for user while they are fresh and resolved at its permission version
+func (s *userPermissionService) cached(user *entity.User) (*EffectivePermissions, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[user.Id]
+	if !ok || time.Now().After(entry.expiresAt) || entry.permissions.Version != user.PermissionVersion {
+		return nil, false
+	}
+	return entry.permissions, true
+}
+
+func (s *userPermissionService) cache(userId string, permissions *EffectivePermissions) {
+	if s.ttl <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.entries) >= maxCachedUserPermissions {
+		now := time.Now()
+		for id, entry := range s.entries {
+			if now.After(entry.expiresAt) {
+				delete(s.entries, id)
+			}
+		}
+		if len(s.entries) >= maxCachedUserPermissions {
+			clear(s.entries)
+		}
+	}
+
+	expiresAt := time.Now().Add(s.ttl)
+	if permissions.ChangesAt.Valid && permissions.ChangesAt.Time.Before(expiresAt) {
+		expiresAt = permissions.ChangesAt.Time
+	}
+	s.entries[userId] = &cachedUserPermissions{
+		permissions: permissions,
+		expiresAt:   expiresAt,
+	}
+}
+
+func (s *userPermissionService) onUserRolesChanged(_ context.Context, e event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, e.(sharedEvent.UserRolesChanged).UserId)
+	return nil
+}
+
+func (s *userPermissionService) onRoleChanged(_ context.Context, e event.Event) error {
+	roleId := e.(sharedEvent.RoleChanged).RoleId
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userId, entry := range s.entries {
+		if lo.Contains(entry.permissions.RoleIds, roleId) || lo.Contains(entry.permissions.AncestorRoleIds, roleId) {
+			delete(s.entries, userId)
+		}
+	}
+	return nil
+}
+
+func (s *userPermissionService) onPermissionsChanged(_ context.Context, _ event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.entries)
+	return nil
+}
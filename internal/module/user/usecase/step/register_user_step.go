@@ -5,6 +5,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
 	"github.com/arfanxn/welding/internal/module/code/domain/enum"
 	codeRepository "github.com/arfanxn/welding/internal/module/code/domain/repository"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
@@ -20,7 +21,8 @@ type RegisterUserStep interface {
 }
 
 type registerUserStep struct {
-	saveUserStep SaveUserStep
+	saveUserStep   SaveUserStep
+	metricsService metrics.MetricsService
 
 	userRepository userRepository.UserRepository
 	codeRepository codeRepository.CodeRepository
@@ -29,13 +31,15 @@ type registerUserStep struct {
 
 func NewRegisterUserStep(
 	saveUserStep SaveUserStep,
+	metricsService metrics.MetricsService,
 
 	userRepository userRepository.UserRepository,
 	codeRepository codeRepository.CodeRepository,
 	roleRepository roleRepository.RoleRepository,
 ) RegisterUserStep {
 	return &registerUserStep{
-		saveUserStep: saveUserStep,
+		saveUserStep:   saveUserStep,
+		metricsService: metricsService,
 
 		userRepository: userRepository,
 		codeRepository: codeRepository,
@@ -62,6 +66,9 @@ func (s *registerUserStep) Handle(
 	ctx context.Context,
 	_dto *dto.Register,
 ) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "RegisterUserStep.Handle")
+	defer span.End()
+
 	// Initialize variables for role determination and invitation handling
 	var (
 		err                  error
@@ -73,7 +80,7 @@ func (s *registerUserStep) Handle(
 	// Handle invitation-based registration
 	if isWithInvitationCode {
 		// Find invitation code by type and value
-		code, err = s.codeRepository.FindByTypeAndValue(enum.UserRegisterInvitation, *_dto.InvitationCode)
+		code, err = s.codeRepository.FindByTypeAndValue(ctx, enum.UserRegisterInvitation, *_dto.InvitationCode)
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, errorx.ErrCodeNotFound
@@ -101,7 +108,7 @@ func (s *registerUserStep) Handle(
 		roleIds = []string{roleId}
 	} else {
 		// Handle default registration without invitation code
-		defaultRole, err := s.roleRepository.FindDefault()
+		defaultRole, err := s.roleRepository.FindDefault(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -125,9 +132,10 @@ func (s *registerUserStep) Handle(
 	// Mark invitation code as used if one was provided
 	if isWithInvitationCode {
 		code.MarkUsed()
-		if err := s.codeRepository.Save(code); err != nil {
+		if err := s.codeRepository.Save(ctx, code); err != nil {
 			return nil, err
 		}
+		s.metricsService.IncCodeRedeemed(code.Type)
 	}
 
 	return user, err
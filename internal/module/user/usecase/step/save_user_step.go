@@ -9,6 +9,7 @@ import (
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
 	roleUserRepository "github.com/arfanxn/welding/internal/module/role_user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
+	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
 	userRepository "github.com/arfanxn/welding/internal/module/user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/user/usecase/dto"
 	"github.com/arfanxn/welding/pkg/query"
@@ -61,7 +62,7 @@ func NewSaveUserStep(params NewSaveUserStepParams) SaveUserStep {
 // - Basic user information (name, phone, email, password)
 // - Account activation/deactivation status
 // - User role assignments
-// - Employee association with employment identity number
+// - Employee association with employment identity number and department
 //
 // Parameters:
 //   - ctx: Context for the operation
@@ -71,6 +72,9 @@ func NewSaveUserStep(params NewSaveUserStepParams) SaveUserStep {
 //   - *entity.User: The saved/updated user with all associations
 //   - error: Any error encountered during the operation
 func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "SaveUserStep.Handle")
+	defer span.End()
+
 	// Initialize query and include relationships
 	var (
 		q      = query.NewQuery()
@@ -81,7 +85,7 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 
 	// Conditionally include Employee relationship only when employee data is provided
 	// This optimizes database queries by avoiding unnecessary JOIN operations
-	if _dto.EmploymentIdentityNumber != nil {
+	if _dto.EmploymentIdentityNumber != nil || _dto.Department != nil {
 		q.Include("Employee")
 	}
 
@@ -96,10 +100,13 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 		// Update scenario: fetch existing user
 		userId = *_dto.Id
 		q = q.FilterById(userId)
-		user, err = s.userRepository.First(q)
+		user, err = s.userRepository.First(ctx, q)
 		if err != nil {
 			return nil, err
 		}
+		if !entity.MatchesVersion(user.Version, _dto.Versions) {
+			return nil, errorx.ErrUserModified
+		}
 	} else {
 		// Create scenario: initialize new user with generated ID
 		userId = s.idService.Generate()
@@ -107,6 +114,17 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 		user = &entity.User{Id: userId}
 	}
 
+	// Only employees belong to a department, checked before anything is saved
+	if !goutil.IsEmptyReal(_dto.Department) {
+		isEmployee := user.Employee != nil
+		if _dto.EmploymentIdentityNumber != nil {
+			isEmployee = !goutil.IsEmptyReal(_dto.EmploymentIdentityNumber)
+		}
+		if !isEmployee {
+			return nil, errorx.ErrUserNotEmployee
+		}
+	}
+
 	// Update basic user information if provided
 	if !goutil.IsEmptyReal(_dto.Name) {
 		user.Name = *_dto.Name
@@ -114,6 +132,9 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 	if !goutil.IsEmptyReal(_dto.PhoneNumber) {
 		user.PhoneNumber = *_dto.PhoneNumber
 	}
+	if !goutil.IsEmptyReal(_dto.Locale) {
+		user.Locale = null.StringFrom(*_dto.Locale)
+	}
 
 	// Handle email update - reset email verification if email changed
 	if !goutil.IsEmptyReal(_dto.Email) {
@@ -142,14 +163,14 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 	}
 
 	// Save user basic information
-	if err := s.userRepository.Save(user); err != nil {
+	if err := s.userRepository.Save(ctx, user); err != nil {
 		return nil, err
 	}
 
 	// Handle role assignments - replace all existing roles with new ones
 	if _dto.RoleIds != nil {
 		// Remove all existing role associations for this user
-		if err := s.roleUserRepository.DestroyByUserId(user.Id); err != nil {
+		if err := s.roleUserRepository.DestroyByUserId(ctx, user.Id); err != nil {
 			return nil, err
 		}
 
@@ -158,7 +179,7 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 			rus := lo.Map(_dto.RoleIds, func(roleId string, _ int) *entity.RoleUser {
 				return &entity.RoleUser{RoleId: roleId, UserId: user.Id}
 			})
-			if err := s.roleUserRepository.SaveMany(rus); err != nil {
+			if err := s.roleUserRepository.SaveMany(ctx, rus); err != nil {
 				return nil, err
 			}
 		}
@@ -172,20 +193,28 @@ func (s *saveUserStep) Handle(ctx context.Context, _dto *dto.SaveUser) (*entity.
 				user.Employee = &entity.Employee{UserId: user.Id}
 			}
 			user.Employee.EmploymentIdentityNumber = *_dto.EmploymentIdentityNumber
-			if err := s.employeeRepository.Save(user.Employee); err != nil {
+			if err := s.employeeRepository.Save(ctx, user.Employee); err != nil {
 				return nil, err
 			}
 		} else {
 			// Remove employee record if employment identity number is empty
-			if err := s.employeeRepository.DestroyByUserId(user.Id); err != nil {
+			if err := s.employeeRepository.DestroyByUserId(ctx, user.Id); err != nil {
 				return nil, err
 			}
 			user.Employee = nil
 		}
 	}
 
+	// Handle the department of the employee, an empty department removes it
+	if _dto.Department != nil && user.Employee != nil {
+		user.Employee.Department = null.NewString(*_dto.Department, *_dto.Department != "")
+		if err := s.employeeRepository.Save(ctx, user.Employee); err != nil {
+			return nil, err
+		}
+	}
+
 	// Fetch complete user with all associations to return
-	user, err = s.userRepository.First(q)
+	user, err = s.userRepository.First(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,6 @@
+// Package step contains reusable units of work shared by the user use cases.
+package step
+
+import "go.opentelemetry.io/otel"
+
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/user/usecase/step")
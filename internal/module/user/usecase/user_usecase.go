@@ -2,17 +2,26 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/arfanxn/welding/internal/infrastructure/event"
 	"github.com/arfanxn/welding/internal/infrastructure/http/jwt"
 	"github.com/arfanxn/welding/internal/infrastructure/logger"
+	"github.com/arfanxn/welding/internal/infrastructure/metrics"
 	"github.com/arfanxn/welding/internal/infrastructure/security"
+	auditLogEnum "github.com/arfanxn/welding/internal/module/audit_log/domain/enum"
+	auditLogDto "github.com/arfanxn/welding/internal/module/audit_log/usecase/dto"
+	auditLogService "github.com/arfanxn/welding/internal/module/audit_log/usecase/service"
 	"github.com/arfanxn/welding/internal/module/code/domain/enum"
 	codeRepository "github.com/arfanxn/welding/internal/module/code/domain/repository"
 	roleRepository "github.com/arfanxn/welding/internal/module/role/domain/repository"
+	roleUserRepository "github.com/arfanxn/welding/internal/module/role_user/domain/repository"
+	roleUserService "github.com/arfanxn/welding/internal/module/role_user/usecase/service"
 	"github.com/arfanxn/welding/internal/module/shared/contextkey"
 	"github.com/arfanxn/welding/internal/module/shared/domain/entity"
 	"github.com/arfanxn/welding/internal/module/shared/domain/errorx"
+	sharedEvent "github.com/arfanxn/welding/internal/module/shared/domain/event"
 	"github.com/arfanxn/welding/internal/module/user/domain/repository"
 	"github.com/arfanxn/welding/internal/module/user/infrastructure/policy"
 	"github.com/arfanxn/welding/internal/module/user/usecase/dto"
@@ -20,9 +29,13 @@ import (
 	"github.com/arfanxn/welding/pkg/pagination"
 	"github.com/arfanxn/welding/pkg/query"
 	"github.com/guregu/null/v6"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/fx"
 )
 
+var tracer = otel.Tracer("github.com/arfanxn/welding/internal/module/user/usecase")
+
 var _ UserUsecase = (*userUsecase)(nil)
 
 type UserUsecase interface {
@@ -38,6 +51,8 @@ type UserUsecase interface {
 	// ! Deprecated
 	// UpdatePassword(ctx context.Context, _dto *dto.UpdateUserPassword) (*entity.User, error)
 	ToggleActivation(ctx context.Context, _dto *dto.ToggleActivation) (*entity.User, error)
+	// GrantRole grants a role to a user temporarily, replacing the temporary grant of the role they hold
+	GrantRole(ctx context.Context, _dto *dto.GrantUserRole) (*entity.RoleUser, error)
 	Destroy(ctx context.Context, _dto *dto.DestroyUser) error
 }
 
@@ -45,14 +60,19 @@ type userUsecase struct {
 	registerUserStep step.RegisterUserStep
 	saveUserStep     step.SaveUserStep
 
-	userPolicy     policy.UserPolicy
-	userRepository repository.UserRepository
-	roleRepository roleRepository.RoleRepository
-	codeRepository codeRepository.CodeRepository
-
-	jwtService      jwt.JWTService
-	passwordService security.PasswordService
-	logger          *logger.Logger
+	userPolicy         policy.UserPolicy
+	userRepository     repository.UserRepository
+	roleRepository     roleRepository.RoleRepository
+	codeRepository     codeRepository.CodeRepository
+	roleUserRepository roleUserRepository.RoleUserRepository
+
+	jwtService       jwt.JWTService
+	passwordService  security.PasswordService
+	metricsService   metrics.MetricsService
+	auditLogService  auditLogService.AuditLogService
+	eventBus         event.EventBus
+	logger           *logger.Logger
+	roleGrantService roleUserService.RoleGrantService
 }
 
 type NewUserUsecaseParams struct {
@@ -61,14 +81,19 @@ type NewUserUsecaseParams struct {
 	RegisterUserStep step.RegisterUserStep
 	SaveUserStep     step.SaveUserStep
 
-	UserPolicy     policy.UserPolicy
-	UserRepository repository.UserRepository
-	RoleRepository roleRepository.RoleRepository
-	CodeRepository codeRepository.CodeRepository
-
-	JWTService      jwt.JWTService
-	PasswordService security.PasswordService
-	Logger          *logger.Logger
+	UserPolicy         policy.UserPolicy
+	UserRepository     repository.UserRepository
+	RoleRepository     roleRepository.RoleRepository
+	CodeRepository     codeRepository.CodeRepository
+	RoleUserRepository roleUserRepository.RoleUserRepository
+
+	JWTService       jwt.JWTService
+	PasswordService  security.PasswordService
+	MetricsService   metrics.MetricsService
+	AuditLogService  auditLogService.AuditLogService
+	EventBus         event.EventBus
+	Logger           *logger.Logger
+	RoleGrantService roleUserService.RoleGrantService
 }
 
 func NewUserUsecase(params NewUserUsecaseParams) UserUsecase {
@@ -76,14 +101,19 @@ func NewUserUsecase(params NewUserUsecaseParams) UserUsecase {
 		registerUserStep: params.RegisterUserStep,
 		saveUserStep:     params.SaveUserStep,
 
-		userPolicy:     params.UserPolicy,
-		userRepository: params.UserRepository,
-		roleRepository: params.RoleRepository,
-		codeRepository: params.CodeRepository,
-
-		jwtService:      params.JWTService,
-		passwordService: params.PasswordService,
-		logger:          params.Logger,
+		userPolicy:         params.UserPolicy,
+		userRepository:     params.UserRepository,
+		roleRepository:     params.RoleRepository,
+		codeRepository:     params.CodeRepository,
+		roleUserRepository: params.RoleUserRepository,
+
+		jwtService:       params.JWTService,
+		passwordService:  params.PasswordService,
+		metricsService:   params.MetricsService,
+		auditLogService:  params.AuditLogService,
+		eventBus:         params.EventBus,
+		logger:           params.Logger,
+		roleGrantService: params.RoleGrantService,
 	}
 }
 
@@ -92,6 +122,9 @@ func NewUserUsecase(params NewUserUsecaseParams) UserUsecase {
 // 2. Creates a new user with the provided details
 // 3. If an invitation code was used, marks it as used
 func (u *userUsecase) Register(ctx context.Context, _dto *dto.Register) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.Register")
+	defer span.End()
+
 	user, err := u.registerUserStep.Handle(ctx, _dto)
 	if err != nil {
 		return nil, err
@@ -101,7 +134,11 @@ func (u *userUsecase) Register(ctx context.Context, _dto *dto.Register) (*entity
 }
 
 func (u *userUsecase) VerifyEmail(ctx context.Context, _dto *dto.VerifyEmail) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.VerifyEmail")
+	defer span.End()
+
 	code, err := u.codeRepository.FindByCodeableAndTypeAndValue(
+		ctx,
 		_dto.Email,
 		"email",
 		enum.UserEmailVerification,
@@ -119,7 +156,7 @@ func (u *userUsecase) VerifyEmail(ctx context.Context, _dto *dto.VerifyEmail) (*
 		return nil, errorx.ErrCodeExpired
 	}
 
-	user, err := u.userRepository.FindByEmail(_dto.Email)
+	user, err := u.userRepository.FindByEmail(ctx, _dto.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -129,20 +166,25 @@ func (u *userUsecase) VerifyEmail(ctx context.Context, _dto *dto.VerifyEmail) (*
 	}
 
 	user.EmailVerifiedAt = null.TimeFrom(time.Now())
-	if err := u.userRepository.Save(user); err != nil {
+	if err := u.userRepository.Save(ctx, user); err != nil {
 		return nil, err
 	}
 
 	code.UsedAt = null.TimeFrom(time.Now())
-	if err := u.codeRepository.Save(code); err != nil {
+	if err := u.codeRepository.Save(ctx, code); err != nil {
 		return nil, err
 	}
+	u.metricsService.IncCodeRedeemed(code.Type)
 
 	return user, nil
 }
 
 func (u *userUsecase) ResetPassword(ctx context.Context, _dto *dto.ResetPassword) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.ResetPassword")
+	defer span.End()
+
 	code, err := u.codeRepository.FindByCodeableAndTypeAndValue(
+		ctx,
 		_dto.Email,
 		"email",
 		enum.UserResetPassword,
@@ -160,7 +202,7 @@ func (u *userUsecase) ResetPassword(ctx context.Context, _dto *dto.ResetPassword
 		return nil, errorx.ErrCodeExpired
 	}
 
-	user, err := u.userRepository.FindByEmail(_dto.Email)
+	user, err := u.userRepository.FindByEmail(ctx, _dto.Email)
 	if err != nil {
 		return nil, err
 	}
@@ -170,33 +212,48 @@ func (u *userUsecase) ResetPassword(ctx context.Context, _dto *dto.ResetPassword
 		return nil, err
 	}
 
-	if err := u.userRepository.Save(user); err != nil {
+	if err := u.userRepository.Save(ctx, user); err != nil {
 		return nil, err
 	}
 
 	code.UsedAt = null.TimeFrom(time.Now())
-	if err := u.codeRepository.Save(code); err != nil {
+	if err := u.codeRepository.Save(ctx, code); err != nil {
 		return nil, err
 	}
+	u.metricsService.IncCodeRedeemed(code.Type)
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserResetPassword,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   user.Id,
+	})
 
 	return user, nil
 }
 
 func (u *userUsecase) Login(ctx context.Context, loginDto *dto.Login) (*dto.LoginResult, error) {
-	user, err := u.userRepository.FindByEmail(loginDto.Email)
+	ctx, span := tracer.Start(ctx, "UserUsecase.Login")
+	defer span.End()
+
+	user, err := u.userRepository.FindByEmail(ctx, loginDto.Email)
 	if err != nil {
-		return nil, errorx.ErrUserPasswordIncorrect
+		u.metricsService.IncLogin(metrics.LoginResultFailure)
+		return nil, errorx.ErrUserCredentialsInvalid
 	}
 
 	if err = u.passwordService.Check(user.Password, loginDto.Password); err != nil {
-		return nil, errorx.ErrUserPasswordIncorrect
+		u.metricsService.IncLogin(metrics.LoginResultFailure)
+		return nil, errorx.ErrUserCredentialsInvalid
 	}
 
-	token, err := u.jwtService.CreateToken(user.Id)
+	token, err := u.jwtService.CreateToken(user.Id, user.PermissionVersion)
 	if err != nil {
-		return nil, errorx.ErrUserPasswordIncorrect
+		u.metricsService.IncLogin(metrics.LoginResultFailure)
+		return nil, errorx.ErrUserCredentialsInvalid
 	}
 
+	u.metricsService.IncLogin(metrics.LoginResultSuccess)
+
 	return &dto.LoginResult{
 		User:  user,
 		Token: token,
@@ -204,34 +261,95 @@ func (u *userUsecase) Login(ctx context.Context, loginDto *dto.Login) (*dto.Logi
 }
 
 func (u *userUsecase) Show(ctx context.Context, q *query.Query) (*entity.User, error) {
-	user, err := u.userRepository.First(q)
+	ctx, span := tracer.Start(ctx, "UserUsecase.Show")
+	defer span.End()
+
+	user, err := u.userRepository.First(ctx, q)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := u.userPolicy.Show(ctx, user); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
 func (u *userUsecase) Paginate(ctx context.Context, q *query.Query) (*pagination.OffsetPagination[*entity.User], error) {
-	return u.userRepository.Paginate(q)
+	ctx, span := tracer.Start(ctx, "UserUsecase.Paginate")
+	defer span.End()
+
+	return u.userRepository.Paginate(ctx, q)
 }
 
 func (u *userUsecase) Store(ctx context.Context, _dto *dto.SaveUser) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.Store")
+	defer span.End()
+
 	if err := u.userPolicy.Store(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	return u.saveUserStep.Handle(ctx, _dto)
+	user, err := u.saveUserStep.Handle(ctx, _dto)
+	if err != nil {
+		return nil, err
+	}
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserStore,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   user.Id,
+		After:      user,
+	})
+
+	return user, nil
 }
 
 func (u *userUsecase) Update(ctx context.Context, _dto *dto.SaveUser) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.Update")
+	defer span.End()
+
 	if err := u.userPolicy.Update(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	return u.saveUserStep.Handle(ctx, _dto)
+	// The state before the update, with the relations the update may change
+	q := query.NewQuery().FilterById(*_dto.Id)
+	if _dto.EmploymentIdentityNumber != nil {
+		q.Include("Employee")
+	}
+	if _dto.RoleIds != nil {
+		q.Include("Roles")
+	}
+	before, err := u.userRepository.First(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := u.saveUserStep.Handle(ctx, _dto)
+	if err != nil {
+		return nil, err
+	}
+
+	if _dto.RoleIds != nil {
+		u.eventBus.Publish(ctx, sharedEvent.UserRolesChanged{UserId: user.Id})
+	}
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserUpdate,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   user.Id,
+		Before:     before,
+		After:      user,
+	})
+
+	return user, nil
 }
 
 func (u *userUsecase) UpdateMePassword(ctx context.Context, _dto *dto.UpdateUserMePassword) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.UpdateMePassword")
+	defer span.End()
+
 	userId := ctx.Value(contextkey.UserIdKey).(string)
 
 	err := u.userPolicy.UpdateMePassword(ctx, _dto)
@@ -268,16 +386,94 @@ func (u *userUsecase) UpdatePassword(ctx context.Context, _dto *dto.UpdateUserPa
 */
 
 func (u *userUsecase) ToggleActivation(ctx context.Context, _dto *dto.ToggleActivation) (*entity.User, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.ToggleActivation")
+	defer span.End()
+
 	if err := u.userPolicy.ToggleActivation(ctx, _dto); err != nil {
 		return nil, err
 	}
 
-	user, err := u.userRepository.Find(_dto.Id)
+	user, err := u.userRepository.Find(ctx, _dto.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !entity.MatchesVersion(user.Version, _dto.Versions) {
+		return nil, errorx.ErrUserModified
+	}
+	before := *user
+
+	user, err = u.userRepository.ToggleActivation(ctx, user)
 	if err != nil {
 		return nil, err
 	}
 
-	return u.userRepository.ToggleActivation(user)
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserToggleActivation,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   user.Id,
+		Before:     &before,
+		After:      user,
+	})
+
+	return user, nil
+}
+
+func (u *userUsecase) GrantRole(ctx context.Context, _dto *dto.GrantUserRole) (*entity.RoleUser, error) {
+	ctx, span := tracer.Start(ctx, "UserUsecase.GrantRole")
+	defer span.End()
+
+	if err := u.userPolicy.GrantRole(ctx, _dto); err != nil {
+		return nil, err
+	}
+
+	// A permanent grant is not shortened, a temporary one is replaced
+	var before *entity.RoleUser
+	grant, err := u.roleUserRepository.Find(ctx, _dto.RoleId, _dto.Id)
+	switch {
+	case errors.Is(err, errorx.ErrRoleUserNotFound):
+		grant = &entity.RoleUser{RoleId: _dto.RoleId, UserId: _dto.Id}
+	case err != nil:
+		return nil, err
+	case !grant.IsTemporary():
+		return nil, errorx.ErrRoleUserAlreadyGranted
+	default:
+		before = lo.ToPtr(*grant)
+	}
+
+	now := time.Now()
+	grant.StartsAt = lo.FromPtrOr(_dto.StartsAt, now)
+	grant.ExpiresAt = null.TimeFrom(_dto.ExpiresAt)
+	grant.Reason = null.StringFrom(_dto.Reason)
+	grant.BeganAt = null.Time{}
+	if err := u.roleUserRepository.Save(ctx, grant); err != nil {
+		return nil, err
+	}
+	u.eventBus.Publish(ctx, sharedEvent.UserRolesChanged{UserId: grant.UserId})
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserGrantRole,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   grant.UserId,
+		Before:     before,
+		After:      grant,
+	})
+
+	if grant.Role, err = u.roleRepository.Find(ctx, grant.RoleId); err != nil {
+		return nil, err
+	}
+
+	// A grant in effect already begins now, a later one is begun by the scheduler
+	if grant.IsInEffect(now) {
+		if grant.User, err = u.userRepository.Find(ctx, grant.UserId); err != nil {
+			return nil, err
+		}
+		if err := u.roleGrantService.Begin(ctx, grant); err != nil {
+			return nil, err
+		}
+		grant.User = nil
+	}
+
+	return grant, nil
 }
 
 func (u *userUsecase) Destroy(ctx context.Context, _dto *dto.DestroyUser) error {
@@ -285,10 +481,25 @@ func (u *userUsecase) Destroy(ctx context.Context, _dto *dto.DestroyUser) error
 		return err
 	}
 
-	user, err := u.userRepository.Find(_dto.Id)
+	user, err := u.userRepository.Find(ctx, _dto.Id)
 	if err != nil {
 		return err
 	}
+	if !entity.MatchesVersion(user.Version, _dto.Versions) {
+		return errorx.ErrUserModified
+	}
+
+	if err := u.userRepository.Destroy(ctx, user); err != nil {
+		return err
+	}
+	u.eventBus.Publish(ctx, sharedEvent.UserRolesChanged{UserId: user.Id})
+
+	u.auditLogService.Record(ctx, &auditLogDto.RecordAuditLog{
+		Action:     auditLogEnum.UserDestroy,
+		TargetType: auditLogEnum.TargetUser,
+		TargetId:   user.Id,
+		Before:     user,
+	})
 
-	return u.userRepository.Destroy(user)
+	return nil
 }
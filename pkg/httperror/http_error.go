@@ -8,6 +8,8 @@ type HttpError struct {
 	Code    int       `json:"code"`
 	Message string    `json:"message"`
 	Errors  ErrorsMap `json:"errors,omitempty"`
+	// ErrorCode is the stable, machine readable code of the error, e.g. code.already_used
+	ErrorCode string `json:"error_code,omitempty"`
 }
 
 func New(code int, message string, errors ErrorsMap) *HttpError {
@@ -22,6 +24,12 @@ func Panic(code int, message string, errors ErrorsMap) {
 	panic(New(code, message, errors))
 }
 
+// WithErrorCode sets the stable error code and returns the error for chaining
+func (e *HttpError) WithErrorCode(errorCode string) *HttpError {
+	e.ErrorCode = errorCode
+	return e
+}
+
 func (e HttpError) Error() string {
 	return e.Message
 }
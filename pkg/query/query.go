@@ -43,6 +43,13 @@ type Filter struct {
 	Value    string `json:"value"`
 }
 
+const (
+	// DefaultPerPage is the number of items of a page when per_page is not positive
+	DefaultPerPage = 10
+	// MaxPerPage bounds the number of items of a page, a larger per_page is clamped to it
+	MaxPerPage = 100
+)
+
 const (
 	OrderAsc  string = "ASC"
 	OrderDesc string = "DESC"
@@ -67,12 +74,12 @@ type Query struct {
 	// Search is a free-text search query.
 	// Implementation depends on the specific endpoint.
 	// Example: ?search=admin
-	Search *string `form:"search" json:"search"`
+	Search *string `form:"search" json:"search" description:"Free-text search"`
 
 	// Includes specifies related models to be loaded (eager loading).
 	// Format: "relation" or "relation1,relation2"
 	// Example: ?include=permissions&include=users
-	Includes []string `form:"include" json:"include" default:"[]"`
+	Includes []string `form:"include" json:"include" default:"[]" description:"Relations to load, e.g. roles"`
 
 	// Filters specifies conditions to filter the results.
 	// Format: "field operator value"
@@ -81,7 +88,7 @@ type Query struct {
 	// - ?filter=name==admin
 	// - ?filter=created_at>2023-01-01
 	// - ?filter=statusINactive,pending
-	Filters []string `form:"filter" json:"filter" default:"[]"`
+	Filters []string `form:"filter" json:"filter" default:"[]" description:"Conditions as column, operator and value, e.g. name==admin"`
 
 	// Sorts specifies the order of results.
 	// Prefix field with - for descending order.
@@ -89,7 +96,7 @@ type Query struct {
 	// - ?sort=name (ascending)
 	// - ?sort=-created_at (descending)
 	// - ?sort=name&sort=-created_at (multiple sorts)
-	Sorts []string `form:"sort" json:"sort" default:"[]"`
+	Sorts []string `form:"sort" json:"sort" default:"[]" description:"Columns to sort by, prefix with - for descending, e.g. -created_at"`
 }
 
 func NewQuery() *Query {
@@ -122,20 +129,25 @@ func (q *Query) GetFilterById() *Filter {
 	return q.GetFilter("id", OperatorEqual)
 }
 
+// GetPage returns the page number, at least 1
 func (q *Query) GetPage() int {
-	return q.Page
+	return max(q.Page, 1)
 }
 
+// GetPerPage returns the number of items per page, DefaultPerPage when not positive and at most MaxPerPage
 func (q *Query) GetPerPage() int {
-	return q.PerPage
+	if q.PerPage <= 0 {
+		return DefaultPerPage
+	}
+	return min(q.PerPage, MaxPerPage)
 }
 
 func (q *Query) GetLimit() int {
-	return q.PerPage
+	return q.GetPerPage()
 }
 
 func (q *Query) GetOffset() int {
-	return (q.Page - 1) * q.PerPage
+	return (q.GetPage() - 1) * q.GetPerPage()
 }
 
 func (q *Query) GetSearch() *string {
@@ -3,13 +3,18 @@ package reflectutil
 import (
 	"encoding"
 	"fmt"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
+var urlType = reflect.TypeOf(url.URL{})
+
 // SetValueFromString sets a reflect.Value from a string representation.
-// It supports basic types, durations, and anything implementing encoding.TextUnmarshaler.
+// It supports basic types, durations, URLs, comma separated slices of any supported type,
+// and anything implementing encoding.TextUnmarshaler.
 func SetValueFromString(fieldVal reflect.Value, str string) error {
 	// Handle pointer types
 	if fieldVal.Kind() == reflect.Pointer {
@@ -19,7 +24,35 @@ func SetValueFromString(fieldVal reflect.Value, str string) error {
 		return SetValueFromString(fieldVal.Elem(), str)
 	}
 
+	// Special case: url.URL
+	if fieldVal.Type() == urlType {
+		u, err := url.Parse(str)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid URL %q: scheme and host are required", str)
+		}
+		fieldVal.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
 	switch fieldVal.Kind() {
+	case reflect.Slice:
+		// Comma separated values, empty items are skipped
+		slice := reflect.MakeSlice(fieldVal.Type(), 0, 0)
+		for _, item := range strings.Split(str, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			elem := reflect.New(fieldVal.Type().Elem()).Elem()
+			if err := SetValueFromString(elem, item); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		fieldVal.Set(slice)
 	case reflect.String:
 		fieldVal.SetString(str)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -77,7 +110,23 @@ func GetValueAsString(fieldVal reflect.Value) (string, error) {
 		return GetValueAsString(fieldVal.Elem())
 	}
 
+	// Special case: url.URL
+	if fieldVal.Type() == urlType {
+		u := fieldVal.Interface().(url.URL)
+		return u.String(), nil
+	}
+
 	switch fieldVal.Kind() {
+	case reflect.Slice:
+		items := make([]string, 0, fieldVal.Len())
+		for i := 0; i < fieldVal.Len(); i++ {
+			item, err := GetValueAsString(fieldVal.Index(i))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
 	case reflect.String:
 		return fieldVal.String(), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64: